@@ -0,0 +1,205 @@
+package campaign
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"net/url"
+	"os"
+	"rocketship/mailer"
+	"rocketship/user"
+	"strings"
+	"time"
+)
+
+const invitationValidity = 7 * 24 * time.Hour
+
+func (s *service) FindCollaborators(campaignID CampaignDetailInput, currentUser user.User) ([]Collaborator, error) {
+	campaign, err := s.repository.FindCampaignByID(campaignID.ID)
+	if err != nil {
+		return []Collaborator{}, err
+	}
+
+	//Any member of the team may see who else is on it
+	if campaign.UserID != currentUser.ID {
+		collaborator, err := s.repository.FindCollaboratorByUserID(campaign.ID, currentUser.ID)
+		if err != nil {
+			return []Collaborator{}, err
+		}
+
+		if campaign.ID == 0 || collaborator.ID == 0 || collaborator.Status != "accepted" {
+			return []Collaborator{}, errors.New("Could not find collaborators due to lack of credentials")
+		}
+	}
+
+	collaboratorList, err := s.repository.FindCollaboratorByCampaignID(campaign.ID)
+	if err != nil {
+		return collaboratorList, err
+	}
+
+	return collaboratorList, nil
+}
+
+func (s *service) InviteCollaborator(campaignID CampaignDetailInput, input InviteCollaboratorInput) (Collaborator, error) {
+	campaign, err := s.repository.FindCampaignByID(campaignID.ID)
+	if err != nil {
+		return Collaborator{}, err
+	}
+
+	if campaign.ID == 0 || campaign.UserID != input.User.ID {
+		return Collaborator{}, errors.New("Could not invite collaborators due to lack of credentials")
+	}
+
+	email := strings.ToLower(strings.TrimSpace(input.Email))
+	if email == strings.ToLower(input.User.Email) {
+		return Collaborator{}, errors.New("Campaign owners could not invite themselves")
+	}
+
+	//Inviting the same address again renews the invitation instead of adding a second one
+	collaborator, err := s.repository.FindCollaboratorByEmail(campaign.ID, email)
+	if err != nil {
+		return collaborator, err
+	}
+
+	if collaborator.Status == "accepted" {
+		return collaborator, errors.New("This user is already a collaborator")
+	}
+
+	token, err := generateInvitationToken()
+	if err != nil {
+		return collaborator, err
+	}
+
+	collaborator.CampaignID = campaign.ID
+	collaborator.Email = email
+	collaborator.Role = input.Role
+	collaborator.Status = "invited"
+	collaborator.TokenHash = hashInvitationToken(token)
+	collaborator.InvitedBy = input.User.ID
+	collaborator.ExpiresAt = time.Now().Add(invitationValidity)
+
+	savedCollaborator, err := s.repository.SaveCollaborator(collaborator)
+	if err != nil {
+		return savedCollaborator, err
+	}
+
+	err = s.mailerService.Send(invitationMail(savedCollaborator, campaign, input.User, token))
+	if err != nil {
+		log.Println("Failed to send collaborator invitation:", err)
+	}
+
+	return savedCollaborator, nil
+}
+
+func (s *service) AcceptInvitation(input AcceptInvitationInput) (Collaborator, error) {
+	collaborator, err := s.repository.FindCollaboratorByTokenHash(hashInvitationToken(strings.TrimSpace(input.Token)))
+	if err != nil {
+		return collaborator, err
+	}
+
+	if collaborator.ID == 0 || collaborator.Status != "invited" {
+		return Collaborator{}, errors.New("No invitation found with this token")
+	}
+
+	if time.Now().After(collaborator.ExpiresAt) {
+		return Collaborator{}, errors.New("This invitation has expired")
+	}
+
+	//A forwarded invitation could not be used by somebody else
+	if strings.ToLower(input.User.Email) != collaborator.Email {
+		return Collaborator{}, errors.New("This invitation was sent to a different e-mail address")
+	}
+
+	now := time.Now()
+	collaborator.UserID = input.User.ID
+	collaborator.Status = "accepted"
+	collaborator.TokenHash = ""
+	collaborator.AcceptedAt = &now
+
+	updatedCollaborator, err := s.repository.SaveCollaborator(collaborator)
+	if err != nil {
+		return updatedCollaborator, err
+	}
+
+	return updatedCollaborator, nil
+}
+
+func (s *service) RemoveCollaborator(collaboratorID CollaboratorDetailInput, currentUser user.User) (Collaborator, error) {
+	collaborator, err := s.repository.FindCollaboratorByID(collaboratorID.ID)
+	if err != nil {
+		return collaborator, err
+	}
+
+	if collaborator.ID == 0 {
+		return collaborator, errors.New("No collaborator found with this ID")
+	}
+
+	campaign, err := s.repository.FindCampaignByID(collaborator.CampaignID)
+	if err != nil {
+		return collaborator, err
+	}
+
+	//Owners remove anybody, collaborators may only leave on their own
+	if campaign.UserID != currentUser.ID && (collaborator.UserID == 0 || collaborator.UserID != currentUser.ID) {
+		return collaborator, errors.New("Could not remove this collaborator due to lack of credentials")
+	}
+
+	err = s.repository.DeleteCollaborator(collaborator)
+	if err != nil {
+		return collaborator, err
+	}
+
+	return collaborator, nil
+}
+
+func generateInvitationToken() (string, error) {
+	token := make([]byte, 32)
+
+	_, err := rand.Read(token)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(token), nil
+}
+
+// Only a hash is stored, so a leaked database does not leak usable invitations
+func hashInvitationToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(hash[:])
+}
+
+var roleNames = map[string]string{
+	RoleEditor:        "an editor",
+	RoleFinanceViewer: "a finance viewer",
+}
+
+func invitationMail(collaborator Collaborator, campaign Campaign, inviter user.User, token string) mailer.Mail {
+	acceptance := fmt.Sprintf("<p>Your invitation code is <b>%s</b>.</p>", token)
+	if os.Getenv("APP_URL") != "" {
+		link := strings.TrimRight(os.Getenv("APP_URL"), "/") + "/invitations?token=" + url.QueryEscape(token)
+		acceptance = fmt.Sprintf(`<p><a href="%s">Accept the invitation</a></p>`, html.EscapeString(link))
+	}
+
+	body := fmt.Sprintf(
+		"<p>Hi,</p><p>%s invited you to help run <b>%s</b> as %s.</p>%s<p>The invitation expires on %s.</p>",
+		html.EscapeString(inviter.Name),
+		html.EscapeString(campaign.Name),
+		roleNames[collaborator.Role],
+		acceptance,
+		collaborator.ExpiresAt.Format("2 January 2006"),
+	)
+
+	mail := mailer.Mail{
+		To:       collaborator.Email,
+		Subject:  "You have been invited to " + campaign.Name,
+		HTMLBody: body,
+	}
+
+	return mail
+}
@@ -0,0 +1,29 @@
+package campaign
+
+import (
+	"rocketship/markdown"
+	"rocketship/storage"
+	"strings"
+)
+
+// RenderDescription turns a Markdown description into sanitised HTML. Images have to be campaign images
+// from our own storage, they are resolved on every render because signed storage URLs expire.
+func RenderDescription(description string, storageService storage.Service) string {
+	return markdown.Render(description, func(source string) (string, bool) {
+		return descriptionImageURL(source, storageService)
+	})
+}
+
+func descriptionImageURL(source string, storageService storage.Service) (string, bool) {
+	key, ok := storage.KeyFromURL(storageService, source)
+	if !ok || !strings.HasPrefix(key, "campaign-images/") {
+		return "", false
+	}
+
+	url := storage.URL(storageService, key)
+	if url == "" {
+		return "", false
+	}
+
+	return url, true
+}
@@ -12,13 +12,23 @@ type Campaign struct {
 	Description      string
 	ShortDescription string
 	Perks            string
+	Category         string
 	FunderAmount     int
+	Currency         string
 	GoalAmount       int
 	CurrentAmount    int
+	FeeAmount        int
+	MinDonation      int
+	MaxDonation      int
 	Slug             string
+	Status           string
+	EndsAt           *time.Time
 	CreatedAt        time.Time
 	UpdatedAt        time.Time
 	CampaignImages   []CampaignImage
+	CampaignMedia    []CampaignMedia
+	StretchGoals     []StretchGoal
+	MilestoneEvents  []MilestoneEvent
 	User             user.User
 }
 
@@ -26,7 +36,83 @@ type CampaignImage struct {
 	ID         int
 	CampaignID int
 	IsPrimary  int
+	Position   int
 	FileName   string
+	// VariantStatus tracks the resized copies of the file, see imaging.StatusReady
+	VariantStatus string
+	UpdatedAt     time.Time
+	CreatedAt     time.Time
+}
+
+// CampaignMedia is either an uploaded video file or an embedded YouTube or Vimeo video
+type CampaignMedia struct {
+	ID           int
+	CampaignID   int
+	Kind         string
+	Provider     string
+	SourceURL    string
+	EmbedURL     string
+	FileName     string
+	ContentType  string
+	Title        string
+	AuthorName   string
+	ThumbnailURL string
+	Width        int
+	Height       int
+	IsPitch      bool
+	Position     int
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// CampaignUpdate is news posted to a campaign's backers, its Body is Markdown like the description
+type CampaignUpdate struct {
+	ID         int
+	CampaignID int
+	UserID     int
+	Title      string
+	Body       string
+	CreatedAt  time.Time
 	UpdatedAt  time.Time
+	User       user.User
+}
+
+// Collaborator is a pending invitation until a user with the invited e-mail address accepts it
+type Collaborator struct {
+	ID         int
+	CampaignID int
+	UserID     int
+	Email      string
+	Role       string
+	Status     string
+	TokenHash  string
+	InvitedBy  int
+	ExpiresAt  time.Time
+	AcceptedAt *time.Time
 	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// StretchGoal is an extra target above the campaign goal, in the campaign currency
+type StretchGoal struct {
+	ID          int
+	CampaignID  int
+	Name        string
+	Description string
+	Amount      int
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// MilestoneEvent records that a campaign crossed a share of its goal or a stretch goal, at most once per milestone
+type MilestoneEvent struct {
+	ID            int
+	CampaignID    int
+	Kind          string
+	Percent       int
+	StretchGoalID int
+	Name          string
+	Amount        int
+	ReachedAmount int
+	CreatedAt     time.Time
 }
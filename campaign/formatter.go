@@ -1,87 +1,151 @@
 package campaign
 
-import "strings"
+import (
+	"rocketship/imaging"
+	"rocketship/money"
+	"rocketship/storage"
+	"strings"
+	"time"
+)
 
 type CampaignFormatter struct {
-	ID               int    `json:"id"`
-	UserID           int    `json:"user_id"`
-	Name             string `json:"name"`
-	ShortDescription string `json:"short_description"`
-	ImageURL         string `json:"image_url"`
-	GoalAmount       int    `json:"goal_amount"`
-	CurrentAmount    int    `json:"current_amount"`
-	Slug             string `json:"slug"`
+	ID               int                       `json:"id"`
+	UserID           int                       `json:"user_id"`
+	Name             string                    `json:"name"`
+	ShortDescription string                    `json:"short_description"`
+	ImageURL         string                    `json:"image_url"`
+	Currency         string                    `json:"currency"`
+	GoalAmount       int                       `json:"goal_amount"`
+	CurrentAmount    int                       `json:"current_amount"`
+	Slug             string                    `json:"slug"`
+	Category         string                    `json:"category"`
+	Status           string                    `json:"status"`
+	EndsAt           *time.Time                `json:"ends_at"`
+	IsFundable       bool                      `json:"is_fundable"`
+	Variants         map[string]string         `json:"variants"`
+	Display          *CampaignDisplayFormatter `json:"display,omitempty"`
 }
 
 type CampaignDetailFormatter struct {
-	ID               int                      `json:"id"`
-	Name             string                   `json:"name"`
-	ShortDescription string                   `json:"short_description"`
-	Description      string                   `json:"description"`
-	ImageUrl         string                   `json:"image_url"`
-	GoalAmount       int                      `json:"goal_amount"`
-	CurrentAmount    int                      `json:"current_amount"`
-	UserID           int                      `json:"user_id"`
-	Slug             string                   `json:"slug"`
-	Perks            []string                 `json:"perks"`
-	User             CampaignUserFormatter    `json:"user"`
-	CampaignImages   []CampaignImageFormatter `json:"campaign_images"`
+	ID               int                       `json:"id"`
+	Name             string                    `json:"name"`
+	ShortDescription string                    `json:"short_description"`
+	Description      string                    `json:"description"`
+	DescriptionHTML  string                    `json:"description_html"`
+	ImageUrl         string                    `json:"image_url"`
+	Currency         string                    `json:"currency"`
+	GoalAmount       int                       `json:"goal_amount"`
+	CurrentAmount    int                       `json:"current_amount"`
+	FeeAmount        int                       `json:"fee_amount"`
+	NetAmount        int                       `json:"net_amount"`
+	MinDonation      int                       `json:"min_donation"`
+	MaxDonation      int                       `json:"max_donation"`
+	UserID           int                       `json:"user_id"`
+	Slug             string                    `json:"slug"`
+	Category         string                    `json:"category"`
+	Status           string                    `json:"status"`
+	EndsAt           *time.Time                `json:"ends_at"`
+	IsFundable       bool                      `json:"is_fundable"`
+	Perks            []string                  `json:"perks"`
+	User             CampaignUserFormatter     `json:"user"`
+	CampaignImages   []CampaignImageFormatter  `json:"campaign_images"`
+	PitchVideo       *CampaignMediaFormatter   `json:"pitch_video"`
+	Media            []CampaignMediaFormatter  `json:"media"`
+	MatchedBy        []string                  `json:"matched_by"`
+	FollowerCount    int                       `json:"follower_count"`
+	Milestones       []MilestoneFormatter      `json:"milestones"`
+	StretchGoals     []StretchGoalFormatter    `json:"stretch_goals"`
+	Display          *CampaignDisplayFormatter `json:"display,omitempty"`
+}
+
+type DescriptionPreviewFormatter struct {
+	Description     string `json:"description"`
+	DescriptionHTML string `json:"description_html"`
+}
+
+type CampaignDisplayFormatter struct {
+	Currency      string `json:"currency"`
+	GoalAmount    int    `json:"goal_amount"`
+	CurrentAmount int    `json:"current_amount"`
 }
 
 type CampaignUserFormatter struct {
-	Name     string `json:"name"`
-	ImageURL string `json:"image_url"`
+	ID            int    `json:"id"`
+	Name          string `json:"name"`
+	ImageURL      string `json:"image_url"`
+	FollowerCount int    `json:"follower_count"`
 }
 
 type CampaignImageFormatter struct {
-	ImageUrl  string `json:"image_url"`
-	IsPrimary bool   `json:"is_primary"`
+	ID        int               `json:"id"`
+	ImageUrl  string            `json:"image_url"`
+	IsPrimary bool              `json:"is_primary"`
+	Position  int               `json:"position"`
+	Variants  map[string]string `json:"variants"`
 }
 
-func FormatCampaign(campaign Campaign) CampaignFormatter {
+func FormatCampaign(campaign Campaign, storageService storage.Service) CampaignFormatter {
 	formatter := CampaignFormatter{
 		ID:               campaign.ID,
 		UserID:           campaign.UserID,
 		Name:             campaign.Name,
 		ShortDescription: campaign.ShortDescription,
+		Currency:         money.Normalize(campaign.Currency),
 		GoalAmount:       campaign.GoalAmount,
 		CurrentAmount:    campaign.CurrentAmount,
 		Slug:             campaign.Slug,
+		Category:         campaign.Category,
+		Status:           campaignStatus(campaign),
+		EndsAt:           campaign.EndsAt,
+		IsFundable:       IsFundable(campaign, time.Now()),
+		Variants:         map[string]string{},
 	}
 
 	if len(campaign.CampaignImages) > 0 {
-		formatter.ImageURL = campaign.CampaignImages[0].FileName
+		formatter.ImageURL = storage.URL(storageService, campaign.CampaignImages[0].FileName)
+		formatter.Variants = imaging.VariantURLs(campaign.CampaignImages[0].FileName, campaign.CampaignImages[0].VariantStatus, storageService)
 	}
 
 	return formatter
 }
 
-func FormatCampaigns(campaigns []Campaign) []CampaignFormatter {
+func FormatCampaigns(campaigns []Campaign, storageService storage.Service) []CampaignFormatter {
 	campaignFormatterList := []CampaignFormatter{}
 
 	for _, campaign := range campaigns {
-		formatter := FormatCampaign(campaign)
+		formatter := FormatCampaign(campaign, storageService)
 		campaignFormatterList = append(campaignFormatterList, formatter)
 	}
 
 	return campaignFormatterList
 }
 
-func FormatCampaignDetail(campaign Campaign) CampaignDetailFormatter {
+func FormatCampaignDetail(campaign Campaign, storageService storage.Service) CampaignDetailFormatter {
 	formatter := CampaignDetailFormatter{
 		ID:               campaign.ID,
 		Name:             campaign.Name,
 		ShortDescription: campaign.ShortDescription,
 		Description:      campaign.Description,
+		DescriptionHTML:  RenderDescription(campaign.Description, storageService),
+		Currency:         money.Normalize(campaign.Currency),
 		GoalAmount:       campaign.GoalAmount,
 		CurrentAmount:    campaign.CurrentAmount,
+		FeeAmount:        campaign.FeeAmount,
+		NetAmount:        campaign.CurrentAmount - campaign.FeeAmount,
+		MinDonation:      campaign.MinDonation,
+		MaxDonation:      campaign.MaxDonation,
 		UserID:           campaign.UserID,
 		Slug:             campaign.Slug,
+		Category:         campaign.Category,
+		Status:           campaignStatus(campaign),
+		EndsAt:           campaign.EndsAt,
+		IsFundable:       IsFundable(campaign, time.Now()),
+		MatchedBy:        []string{},
 	}
 
 	//Image
 	if len(campaign.CampaignImages) > 0 {
-		formatter.ImageUrl = campaign.CampaignImages[0].FileName
+		formatter.ImageUrl = storage.URL(storageService, campaign.CampaignImages[0].FileName)
 	}
 
 	//Perks
@@ -93,26 +157,289 @@ func FormatCampaignDetail(campaign Campaign) CampaignDetailFormatter {
 
 	//User
 	CampaignUserFormatter := CampaignUserFormatter{
+		ID:       campaign.User.ID,
 		Name:     campaign.User.Name,
-		ImageURL: campaign.User.AvatarFileName,
+		ImageURL: storage.URL(storageService, campaign.User.AvatarFileName),
 	}
 	formatter.User = CampaignUserFormatter
 
 	//CampaignImages
-	campaignImages := []CampaignImageFormatter{}
-	for _, image := range campaign.CampaignImages {
-		isPrimary := false
-		if image.IsPrimary == 1 {
-			isPrimary = true
-		}
-		formatter := CampaignImageFormatter{
-			ImageUrl:  image.FileName,
-			IsPrimary: isPrimary,
+	formatter.CampaignImages = FormatCampaignImageList(campaign.CampaignImages, storageService)
+
+	//Media
+	formatter.Media = FormatCampaignMediaList(campaign.CampaignMedia, storageService)
+	for _, campaignMedia := range campaign.CampaignMedia {
+		if campaignMedia.IsPitch {
+			pitchVideo := FormatCampaignMedia(campaignMedia, storageService)
+			formatter.PitchVideo = &pitchVideo
 		}
+	}
+
+	//Milestones
+	formatter.Milestones = FormatMilestoneList(campaign.MilestoneEvents)
+	formatter.StretchGoals = FormatStretchGoalList(campaign.StretchGoals, campaign.MilestoneEvents)
+
+	return formatter
+}
+
+type CampaignMediaFormatter struct {
+	ID           int    `json:"id"`
+	Kind         string `json:"kind"`
+	Provider     string `json:"provider"`
+	URL          string `json:"url"`
+	EmbedURL     string `json:"embed_url"`
+	ContentType  string `json:"content_type"`
+	Title        string `json:"title"`
+	AuthorName   string `json:"author_name"`
+	ThumbnailURL string `json:"thumbnail_url"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	IsPitch      bool   `json:"is_pitch"`
+	Position     int    `json:"position"`
+}
+
+func FormatCampaignMedia(campaignMedia CampaignMedia, storageService storage.Service) CampaignMediaFormatter {
+	formatter := CampaignMediaFormatter{
+		ID:           campaignMedia.ID,
+		Kind:         campaignMedia.Kind,
+		Provider:     campaignMedia.Provider,
+		URL:          campaignMedia.SourceURL,
+		EmbedURL:     campaignMedia.EmbedURL,
+		ContentType:  campaignMedia.ContentType,
+		Title:        campaignMedia.Title,
+		AuthorName:   campaignMedia.AuthorName,
+		ThumbnailURL: campaignMedia.ThumbnailURL,
+		Width:        campaignMedia.Width,
+		Height:       campaignMedia.Height,
+		IsPitch:      campaignMedia.IsPitch,
+		Position:     campaignMedia.Position,
+	}
+
+	//Uploaded videos are played straight from storage
+	if campaignMedia.Kind == "upload" {
+		formatter.URL = storage.URL(storageService, campaignMedia.FileName)
+	}
+
+	return formatter
+}
+
+func FormatCampaignMediaList(mediaList []CampaignMedia, storageService storage.Service) []CampaignMediaFormatter {
+	formatterList := []CampaignMediaFormatter{}
+
+	for _, campaignMedia := range mediaList {
+		formatter := FormatCampaignMedia(campaignMedia, storageService)
+		formatterList = append(formatterList, formatter)
+	}
+
+	return formatterList
+}
+
+func FormatCampaignImage(image CampaignImage, storageService storage.Service) CampaignImageFormatter {
+	isPrimary := false
+	if image.IsPrimary == 1 {
+		isPrimary = true
+	}
+
+	formatter := CampaignImageFormatter{
+		ID:        image.ID,
+		ImageUrl:  storage.URL(storageService, image.FileName),
+		IsPrimary: isPrimary,
+		Position:  image.Position,
+		Variants:  imaging.VariantURLs(image.FileName, image.VariantStatus, storageService),
+	}
+
+	return formatter
+}
+
+func FormatCampaignImageList(imageList []CampaignImage, storageService storage.Service) []CampaignImageFormatter {
+	formatterList := []CampaignImageFormatter{}
+
+	for _, image := range imageList {
+		formatter := FormatCampaignImage(image, storageService)
+		formatterList = append(formatterList, formatter)
+	}
+
+	return formatterList
+}
+
+// Display amounts are only an estimate for the viewer, the campaign keeps counting in its own currency
+func FormatCampaignDisplay(campaign Campaign, currency string, rates money.RateSource) (CampaignDisplayFormatter, error) {
+	goalAmount, err := money.Convert(money.New(campaign.GoalAmount, campaign.Currency), currency, rates)
+	if err != nil {
+		return CampaignDisplayFormatter{}, err
+	}
+
+	currentAmount, err := money.Convert(money.New(campaign.CurrentAmount, campaign.Currency), currency, rates)
+	if err != nil {
+		return CampaignDisplayFormatter{}, err
+	}
+
+	formatter := CampaignDisplayFormatter{
+		Currency:      goalAmount.Currency,
+		GoalAmount:    goalAmount.Amount,
+		CurrentAmount: currentAmount.Amount,
+	}
+
+	return formatter, nil
+}
+
+func FormatDescriptionPreview(description string, storageService storage.Service) DescriptionPreviewFormatter {
+	formatter := DescriptionPreviewFormatter{
+		Description:     description,
+		DescriptionHTML: RenderDescription(description, storageService),
+	}
+
+	return formatter
+}
+
+type CampaignUpdateFormatter struct {
+	ID         int                   `json:"id"`
+	CampaignID int                   `json:"campaign_id"`
+	Title      string                `json:"title"`
+	Body       string                `json:"body"`
+	BodyHTML   string                `json:"body_html"`
+	User       CampaignUserFormatter `json:"user"`
+	CreatedAt  time.Time             `json:"created_at"`
+}
 
-		campaignImages = append(campaignImages, formatter)
+func FormatCampaignUpdate(campaignUpdate CampaignUpdate, storageService storage.Service) CampaignUpdateFormatter {
+	formatter := CampaignUpdateFormatter{
+		ID:         campaignUpdate.ID,
+		CampaignID: campaignUpdate.CampaignID,
+		Title:      campaignUpdate.Title,
+		Body:       campaignUpdate.Body,
+		BodyHTML:   RenderDescription(campaignUpdate.Body, storageService),
+		CreatedAt:  campaignUpdate.CreatedAt,
+	}
+
+	formatter.User = CampaignUserFormatter{
+		ID:       campaignUpdate.User.ID,
+		Name:     campaignUpdate.User.Name,
+		ImageURL: storage.URL(storageService, campaignUpdate.User.AvatarFileName),
 	}
-	formatter.CampaignImages = campaignImages
 
 	return formatter
 }
+
+func FormatCampaignUpdateList(campaignUpdateList []CampaignUpdate, storageService storage.Service) []CampaignUpdateFormatter {
+	formatterList := []CampaignUpdateFormatter{}
+
+	for _, campaignUpdate := range campaignUpdateList {
+		formatter := FormatCampaignUpdate(campaignUpdate, storageService)
+		formatterList = append(formatterList, formatter)
+	}
+
+	return formatterList
+}
+
+type CollaboratorFormatter struct {
+	ID         int        `json:"id"`
+	CampaignID int        `json:"campaign_id"`
+	UserID     int        `json:"user_id"`
+	Email      string     `json:"email"`
+	Role       string     `json:"role"`
+	Status     string     `json:"status"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at"`
+}
+
+func FormatCollaborator(collaborator Collaborator) CollaboratorFormatter {
+	formatter := CollaboratorFormatter{
+		ID:         collaborator.ID,
+		CampaignID: collaborator.CampaignID,
+		UserID:     collaborator.UserID,
+		Email:      collaborator.Email,
+		Role:       collaborator.Role,
+		Status:     collaborator.Status,
+		ExpiresAt:  collaborator.ExpiresAt,
+		AcceptedAt: collaborator.AcceptedAt,
+	}
+
+	return formatter
+}
+
+func FormatCollaboratorList(collaboratorList []Collaborator) []CollaboratorFormatter {
+	formatterList := []CollaboratorFormatter{}
+
+	for _, collaborator := range collaboratorList {
+		formatter := FormatCollaborator(collaborator)
+		formatterList = append(formatterList, formatter)
+	}
+
+	return formatterList
+}
+
+type MilestoneFormatter struct {
+	Kind          string    `json:"kind"`
+	Percent       int       `json:"percent"`
+	StretchGoalID int       `json:"stretch_goal_id"`
+	Name          string    `json:"name"`
+	Amount        int       `json:"amount"`
+	ReachedAmount int       `json:"reached_amount"`
+	ReachedAt     time.Time `json:"reached_at"`
+}
+
+type StretchGoalFormatter struct {
+	ID          int        `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Amount      int        `json:"amount"`
+	IsAchieved  bool       `json:"is_achieved"`
+	AchievedAt  *time.Time `json:"achieved_at"`
+}
+
+func FormatMilestone(milestoneEvent MilestoneEvent) MilestoneFormatter {
+	formatter := MilestoneFormatter{
+		Kind:          milestoneEvent.Kind,
+		Percent:       milestoneEvent.Percent,
+		StretchGoalID: milestoneEvent.StretchGoalID,
+		Name:          milestoneEvent.Name,
+		Amount:        milestoneEvent.Amount,
+		ReachedAmount: milestoneEvent.ReachedAmount,
+		ReachedAt:     milestoneEvent.CreatedAt,
+	}
+
+	return formatter
+}
+
+func FormatMilestoneList(milestoneEventList []MilestoneEvent) []MilestoneFormatter {
+	formatterList := []MilestoneFormatter{}
+
+	for _, milestoneEvent := range milestoneEventList {
+		formatter := FormatMilestone(milestoneEvent)
+		formatterList = append(formatterList, formatter)
+	}
+
+	return formatterList
+}
+
+// A stretch goal counts as achieved once its milestone was recorded, later refunds do not take that back
+func FormatStretchGoal(stretchGoal StretchGoal, milestoneEventList []MilestoneEvent) StretchGoalFormatter {
+	formatter := StretchGoalFormatter{
+		ID:          stretchGoal.ID,
+		Name:        stretchGoal.Name,
+		Description: stretchGoal.Description,
+		Amount:      stretchGoal.Amount,
+	}
+
+	for _, milestoneEvent := range milestoneEventList {
+		if milestoneEvent.Kind == MilestoneStretchGoal && milestoneEvent.StretchGoalID == stretchGoal.ID {
+			achievedAt := milestoneEvent.CreatedAt
+			formatter.IsAchieved = true
+			formatter.AchievedAt = &achievedAt
+		}
+	}
+
+	return formatter
+}
+
+func FormatStretchGoalList(stretchGoalList []StretchGoal, milestoneEventList []MilestoneEvent) []StretchGoalFormatter {
+	formatterList := []StretchGoalFormatter{}
+
+	for _, stretchGoal := range stretchGoalList {
+		formatter := FormatStretchGoal(stretchGoal, milestoneEventList)
+		formatterList = append(formatterList, formatter)
+	}
+
+	return formatterList
+}
@@ -1,22 +1,95 @@
 package campaign
 
-import "rocketship/user"
+import (
+	"rocketship/user"
+	"time"
+)
 
 type CampaignDetailInput struct {
 	ID int `uri:"id" binding:"required"`
 }
 
 type CreateCampaignInput struct {
-	Name             string `json:"name" binding:"required"`
-	ShortDescription string `json:"short_description" binding:"required"`
-	Description      string `json:"description" binding:"required"`
-	GoalAmount       int    `json:"goal_amount" binding:"required"`
-	Perks            string `json:"perks" binding:"required"`
+	Name             string     `json:"name" binding:"required"`
+	ShortDescription string     `json:"short_description" binding:"required"`
+	Description      string     `json:"description" binding:"required,max=20000"`
+	Currency         string     `json:"currency"`
+	GoalAmount       int        `json:"goal_amount" binding:"required"`
+	MinDonation      int        `json:"min_donation" binding:"gte=0"`
+	MaxDonation      int        `json:"max_donation" binding:"gte=0"`
+	Perks            string     `json:"perks" binding:"required"`
+	Category         string     `json:"category"`
+	Status           string     `json:"status" binding:"omitempty,oneof=active closed"`
+	EndsAt           *time.Time `json:"ends_at"`
 	User             user.User
 }
 
+type CampaignImageDetailInput struct {
+	ID int `uri:"id" binding:"required"`
+}
+
+type ReorderCampaignImagesInput struct {
+	ImageIDs []int `json:"image_ids" binding:"required"`
+	User     user.User
+}
+
+type StretchGoalDetailInput struct {
+	ID int `uri:"id" binding:"required"`
+}
+
+type CreateStretchGoalInput struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description" binding:"max=1000"`
+	Amount      int    `json:"amount" binding:"required,gt=0"`
+	User        user.User
+}
+
+type PreviewDescriptionInput struct {
+	Description string `json:"description" binding:"max=20000"`
+}
+
 type CreateCampaignImageInput struct {
 	CampaignID int  `form:"campaign_id" binding:"required"`
 	IsPrimary  bool `form:"is_primary"`
 	User       user.User
 }
+
+type CampaignMediaDetailInput struct {
+	ID int `uri:"id" binding:"required"`
+}
+
+type CreateCampaignVideoInput struct {
+	CampaignID int    `form:"campaign_id" binding:"required"`
+	Title      string `form:"title"`
+	IsPitch    bool   `form:"is_pitch"`
+	User       user.User
+}
+
+type CreateCampaignEmbedInput struct {
+	CampaignID int    `json:"campaign_id" binding:"required"`
+	URL        string `json:"url" binding:"required"`
+	Title      string `json:"title"`
+	IsPitch    bool   `json:"is_pitch"`
+	User       user.User
+}
+
+type CreateCampaignUpdateInput struct {
+	Title string `json:"title" binding:"required,max=150"`
+	Body  string `json:"body" binding:"required,max=20000"`
+	User  user.User
+}
+
+type CollaboratorDetailInput struct {
+	ID int `uri:"id" binding:"required"`
+}
+
+type InviteCollaboratorInput struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required,oneof=editor finance_viewer"`
+	User  user.User
+}
+
+type AcceptInvitationInput struct {
+	Token string `json:"token" binding:"required"`
+	User  user.User
+}
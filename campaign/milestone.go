@@ -0,0 +1,146 @@
+package campaign
+
+import (
+	"errors"
+	"fmt"
+	"rocketship/user"
+)
+
+const (
+	MilestoneGoal        = "goal"
+	MilestoneStretchGoal = "stretch_goal"
+)
+
+// goalPercents are the shares of the goal that count as milestones
+var goalPercents = []int{25, 50, 75, 100}
+
+func (s *service) CreateStretchGoal(campaignID CampaignDetailInput, input CreateStretchGoalInput) (StretchGoal, error) {
+	campaign, err := s.repository.FindCampaignByID(campaignID.ID)
+	if err != nil {
+		return StretchGoal{}, err
+	}
+
+	err = s.checkAccess(campaign, input.User, PermissionEdit, "Could not add stretch goals due to lack of credentials")
+	if err != nil {
+		return StretchGoal{}, err
+	}
+
+	if input.Amount <= campaign.GoalAmount {
+		return StretchGoal{}, errors.New("Stretch goals must be above the campaign goal")
+	}
+
+	stretchGoal := StretchGoal{
+		CampaignID:  campaign.ID,
+		Name:        input.Name,
+		Description: input.Description,
+		Amount:      input.Amount,
+	}
+
+	newStretchGoal, err := s.repository.CreateStretchGoal(stretchGoal)
+	if err != nil {
+		return newStretchGoal, err
+	}
+
+	return newStretchGoal, nil
+}
+
+func (s *service) DeleteStretchGoal(stretchGoalID StretchGoalDetailInput, currentUser user.User) (StretchGoal, error) {
+	stretchGoal, err := s.repository.FindStretchGoalByID(stretchGoalID.ID)
+	if err != nil {
+		return stretchGoal, err
+	}
+
+	if stretchGoal.ID == 0 {
+		return stretchGoal, errors.New("No stretch goal found with this ID")
+	}
+
+	campaign, err := s.repository.FindCampaignByID(stretchGoal.CampaignID)
+	if err != nil {
+		return stretchGoal, err
+	}
+
+	err = s.checkAccess(campaign, currentUser, PermissionEdit, "Could not delete this stretch goal due to lack of credentials")
+	if err != nil {
+		return stretchGoal, err
+	}
+
+	//Events of a reached stretch goal are kept as history
+	err = s.repository.DeleteStretchGoal(stretchGoal)
+	if err != nil {
+		return stretchGoal, err
+	}
+
+	return stretchGoal, nil
+}
+
+// RecordMilestones returns only the milestones crossed since the last call, so callers can notify about each one once
+func (s *service) RecordMilestones(campaignID int) ([]MilestoneEvent, error) {
+	milestoneEventList, err := s.repository.SaveMilestoneEvents(campaignID, detectMilestones)
+	if err != nil {
+		return milestoneEventList, err
+	}
+
+	return milestoneEventList, nil
+}
+
+func detectMilestones(campaign Campaign) []MilestoneEvent {
+	reached := map[string]bool{}
+	for _, milestoneEvent := range campaign.MilestoneEvents {
+		reached[milestoneKey(milestoneEvent)] = true
+	}
+
+	candidates := []MilestoneEvent{}
+
+	if campaign.GoalAmount > 0 {
+		for _, percent := range goalPercents {
+			candidates = append(candidates, MilestoneEvent{
+				Kind:    MilestoneGoal,
+				Percent: percent,
+				Name:    fmt.Sprintf("%d%% of the goal", percent),
+				Amount:  (campaign.GoalAmount*percent + 99) / 100,
+			})
+		}
+	}
+
+	for _, stretchGoal := range campaign.StretchGoals {
+		candidates = append(candidates, MilestoneEvent{
+			Kind:          MilestoneStretchGoal,
+			StretchGoalID: stretchGoal.ID,
+			Name:          stretchGoal.Name,
+			Amount:        stretchGoal.Amount,
+		})
+	}
+
+	milestoneEventList := []MilestoneEvent{}
+	for _, candidate := range candidates {
+		if campaign.CurrentAmount < candidate.Amount || reached[milestoneKey(candidate)] {
+			continue
+		}
+
+		candidate.CampaignID = campaign.ID
+		candidate.ReachedAmount = campaign.CurrentAmount
+		milestoneEventList = append(milestoneEventList, candidate)
+	}
+
+	return milestoneEventList
+}
+
+func milestoneKey(milestoneEvent MilestoneEvent) string {
+	if milestoneEvent.Kind == MilestoneStretchGoal {
+		return fmt.Sprintf("%s:%d", milestoneEvent.Kind, milestoneEvent.StretchGoalID)
+	}
+
+	return fmt.Sprintf("%s:%d", milestoneEvent.Kind, milestoneEvent.Percent)
+}
+
+func MilestoneMessage(campaignName string, milestoneEvent MilestoneEvent) string {
+	if milestoneEvent.Kind == MilestoneStretchGoal {
+		return fmt.Sprintf("%s unlocked the stretch goal %s", campaignName, milestoneEvent.Name)
+	}
+
+	if milestoneEvent.Percent >= 100 {
+		return campaignName + " reached its goal"
+	}
+
+	return fmt.Sprintf("%s reached %d%% of its goal", campaignName, milestoneEvent.Percent)
+}
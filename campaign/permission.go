@@ -0,0 +1,44 @@
+package campaign
+
+const (
+	RoleEditor        = "editor"
+	RoleFinanceViewer = "finance_viewer"
+)
+
+const (
+	PermissionEdit        = "edit"
+	PermissionViewFinance = "view_finance"
+)
+
+var rolePermissions = map[string][]string{
+	RoleEditor:        {PermissionEdit},
+	RoleFinanceViewer: {PermissionViewFinance},
+}
+
+// CanAccess allows the owner everything and accepted collaborators what their role grants
+func CanAccess(repository Repository, campaign Campaign, userID int, permission string) (bool, error) {
+	if campaign.ID == 0 || userID == 0 {
+		return false, nil
+	}
+
+	if campaign.UserID == userID {
+		return true, nil
+	}
+
+	collaborator, err := repository.FindCollaboratorByUserID(campaign.ID, userID)
+	if err != nil {
+		return false, err
+	}
+
+	if collaborator.ID == 0 || collaborator.Status != "accepted" {
+		return false, nil
+	}
+
+	for _, rolePermission := range rolePermissions[collaborator.Role] {
+		if rolePermission == permission {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -1,15 +1,51 @@
 package campaign
 
-import "gorm.io/gorm"
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
 
 type Repository interface {
 	FindAllCampaign() ([]Campaign, error)
 	FindCampaignByUserID(userID int) ([]Campaign, error)
 	FindCampaignByID(campaignID int) (Campaign, error)
+	FindCampaignByIDs(campaignIDs []int) ([]Campaign, error)
+	FindCampaignByUserIDs(userIDs []int) ([]Campaign, error)
 	CreateCampaign(campaign Campaign) (Campaign, error)
 	UpdateCampaign(campaign Campaign) (Campaign, error)
+	UpdateFunderAmount(campaignID int, change int) error
+	UpdateCampaignAmounts(campaignID int, currentAmount int, feeAmount int) error
+	CountOpenTransactionByCampaignID(campaignID int) (int64, error)
 	UploadCampaignImage(campaignImage CampaignImage) (CampaignImage, error)
 	MarkAllAsNonPrimary(campaignID int) (bool, error)
+	FindCampaignImageByID(imageID int) (CampaignImage, error)
+	FindCampaignImageByCampaignID(campaignID int) ([]CampaignImage, error)
+	CountCampaignImageByFileName(fileName string) (int64, error)
+	DeleteCampaignImage(campaignImage CampaignImage) error
+	MarkAsPrimary(campaignImage CampaignImage) (CampaignImage, error)
+	UpdateCampaignImagePositions(campaignID int, imageIDs []int) error
+	FindCampaignMediaByID(mediaID int) (CampaignMedia, error)
+	CountCampaignMediaByCampaignID(campaignID int) (int64, error)
+	CountCampaignMediaByFileName(fileName string) (int64, error)
+	CreateCampaignMedia(campaignMedia CampaignMedia) (CampaignMedia, error)
+	DeleteCampaignMedia(campaignMedia CampaignMedia) error
+	FindCampaignUpdateByCampaignID(campaignID int) ([]CampaignUpdate, error)
+	FindCampaignUpdateByCampaignIDs(campaignIDs []int, limit int) ([]CampaignUpdate, error)
+	CreateCampaignUpdate(campaignUpdate CampaignUpdate) (CampaignUpdate, error)
+	FindCollaboratorByID(collaboratorID int) (Collaborator, error)
+	FindCollaboratorByCampaignID(campaignID int) ([]Collaborator, error)
+	FindCollaboratorByUserID(campaignID int, userID int) (Collaborator, error)
+	FindCollaboratorByEmail(campaignID int, email string) (Collaborator, error)
+	FindCollaboratorByTokenHash(tokenHash string) (Collaborator, error)
+	SaveCollaborator(collaborator Collaborator) (Collaborator, error)
+	DeleteCollaborator(collaborator Collaborator) error
+	FindStretchGoalByID(stretchGoalID int) (StretchGoal, error)
+	CreateStretchGoal(stretchGoal StretchGoal) (StretchGoal, error)
+	DeleteStretchGoal(stretchGoal StretchGoal) error
+	FindMilestoneEventByCampaignIDs(campaignIDs []int, limit int) ([]MilestoneEvent, error)
+	SaveMilestoneEvents(campaignID int, detect func(campaign Campaign) []MilestoneEvent) ([]MilestoneEvent, error)
+	FindCampaignImageByVariantStatus(status string) ([]CampaignImage, error)
+	UpdateCampaignImageVariantStatus(campaignImage CampaignImage, status string) error
 }
 
 type repository struct {
@@ -42,10 +78,40 @@ func (repo *repository) FindCampaignByUserID(userID int) ([]Campaign, error) {
 	return campaignList, nil
 }
 
+func (repo *repository) FindCampaignByIDs(campaignIDs []int) ([]Campaign, error) {
+	var campaignList []Campaign
+	err := repo.db.Where("id IN ?", campaignIDs).Preload("CampaignImages", "campaign_images.is_primary = 1").Find(&campaignList).Error
+
+	if err != nil {
+		return campaignList, err
+	}
+
+	return campaignList, nil
+}
+
+func (repo *repository) FindCampaignByUserIDs(userIDs []int) ([]Campaign, error) {
+	var campaignList []Campaign
+	err := repo.db.Where("user_id IN ?", userIDs).Order("created_at desc").Find(&campaignList).Error
+
+	if err != nil {
+		return campaignList, err
+	}
+
+	return campaignList, nil
+}
+
 func (repo *repository) FindCampaignByID(campaignID int) (Campaign, error) {
 	var campaign Campaign
 
-	err := repo.db.Preload("User").Preload("CampaignImages").Where("id = ?", campaignID).Find(&campaign).Error
+	err := repo.db.Preload("User").Preload("CampaignImages", func(db *gorm.DB) *gorm.DB {
+		return db.Order("campaign_images.position, campaign_images.id")
+	}).Preload("CampaignMedia", func(db *gorm.DB) *gorm.DB {
+		return db.Order("campaign_media.position, campaign_media.id")
+	}).Preload("StretchGoals", func(db *gorm.DB) *gorm.DB {
+		return db.Order("stretch_goals.amount, stretch_goals.id")
+	}).Preload("MilestoneEvents", func(db *gorm.DB) *gorm.DB {
+		return db.Order("milestone_events.created_at, milestone_events.id")
+	}).Where("id = ?", campaignID).Find(&campaign).Error
 
 	if err != nil {
 		return campaign, err
@@ -64,8 +130,10 @@ func (repo *repository) CreateCampaign(campaign Campaign) (Campaign, error) {
 	return campaign, nil
 }
 
+// UpdateCampaign saves what the owner edits. The amounts are kept by the ledger and the preloaded
+// associations have their own writes, so neither is saved from a copy that may be outdated
 func (repo *repository) UpdateCampaign(campaign Campaign) (Campaign, error) {
-	err := repo.db.Save(&campaign).Error
+	err := repo.db.Omit("current_amount", "fee_amount", "funder_amount", clause.Associations).Save(&campaign).Error
 
 	if err != nil {
 		return campaign, err
@@ -74,6 +142,28 @@ func (repo *repository) UpdateCampaign(campaign Campaign) (Campaign, error) {
 	return campaign, nil
 }
 
+// UpdateFunderAmount changes the counter in the database, so concurrent payments and refunds never overwrite each other
+func (repo *repository) UpdateFunderAmount(campaignID int, change int) error {
+	err := repo.db.Model(&Campaign{}).Where("id = ?", campaignID).Update("funder_amount", gorm.Expr("funder_amount + ?", change)).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repo *repository) UpdateCampaignAmounts(campaignID int, currentAmount int, feeAmount int) error {
+	err := repo.db.Model(&Campaign{}).Where("id = ?", campaignID).Updates(map[string]interface{}{
+		"current_amount": currentAmount,
+		"fee_amount":     feeAmount,
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (repo *repository) UploadCampaignImage(campaignImage CampaignImage) (CampaignImage, error) {
 	err := repo.db.Create(&campaignImage).Error
 	if err != nil {
@@ -91,3 +181,368 @@ func (repo *repository) MarkAllAsNonPrimary(campaignID int) (bool, error) {
 
 	return true, nil
 }
+
+func (repo *repository) FindCampaignImageByVariantStatus(status string) ([]CampaignImage, error) {
+	var campaignImageList []CampaignImage
+
+	err := repo.db.Where("variant_status = ?", status).Find(&campaignImageList).Error
+	if err != nil {
+		return campaignImageList, err
+	}
+
+	return campaignImageList, nil
+}
+
+func (repo *repository) UpdateCampaignImageVariantStatus(campaignImage CampaignImage, status string) error {
+	//Matching the file name as well skips rows whose file changed in the meantime
+	err := repo.db.Model(&CampaignImage{}).Where("id = ? AND file_name = ?", campaignImage.ID, campaignImage.FileName).Update("variant_status", status).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repo *repository) FindCampaignImageByID(imageID int) (CampaignImage, error) {
+	var campaignImage CampaignImage
+
+	err := repo.db.Where("id = ?", imageID).Find(&campaignImage).Error
+	if err != nil {
+		return campaignImage, err
+	}
+
+	return campaignImage, nil
+}
+
+func (repo *repository) FindCampaignImageByCampaignID(campaignID int) ([]CampaignImage, error) {
+	var campaignImageList []CampaignImage
+
+	err := repo.db.Where("campaign_id = ?", campaignID).Order("position, id").Find(&campaignImageList).Error
+	if err != nil {
+		return campaignImageList, err
+	}
+
+	return campaignImageList, nil
+}
+
+func (repo *repository) CountCampaignImageByFileName(fileName string) (int64, error) {
+	var count int64
+
+	err := repo.db.Model(&CampaignImage{}).Where("file_name = ?", fileName).Count(&count).Error
+	if err != nil {
+		return count, err
+	}
+
+	return count, nil
+}
+
+func (repo *repository) DeleteCampaignImage(campaignImage CampaignImage) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Delete(&CampaignImage{}, campaignImage.ID).Error
+		if err != nil {
+			return err
+		}
+
+		if campaignImage.IsPrimary != 1 {
+			return nil
+		}
+
+		//A campaign that still has images keeps a primary one, the first in the gallery takes over
+		var nextImage CampaignImage
+		err = tx.Where("campaign_id = ?", campaignImage.CampaignID).Order("position, id").Limit(1).Find(&nextImage).Error
+		if err != nil {
+			return err
+		}
+
+		if nextImage.ID == 0 {
+			return nil
+		}
+
+		return tx.Model(&nextImage).Update("is_primary", 1).Error
+	})
+}
+
+func (repo *repository) MarkAsPrimary(campaignImage CampaignImage) (CampaignImage, error) {
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&CampaignImage{}).Where("campaign_id = ?", campaignImage.CampaignID).Update("is_primary", 0).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&campaignImage).Update("is_primary", 1).Error
+	})
+	if err != nil {
+		return campaignImage, err
+	}
+
+	campaignImage.IsPrimary = 1
+
+	return campaignImage, nil
+}
+
+func (repo *repository) UpdateCampaignImagePositions(campaignID int, imageIDs []int) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		for i, imageID := range imageIDs {
+			err := tx.Model(&CampaignImage{}).Where("id = ? AND campaign_id = ?", imageID, campaignID).Update("position", i+1).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (repo *repository) FindCampaignMediaByID(mediaID int) (CampaignMedia, error) {
+	var campaignMedia CampaignMedia
+
+	err := repo.db.Where("id = ?", mediaID).Find(&campaignMedia).Error
+	if err != nil {
+		return campaignMedia, err
+	}
+
+	return campaignMedia, nil
+}
+
+// Transactions live in a package that depends on campaigns, so they are counted from their table directly
+func (repo *repository) CountOpenTransactionByCampaignID(campaignID int) (int64, error) {
+	var count int64
+
+	err := repo.db.Table("transactions").Where("campaign_id = ? AND status <> ?", campaignID, "cancelled").Count(&count).Error
+	if err != nil {
+		return count, err
+	}
+
+	return count, nil
+}
+
+func (repo *repository) CountCampaignMediaByCampaignID(campaignID int) (int64, error) {
+	var count int64
+
+	err := repo.db.Model(&CampaignMedia{}).Where("campaign_id = ?", campaignID).Count(&count).Error
+	if err != nil {
+		return count, err
+	}
+
+	return count, nil
+}
+
+func (repo *repository) CountCampaignMediaByFileName(fileName string) (int64, error) {
+	var count int64
+
+	err := repo.db.Model(&CampaignMedia{}).Where("file_name = ?", fileName).Count(&count).Error
+	if err != nil {
+		return count, err
+	}
+
+	return count, nil
+}
+
+func (repo *repository) CreateCampaignMedia(campaignMedia CampaignMedia) (CampaignMedia, error) {
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		//A campaign has at most one pitch video
+		if campaignMedia.IsPitch {
+			err := tx.Model(&CampaignMedia{}).Where("campaign_id = ?", campaignMedia.CampaignID).Update("is_pitch", false).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Create(&campaignMedia).Error
+	})
+	if err != nil {
+		return campaignMedia, err
+	}
+
+	return campaignMedia, nil
+}
+
+func (repo *repository) DeleteCampaignMedia(campaignMedia CampaignMedia) error {
+	err := repo.db.Delete(&CampaignMedia{}, campaignMedia.ID).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repo *repository) FindCampaignUpdateByCampaignID(campaignID int) ([]CampaignUpdate, error) {
+	var campaignUpdateList []CampaignUpdate
+
+	err := repo.db.Preload("User").Where("campaign_id = ?", campaignID).Order("created_at desc, id desc").Find(&campaignUpdateList).Error
+	if err != nil {
+		return campaignUpdateList, err
+	}
+
+	return campaignUpdateList, nil
+}
+
+func (repo *repository) FindCampaignUpdateByCampaignIDs(campaignIDs []int, limit int) ([]CampaignUpdate, error) {
+	var campaignUpdateList []CampaignUpdate
+
+	err := repo.db.Where("campaign_id IN ?", campaignIDs).Order("created_at desc, id desc").Limit(limit).Find(&campaignUpdateList).Error
+	if err != nil {
+		return campaignUpdateList, err
+	}
+
+	return campaignUpdateList, nil
+}
+
+func (repo *repository) CreateCampaignUpdate(campaignUpdate CampaignUpdate) (CampaignUpdate, error) {
+	err := repo.db.Create(&campaignUpdate).Error
+	if err != nil {
+		return campaignUpdate, err
+	}
+
+	return campaignUpdate, nil
+}
+
+func (repo *repository) FindCollaboratorByID(collaboratorID int) (Collaborator, error) {
+	var collaborator Collaborator
+
+	err := repo.db.Where("id = ?", collaboratorID).Find(&collaborator).Error
+	if err != nil {
+		return collaborator, err
+	}
+
+	return collaborator, nil
+}
+
+func (repo *repository) FindCollaboratorByCampaignID(campaignID int) ([]Collaborator, error) {
+	var collaboratorList []Collaborator
+
+	err := repo.db.Where("campaign_id = ?", campaignID).Order("id").Find(&collaboratorList).Error
+	if err != nil {
+		return collaboratorList, err
+	}
+
+	return collaboratorList, nil
+}
+
+func (repo *repository) FindCollaboratorByUserID(campaignID int, userID int) (Collaborator, error) {
+	var collaborator Collaborator
+
+	err := repo.db.Where("campaign_id = ? AND user_id = ?", campaignID, userID).Find(&collaborator).Error
+	if err != nil {
+		return collaborator, err
+	}
+
+	return collaborator, nil
+}
+
+func (repo *repository) FindCollaboratorByEmail(campaignID int, email string) (Collaborator, error) {
+	var collaborator Collaborator
+
+	err := repo.db.Where("campaign_id = ? AND email = ?", campaignID, email).Find(&collaborator).Error
+	if err != nil {
+		return collaborator, err
+	}
+
+	return collaborator, nil
+}
+
+func (repo *repository) FindCollaboratorByTokenHash(tokenHash string) (Collaborator, error) {
+	var collaborator Collaborator
+
+	err := repo.db.Where("token_hash = ?", tokenHash).Find(&collaborator).Error
+	if err != nil {
+		return collaborator, err
+	}
+
+	return collaborator, nil
+}
+
+func (repo *repository) SaveCollaborator(collaborator Collaborator) (Collaborator, error) {
+	err := repo.db.Save(&collaborator).Error
+	if err != nil {
+		return collaborator, err
+	}
+
+	return collaborator, nil
+}
+
+func (repo *repository) DeleteCollaborator(collaborator Collaborator) error {
+	err := repo.db.Delete(&Collaborator{}, collaborator.ID).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repo *repository) FindStretchGoalByID(stretchGoalID int) (StretchGoal, error) {
+	var stretchGoal StretchGoal
+
+	err := repo.db.Where("id = ?", stretchGoalID).Find(&stretchGoal).Error
+	if err != nil {
+		return stretchGoal, err
+	}
+
+	return stretchGoal, nil
+}
+
+func (repo *repository) CreateStretchGoal(stretchGoal StretchGoal) (StretchGoal, error) {
+	err := repo.db.Create(&stretchGoal).Error
+	if err != nil {
+		return stretchGoal, err
+	}
+
+	return stretchGoal, nil
+}
+
+func (repo *repository) DeleteStretchGoal(stretchGoal StretchGoal) error {
+	err := repo.db.Delete(&StretchGoal{}, stretchGoal.ID).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repo *repository) FindMilestoneEventByCampaignIDs(campaignIDs []int, limit int) ([]MilestoneEvent, error) {
+	var milestoneEventList []MilestoneEvent
+
+	err := repo.db.Where("campaign_id IN ?", campaignIDs).Order("created_at desc, id desc").Limit(limit).Find(&milestoneEventList).Error
+	if err != nil {
+		return milestoneEventList, err
+	}
+
+	return milestoneEventList, nil
+}
+
+func (repo *repository) SaveMilestoneEvents(campaignID int, detect func(campaign Campaign) []MilestoneEvent) ([]MilestoneEvent, error) {
+	milestoneEventList := []MilestoneEvent{}
+
+	//The campaign row stays locked while events are detected, so concurrent payments never record a milestone twice
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		var campaign Campaign
+
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&campaign, campaignID).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where("campaign_id = ?", campaignID).Find(&campaign.StretchGoals).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where("campaign_id = ?", campaignID).Find(&campaign.MilestoneEvents).Error
+		if err != nil {
+			return err
+		}
+
+		milestoneEventList = detect(campaign)
+		if len(milestoneEventList) == 0 {
+			return nil
+		}
+
+		return tx.Create(&milestoneEventList).Error
+	})
+
+	if err != nil {
+		return []MilestoneEvent{}, err
+	}
+
+	return milestoneEventList, nil
+}
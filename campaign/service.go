@@ -3,6 +3,14 @@ package campaign
 import (
 	"errors"
 	"fmt"
+	"log"
+	"rocketship/imaging"
+	"rocketship/mailer"
+	"rocketship/media"
+	"rocketship/money"
+	"rocketship/user"
+	"strings"
+	"time"
 
 	"github.com/gosimple/slug"
 )
@@ -13,14 +21,35 @@ type Service interface {
 	CreateCampaign(input CreateCampaignInput) (Campaign, error)
 	UpdateCampaign(campaignID CampaignDetailInput, input CreateCampaignInput) (Campaign, error)
 	CreateCampaignImage(input CreateCampaignImageInput, filePath string) (CampaignImage, error)
+	DeleteCampaignImage(imageID CampaignImageDetailInput, currentUser user.User) (CampaignImage, error)
+	MarkCampaignImageAsPrimary(imageID CampaignImageDetailInput, currentUser user.User) (CampaignImage, error)
+	ReorderCampaignImages(campaignID CampaignDetailInput, input ReorderCampaignImagesInput) ([]CampaignImage, error)
+	IsCampaignImageFileInUse(fileName string) (bool, error)
+	CreateCampaignVideo(input CreateCampaignVideoInput, fileName string, contentType string) (CampaignMedia, error)
+	CreateCampaignEmbed(input CreateCampaignEmbedInput) (CampaignMedia, error)
+	DeleteCampaignMedia(mediaID CampaignMediaDetailInput, currentUser user.User) (CampaignMedia, error)
+	IsCampaignMediaFileInUse(fileName string) (bool, error)
+	FindCampaignUpdates(campaignID CampaignDetailInput) ([]CampaignUpdate, error)
+	CreateCampaignUpdate(campaignID CampaignDetailInput, input CreateCampaignUpdateInput) (CampaignUpdate, error)
+	FindCollaborators(campaignID CampaignDetailInput, currentUser user.User) ([]Collaborator, error)
+	InviteCollaborator(campaignID CampaignDetailInput, input InviteCollaboratorInput) (Collaborator, error)
+	AcceptInvitation(input AcceptInvitationInput) (Collaborator, error)
+	RemoveCollaborator(collaboratorID CollaboratorDetailInput, currentUser user.User) (Collaborator, error)
+	CreateStretchGoal(campaignID CampaignDetailInput, input CreateStretchGoalInput) (StretchGoal, error)
+	DeleteStretchGoal(stretchGoalID StretchGoalDetailInput, currentUser user.User) (StretchGoal, error)
+	RecordMilestones(campaignID int) ([]MilestoneEvent, error)
+	FindPendingVariants() ([]imaging.Job, error)
+	SaveVariantStatus(job imaging.Job, status string) error
 }
 
 type service struct {
-	repository Repository
+	repository    Repository
+	resolver      media.Resolver
+	mailerService mailer.Service
 }
 
-func NewService(repository Repository) *service {
-	return &service{repository}
+func NewService(repository Repository, resolver media.Resolver, mailerService mailer.Service) *service {
+	return &service{repository, resolver, mailerService}
 }
 
 func (s *service) FindCampaigns(userID int) ([]Campaign, error) {
@@ -52,12 +81,35 @@ func (s *service) FindCampaignByID(campaignID CampaignDetailInput) (Campaign, er
 }
 
 func (s *service) CreateCampaign(input CreateCampaignInput) (Campaign, error) {
+	if input.MaxDonation != 0 && input.MaxDonation < input.MinDonation {
+		return Campaign{}, errors.New("Maximum donation must not be lower than the minimum donation")
+	}
+
+	if !money.IsSupported(input.Currency) {
+		return Campaign{}, errors.New("Currency is not supported")
+	}
+
+	if input.EndsAt != nil && !input.EndsAt.After(time.Now()) {
+		return Campaign{}, errors.New("Campaign end date must be in the future")
+	}
+
+	status := StatusActive
+	if input.Status != "" {
+		status = input.Status
+	}
+
 	campaign := Campaign{
 		Name:             input.Name,
 		Description:      input.Description,
 		ShortDescription: input.ShortDescription,
+		Currency:         money.Normalize(input.Currency),
 		GoalAmount:       input.GoalAmount,
+		MinDonation:      input.MinDonation,
+		MaxDonation:      input.MaxDonation,
 		Perks:            input.Perks,
+		Category:         strings.ToLower(strings.TrimSpace(input.Category)),
+		Status:           status,
+		EndsAt:           input.EndsAt,
 		UserID:           input.User.ID,
 	}
 
@@ -78,16 +130,54 @@ func (s *service) UpdateCampaign(campaignID CampaignDetailInput, input CreateCam
 		return campaign, err
 	}
 
-	if input.User.ID != campaign.User.ID {
-		return campaign, errors.New("Could not update this campaign due to lack of credentials")
+	err = s.checkAccess(campaign, input.User, PermissionEdit, "Could not update this campaign due to lack of credentials")
+	if err != nil {
+		return campaign, err
+	}
+
+	if input.MaxDonation != 0 && input.MaxDonation < input.MinDonation {
+		return campaign, errors.New("Maximum donation must not be lower than the minimum donation")
+	}
+
+	//Raised amounts are kept in the campaign currency, so it is fixed after the first donation
+	currency := money.Normalize(campaign.Currency)
+	if input.Currency != "" && money.Normalize(input.Currency) != currency {
+		if !money.IsSupported(input.Currency) {
+			return campaign, errors.New("Currency is not supported")
+		}
+
+		if campaign.FunderAmount != 0 || campaign.CurrentAmount != 0 {
+			return campaign, errors.New("Currency could not be changed after receiving donations")
+		}
+
+		//Pending donations were already converted into the current currency
+		transactionCount, err := s.repository.CountOpenTransactionByCampaignID(campaign.ID)
+		if err != nil {
+			return campaign, err
+		}
+
+		if transactionCount != 0 {
+			return campaign, errors.New("Currency could not be changed while donations are pending")
+		}
+
+		currency = money.Normalize(input.Currency)
 	}
 
 	campaign.Name = input.Name
 	campaign.Description = input.Description
 	campaign.ShortDescription = input.ShortDescription
+	campaign.Currency = currency
 	campaign.GoalAmount = input.GoalAmount
+	campaign.MinDonation = input.MinDonation
+	campaign.MaxDonation = input.MaxDonation
 	campaign.Perks = input.Perks
-	slugWireframe := fmt.Sprintf("%s %d", input.Name, input.User.ID)
+	campaign.Category = strings.ToLower(strings.TrimSpace(input.Category))
+	campaign.EndsAt = input.EndsAt
+	if input.Status != "" {
+		campaign.Status = input.Status
+	}
+	//Editors may update the campaign, the slug keeps using the owner's ID
+	slugWireframe := fmt.Sprintf("%s %d", input.Name, campaign.UserID)
 	campaign.Slug = slug.Make(slugWireframe)
 
 	updatedCampaign, err := s.repository.UpdateCampaign(campaign)
@@ -104,8 +194,22 @@ func (s *service) CreateCampaignImage(input CreateCampaignImageInput, filePath s
 		return CampaignImage{}, err
 	}
 
-	if campaign.UserID != input.User.ID {
-		return CampaignImage{}, errors.New("Could not upload campaign image due to lack of credentials")
+	err = s.checkAccess(campaign, input.User, PermissionEdit, "Could not upload campaign image due to lack of credentials")
+	if err != nil {
+		return CampaignImage{}, err
+	}
+
+	imageList, err := s.repository.FindCampaignImageByCampaignID(input.CampaignID)
+	if err != nil {
+		return CampaignImage{}, err
+	}
+
+	//New images go to the end of the gallery
+	position := 1
+	for _, image := range imageList {
+		if image.Position >= position {
+			position = image.Position + 1
+		}
 	}
 
 	isPrimary := 0
@@ -122,6 +226,7 @@ func (s *service) CreateCampaignImage(input CreateCampaignImageInput, filePath s
 		FileName:   filePath,
 		CampaignID: input.CampaignID,
 		IsPrimary:  isPrimary,
+		Position:   position,
 	}
 
 	createdImage, err := s.repository.UploadCampaignImage(campaignImage)
@@ -131,3 +236,267 @@ func (s *service) CreateCampaignImage(input CreateCampaignImageInput, filePath s
 
 	return createdImage, nil
 }
+
+func (s *service) DeleteCampaignImage(imageID CampaignImageDetailInput, currentUser user.User) (CampaignImage, error) {
+	campaignImage, err := s.findOwnedCampaignImage(imageID.ID, currentUser, "Could not delete campaign image due to lack of credentials")
+	if err != nil {
+		return campaignImage, err
+	}
+
+	err = s.repository.DeleteCampaignImage(campaignImage)
+	if err != nil {
+		return campaignImage, err
+	}
+
+	return campaignImage, nil
+}
+
+func (s *service) MarkCampaignImageAsPrimary(imageID CampaignImageDetailInput, currentUser user.User) (CampaignImage, error) {
+	campaignImage, err := s.findOwnedCampaignImage(imageID.ID, currentUser, "Could not update campaign image due to lack of credentials")
+	if err != nil {
+		return campaignImage, err
+	}
+
+	updatedImage, err := s.repository.MarkAsPrimary(campaignImage)
+	if err != nil {
+		return updatedImage, err
+	}
+
+	return updatedImage, nil
+}
+
+func (s *service) ReorderCampaignImages(campaignID CampaignDetailInput, input ReorderCampaignImagesInput) ([]CampaignImage, error) {
+	campaign, err := s.repository.FindCampaignByID(campaignID.ID)
+	if err != nil {
+		return []CampaignImage{}, err
+	}
+
+	err = s.checkAccess(campaign, input.User, PermissionEdit, "Could not reorder campaign images due to lack of credentials")
+	if err != nil {
+		return []CampaignImage{}, err
+	}
+
+	//The new order has to list every image of the campaign exactly once
+	remaining := map[int]bool{}
+	for _, image := range campaign.CampaignImages {
+		remaining[image.ID] = true
+	}
+
+	if len(input.ImageIDs) != len(remaining) {
+		return []CampaignImage{}, errors.New("Image order must include every campaign image once")
+	}
+
+	for _, imageID := range input.ImageIDs {
+		if !remaining[imageID] {
+			return []CampaignImage{}, errors.New("Image order must include every campaign image once")
+		}
+
+		delete(remaining, imageID)
+	}
+
+	err = s.repository.UpdateCampaignImagePositions(campaign.ID, input.ImageIDs)
+	if err != nil {
+		return []CampaignImage{}, err
+	}
+
+	imageList, err := s.repository.FindCampaignImageByCampaignID(campaign.ID)
+	if err != nil {
+		return imageList, err
+	}
+
+	return imageList, nil
+}
+
+// IsCampaignImageFileInUse tells whether another image row still points at a stored file
+func (s *service) IsCampaignImageFileInUse(fileName string) (bool, error) {
+	count, err := s.repository.CountCampaignImageByFileName(fileName)
+	if err != nil {
+		return true, err
+	}
+
+	return count > 0, nil
+}
+
+func (s *service) findOwnedCampaignImage(imageID int, currentUser user.User, message string) (CampaignImage, error) {
+	campaignImage, err := s.repository.FindCampaignImageByID(imageID)
+	if err != nil {
+		return campaignImage, err
+	}
+
+	if campaignImage.ID == 0 {
+		return campaignImage, errors.New("No campaign image found with this ID")
+	}
+
+	campaign, err := s.repository.FindCampaignByID(campaignImage.CampaignID)
+	if err != nil {
+		return campaignImage, err
+	}
+
+	err = s.checkAccess(campaign, currentUser, PermissionEdit, message)
+	if err != nil {
+		return campaignImage, err
+	}
+
+	return campaignImage, nil
+}
+
+func (s *service) CreateCampaignVideo(input CreateCampaignVideoInput, fileName string, contentType string) (CampaignMedia, error) {
+	position, err := s.nextMediaPosition(input.CampaignID, input.User, "Could not upload campaign video due to lack of credentials")
+	if err != nil {
+		return CampaignMedia{}, err
+	}
+
+	campaignMedia := CampaignMedia{
+		CampaignID:  input.CampaignID,
+		Kind:        "upload",
+		FileName:    fileName,
+		ContentType: contentType,
+		Title:       strings.TrimSpace(input.Title),
+		IsPitch:     input.IsPitch,
+		Position:    position,
+	}
+
+	createdMedia, err := s.repository.CreateCampaignMedia(campaignMedia)
+	if err != nil {
+		return createdMedia, err
+	}
+
+	return createdMedia, nil
+}
+
+func (s *service) CreateCampaignEmbed(input CreateCampaignEmbedInput) (CampaignMedia, error) {
+	position, err := s.nextMediaPosition(input.CampaignID, input.User, "Could not add campaign video due to lack of credentials")
+	if err != nil {
+		return CampaignMedia{}, err
+	}
+
+	embed, err := media.ParseVideoURL(input.URL)
+	if err != nil {
+		return CampaignMedia{}, err
+	}
+
+	//Metadata is optional, only a video the provider refuses to embed is rejected
+	metadata, err := s.resolver.Resolve(embed)
+	if errors.Is(err, media.ErrNotFound) {
+		return CampaignMedia{}, err
+	}
+	if err != nil {
+		log.Println("Failed to resolve video metadata:", err)
+	}
+
+	campaignMedia := CampaignMedia{
+		CampaignID:   input.CampaignID,
+		Kind:         "embed",
+		Provider:     embed.Provider,
+		SourceURL:    embed.SourceURL,
+		EmbedURL:     embed.EmbedURL,
+		Title:        metadata.Title,
+		AuthorName:   metadata.AuthorName,
+		ThumbnailURL: metadata.ThumbnailURL,
+		Width:        metadata.Width,
+		Height:       metadata.Height,
+		IsPitch:      input.IsPitch,
+		Position:     position,
+	}
+
+	if strings.TrimSpace(input.Title) != "" {
+		campaignMedia.Title = strings.TrimSpace(input.Title)
+	}
+
+	createdMedia, err := s.repository.CreateCampaignMedia(campaignMedia)
+	if err != nil {
+		return createdMedia, err
+	}
+
+	return createdMedia, nil
+}
+
+func (s *service) DeleteCampaignMedia(mediaID CampaignMediaDetailInput, currentUser user.User) (CampaignMedia, error) {
+	campaignMedia, err := s.repository.FindCampaignMediaByID(mediaID.ID)
+	if err != nil {
+		return campaignMedia, err
+	}
+
+	if campaignMedia.ID == 0 {
+		return campaignMedia, errors.New("No campaign media found with this ID")
+	}
+
+	campaign, err := s.repository.FindCampaignByID(campaignMedia.CampaignID)
+	if err != nil {
+		return campaignMedia, err
+	}
+
+	err = s.checkAccess(campaign, currentUser, PermissionEdit, "Could not delete campaign media due to lack of credentials")
+	if err != nil {
+		return campaignMedia, err
+	}
+
+	err = s.repository.DeleteCampaignMedia(campaignMedia)
+	if err != nil {
+		return campaignMedia, err
+	}
+
+	return campaignMedia, nil
+}
+
+func (s *service) IsCampaignMediaFileInUse(fileName string) (bool, error) {
+	count, err := s.repository.CountCampaignMediaByFileName(fileName)
+	if err != nil {
+		return true, err
+	}
+
+	return count > 0, nil
+}
+
+// nextMediaPosition checks access the same way images do and places new media last
+func (s *service) nextMediaPosition(campaignID int, currentUser user.User, message string) (int, error) {
+	campaign, err := s.repository.FindCampaignByID(campaignID)
+	if err != nil {
+		return 0, err
+	}
+
+	err = s.checkAccess(campaign, currentUser, PermissionEdit, message)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := s.repository.CountCampaignMediaByCampaignID(campaignID)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count) + 1, nil
+}
+
+func (s *service) checkAccess(campaign Campaign, currentUser user.User, permission string, message string) error {
+	allowed, err := CanAccess(s.repository, campaign, currentUser.ID, permission)
+	if err != nil {
+		return err
+	}
+
+	if !allowed {
+		return errors.New(message)
+	}
+
+	return nil
+}
+
+func (s *service) FindPendingVariants() ([]imaging.Job, error) {
+	campaignImageList, err := s.repository.FindCampaignImageByVariantStatus(imaging.StatusPending)
+	if err != nil {
+		return []imaging.Job{}, err
+	}
+
+	jobList := []imaging.Job{}
+	for _, campaignImage := range campaignImageList {
+		jobList = append(jobList, imaging.Job{ID: campaignImage.ID, Key: campaignImage.FileName})
+	}
+
+	return jobList, nil
+}
+
+func (s *service) SaveVariantStatus(job imaging.Job, status string) error {
+	campaignImage := CampaignImage{ID: job.ID, FileName: job.Key}
+
+	return s.repository.UpdateCampaignImageVariantStatus(campaignImage, status)
+}
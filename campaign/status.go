@@ -0,0 +1,29 @@
+package campaign
+
+import "time"
+
+const (
+	StatusActive = "active"
+	StatusClosed = "closed"
+)
+
+// IsFundable tells whether the campaign still takes donations, campaigns from before statuses existed count as active
+func IsFundable(campaign Campaign, now time.Time) bool {
+	if campaign.Status == StatusClosed {
+		return false
+	}
+
+	if campaign.EndsAt != nil && !now.Before(*campaign.EndsAt) {
+		return false
+	}
+
+	return true
+}
+
+func campaignStatus(campaign Campaign) string {
+	if campaign.Status == "" {
+		return StatusActive
+	}
+
+	return campaign.Status
+}
@@ -0,0 +1,49 @@
+package campaign
+
+import (
+	"errors"
+	"strings"
+)
+
+func (s *service) FindCampaignUpdates(campaignID CampaignDetailInput) ([]CampaignUpdate, error) {
+	campaignUpdateList, err := s.repository.FindCampaignUpdateByCampaignID(campaignID.ID)
+	if err != nil {
+		return campaignUpdateList, err
+	}
+
+	return campaignUpdateList, nil
+}
+
+func (s *service) CreateCampaignUpdate(campaignID CampaignDetailInput, input CreateCampaignUpdateInput) (CampaignUpdate, error) {
+	campaign, err := s.repository.FindCampaignByID(campaignID.ID)
+	if err != nil {
+		return CampaignUpdate{}, err
+	}
+
+	//Editors post updates on behalf of the team, the update keeps its author
+	err = s.checkAccess(campaign, input.User, PermissionEdit, "Could not post campaign updates due to lack of credentials")
+	if err != nil {
+		return CampaignUpdate{}, err
+	}
+
+	if strings.TrimSpace(input.Title) == "" || strings.TrimSpace(input.Body) == "" {
+		return CampaignUpdate{}, errors.New("Campaign updates need a title and a body")
+	}
+
+	campaignUpdate := CampaignUpdate{
+		CampaignID: campaign.ID,
+		UserID:     input.User.ID,
+		Title:      strings.TrimSpace(input.Title),
+		Body:       input.Body,
+	}
+
+	newCampaignUpdate, err := s.repository.CreateCampaignUpdate(campaignUpdate)
+	if err != nil {
+		return newCampaignUpdate, err
+	}
+
+	//The author is shown next to the update
+	newCampaignUpdate.User = input.User
+
+	return newCampaignUpdate, nil
+}
@@ -0,0 +1,84 @@
+package campaign
+
+import (
+	"rocketship/user"
+	"testing"
+)
+
+// fakeRepository keeps just what posting updates needs, every other method comes from the nil interface
+type fakeRepository struct {
+	Repository
+	campaign        Campaign
+	collaborators   []Collaborator
+	campaignUpdates []CampaignUpdate
+}
+
+func (repo *fakeRepository) FindCampaignByID(campaignID int) (Campaign, error) {
+	if campaignID != repo.campaign.ID {
+		return Campaign{}, nil
+	}
+
+	return repo.campaign, nil
+}
+
+func (repo *fakeRepository) FindCollaboratorByUserID(campaignID int, userID int) (Collaborator, error) {
+	for _, collaborator := range repo.collaborators {
+		if collaborator.CampaignID == campaignID && collaborator.UserID == userID {
+			return collaborator, nil
+		}
+	}
+
+	return Collaborator{}, nil
+}
+
+func (repo *fakeRepository) CreateCampaignUpdate(campaignUpdate CampaignUpdate) (CampaignUpdate, error) {
+	campaignUpdate.ID = len(repo.campaignUpdates) + 1
+	repo.campaignUpdates = append(repo.campaignUpdates, campaignUpdate)
+
+	return campaignUpdate, nil
+}
+
+func TestCreateCampaignUpdatePermissions(t *testing.T) {
+	repository := &fakeRepository{
+		campaign: Campaign{ID: 7, UserID: 1},
+		collaborators: []Collaborator{
+			{ID: 1, CampaignID: 7, UserID: 2, Role: RoleEditor, Status: "accepted"},
+			{ID: 2, CampaignID: 7, UserID: 3, Role: RoleFinanceViewer, Status: "accepted"},
+			{ID: 3, CampaignID: 7, UserID: 4, Role: RoleEditor, Status: "invited"},
+		},
+	}
+	service := NewService(repository, nil, nil)
+
+	testCases := []struct {
+		name    string
+		userID  int
+		allowed bool
+	}{
+		{"owner", 1, true},
+		{"editor", 2, true},
+		{"finance viewer", 3, false},
+		{"pending editor", 4, false},
+		{"stranger", 5, false},
+	}
+
+	for _, testCase := range testCases {
+		input := CreateCampaignUpdateInput{
+			Title: "Shipping next week",
+			Body:  "The **first batch** is packed.",
+			User:  user.User{ID: testCase.userID},
+		}
+
+		campaignUpdate, err := service.CreateCampaignUpdate(CampaignDetailInput{ID: 7}, input)
+		if testCase.allowed && (err != nil || campaignUpdate.UserID != testCase.userID) {
+			t.Errorf("%s: CreateCampaignUpdate() = %+v, %v, expected an update by user %d", testCase.name, campaignUpdate, err, testCase.userID)
+		}
+
+		if !testCase.allowed && err == nil {
+			t.Errorf("%s: CreateCampaignUpdate() should be refused", testCase.name)
+		}
+	}
+
+	if len(repository.campaignUpdates) != 2 {
+		t.Errorf("%d updates were saved, expected 2", len(repository.campaignUpdates))
+	}
+}
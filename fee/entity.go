@@ -0,0 +1,24 @@
+package fee
+
+import "time"
+
+// Rule percentages are stored in basis points, so 250 means 2.5%.
+// Rules are never edited, a change is a new rule with a later EffectiveFrom.
+type Rule struct {
+	ID                     int
+	CampaignID             int
+	PlatformFeeBasisPoints int
+	ProviderFeeBasisPoints int
+	ProviderFeeFixed       int
+	EffectiveFrom          time.Time
+	CreatedBy              int
+	CreatedAt              time.Time
+	UpdatedAt              time.Time
+}
+
+// PlatformFee is in the campaign currency, ProviderFee in the currency the provider charges in
+type Fee struct {
+	RuleID      int
+	PlatformFee int
+	ProviderFee int
+}
@@ -0,0 +1,36 @@
+package fee
+
+import "time"
+
+type RuleFormatter struct {
+	ID                     int       `json:"id"`
+	CampaignID             int       `json:"campaign_id"`
+	PlatformFeeBasisPoints int       `json:"platform_fee_basis_points"`
+	ProviderFeeBasisPoints int       `json:"provider_fee_basis_points"`
+	ProviderFeeFixed       int       `json:"provider_fee_fixed"`
+	EffectiveFrom          time.Time `json:"effective_from"`
+}
+
+func FormatRule(rule Rule) RuleFormatter {
+	formatter := RuleFormatter{
+		ID:                     rule.ID,
+		CampaignID:             rule.CampaignID,
+		PlatformFeeBasisPoints: rule.PlatformFeeBasisPoints,
+		ProviderFeeBasisPoints: rule.ProviderFeeBasisPoints,
+		ProviderFeeFixed:       rule.ProviderFeeFixed,
+		EffectiveFrom:          rule.EffectiveFrom,
+	}
+
+	return formatter
+}
+
+func FormatRuleList(ruleList []Rule) []RuleFormatter {
+	formatterList := []RuleFormatter{}
+
+	for _, rule := range ruleList {
+		formatter := FormatRule(rule)
+		formatterList = append(formatterList, formatter)
+	}
+
+	return formatterList
+}
@@ -0,0 +1,15 @@
+package fee
+
+import (
+	"rocketship/user"
+	"time"
+)
+
+type CreateRuleInput struct {
+	CampaignID             int       `json:"campaign_id" binding:"gte=0"`
+	PlatformFeeBasisPoints int       `json:"platform_fee_basis_points" binding:"gte=0,lte=10000"`
+	ProviderFeeBasisPoints int       `json:"provider_fee_basis_points" binding:"gte=0,lte=10000"`
+	ProviderFeeFixed       int       `json:"provider_fee_fixed" binding:"gte=0"`
+	EffectiveFrom          time.Time `json:"effective_from"`
+	User                   user.User
+}
@@ -0,0 +1,53 @@
+package fee
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	FindAllRule() ([]Rule, error)
+	FindEffectiveRule(campaignID int, at time.Time) (Rule, error)
+	CreateRule(rule Rule) (Rule, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{db}
+}
+
+func (repo *repository) FindAllRule() ([]Rule, error) {
+	var ruleList []Rule
+
+	err := repo.db.Order("campaign_id asc, effective_from desc").Find(&ruleList).Error
+	if err != nil {
+		return ruleList, err
+	}
+
+	return ruleList, nil
+}
+
+func (repo *repository) FindEffectiveRule(campaignID int, at time.Time) (Rule, error) {
+	var rule Rule
+
+	err := repo.db.Where("campaign_id = ? AND effective_from <= ?", campaignID, at).Order("effective_from desc").Limit(1).Find(&rule).Error
+	if err != nil {
+		return rule, err
+	}
+
+	return rule, nil
+}
+
+func (repo *repository) CreateRule(rule Rule) (Rule, error) {
+	err := repo.db.Create(&rule).Error
+
+	if err != nil {
+		return rule, err
+	}
+
+	return rule, nil
+}
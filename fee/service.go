@@ -0,0 +1,103 @@
+package fee
+
+import (
+	"errors"
+	"rocketship/user"
+	"time"
+)
+
+type Service interface {
+	FindRules(currentUser user.User) ([]Rule, error)
+	CreateRule(input CreateRuleInput) (Rule, error)
+	CalculateFee(campaignID int, amount int, chargeAmount int, at time.Time) (Fee, error)
+}
+
+type service struct {
+	repository Repository
+}
+
+func NewService(repository Repository) *service {
+	return &service{repository}
+}
+
+func (service *service) FindRules(currentUser user.User) ([]Rule, error) {
+	if currentUser.Role != "admin" {
+		return []Rule{}, errors.New("could not find fee rules due to lack of credentials")
+	}
+
+	ruleList, err := service.repository.FindAllRule()
+	if err != nil {
+		return ruleList, err
+	}
+
+	return ruleList, nil
+}
+
+func (service *service) CreateRule(input CreateRuleInput) (Rule, error) {
+	if input.User.Role != "admin" {
+		return Rule{}, errors.New("could not create fee rule due to lack of credentials")
+	}
+
+	effectiveFrom := input.EffectiveFrom
+	if effectiveFrom.IsZero() {
+		effectiveFrom = time.Now()
+	}
+
+	//Backdated rules would change the fees of transactions that were already charged
+	if effectiveFrom.Before(time.Now().Add(-time.Minute)) {
+		return Rule{}, errors.New("fee rules could not take effect in the past")
+	}
+
+	rule := Rule{
+		CampaignID:             input.CampaignID,
+		PlatformFeeBasisPoints: input.PlatformFeeBasisPoints,
+		ProviderFeeBasisPoints: input.ProviderFeeBasisPoints,
+		ProviderFeeFixed:       input.ProviderFeeFixed,
+		EffectiveFrom:          effectiveFrom,
+		CreatedBy:              input.User.ID,
+	}
+
+	newRule, err := service.repository.CreateRule(rule)
+	if err != nil {
+		return newRule, err
+	}
+
+	return newRule, nil
+}
+
+// CalculateFee applies the campaign's own rule when it has one, and the global rule otherwise.
+// The platform fee is taken from the amount in campaign currency, the provider fee from what the provider charged.
+func (service *service) CalculateFee(campaignID int, amount int, chargeAmount int, at time.Time) (Fee, error) {
+	rule, err := service.repository.FindEffectiveRule(campaignID, at)
+	if err != nil {
+		return Fee{}, err
+	}
+
+	if rule.ID == 0 {
+		rule, err = service.repository.FindEffectiveRule(0, at)
+		if err != nil {
+			return Fee{}, err
+		}
+	}
+
+	//No rule at all means the platform does not charge anything yet
+	if rule.ID == 0 {
+		return Fee{}, nil
+	}
+
+	fee := Fee{
+		RuleID:      rule.ID,
+		PlatformFee: percentageOf(amount, rule.PlatformFeeBasisPoints),
+	}
+
+	//Nothing went through the provider when a voucher covered the whole pledge
+	if chargeAmount > 0 {
+		fee.ProviderFee = percentageOf(chargeAmount, rule.ProviderFeeBasisPoints) + rule.ProviderFeeFixed
+	}
+
+	return fee, nil
+}
+
+func percentageOf(amount int, basisPoints int) int {
+	return (amount*basisPoints + 5000) / 10000
+}
@@ -0,0 +1,64 @@
+package fee
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeRepository picks the latest rule of a campaign that is in effect, the way the query does
+type fakeRepository struct {
+	Repository
+	rules []Rule
+}
+
+func (repo *fakeRepository) FindEffectiveRule(campaignID int, at time.Time) (Rule, error) {
+	effectiveRule := Rule{}
+	for _, rule := range repo.rules {
+		if rule.CampaignID == campaignID && !rule.EffectiveFrom.After(at) && !rule.EffectiveFrom.Before(effectiveRule.EffectiveFrom) {
+			effectiveRule = rule
+		}
+	}
+
+	return effectiveRule, nil
+}
+
+func TestCalculateFee(t *testing.T) {
+	changedAt := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	repository := &fakeRepository{rules: []Rule{
+		{ID: 1, CampaignID: 0, PlatformFeeBasisPoints: 500, ProviderFeeBasisPoints: 290, ProviderFeeFixed: 2000, EffectiveFrom: changedAt.AddDate(-1, 0, 0)},
+		{ID: 2, CampaignID: 0, PlatformFeeBasisPoints: 250, ProviderFeeBasisPoints: 290, ProviderFeeFixed: 2000, EffectiveFrom: changedAt},
+		{ID: 3, CampaignID: 7, PlatformFeeBasisPoints: 0, ProviderFeeBasisPoints: 100, EffectiveFrom: changedAt.AddDate(-1, 0, 0)},
+	}}
+
+	testCases := []struct {
+		name         string
+		campaignID   int
+		amount       int
+		chargeAmount int
+		at           time.Time
+		expected     Fee
+	}{
+		{"before the change", 1, 100000, 100000, changedAt.Add(-time.Second), Fee{RuleID: 1, PlatformFee: 5000, ProviderFee: 4900}},
+		{"at the change", 1, 100000, 100000, changedAt, Fee{RuleID: 2, PlatformFee: 2500, ProviderFee: 4900}},
+		{"campaign rule", 7, 100000, 100000, changedAt, Fee{RuleID: 3, PlatformFee: 0, ProviderFee: 1000}},
+		{"half rounds up", 1, 20, 50, changedAt, Fee{RuleID: 2, PlatformFee: 1, ProviderFee: 2001}},
+		{"below half rounds down", 1, 19, 51, changedAt, Fee{RuleID: 2, PlatformFee: 0, ProviderFee: 2001}},
+		{"covered by a voucher", 1, 100000, 0, changedAt, Fee{RuleID: 2, PlatformFee: 2500, ProviderFee: 0}},
+		{"before any rule", 1, 100000, 100000, changedAt.AddDate(-2, 0, 0), Fee{}},
+	}
+
+	service := NewService(repository)
+
+	for _, testCase := range testCases {
+		fee, err := service.CalculateFee(testCase.campaignID, testCase.amount, testCase.chargeAmount, testCase.at)
+		if err != nil {
+			t.Errorf("%s: CalculateFee() returned %v", testCase.name, err)
+			continue
+		}
+
+		if fee != testCase.expected {
+			t.Errorf("%s: CalculateFee() = %+v, expected %+v", testCase.name, fee, testCase.expected)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package follow
+
+import "time"
+
+const (
+	KindCampaign = "campaign"
+	KindCreator  = "creator"
+)
+
+// Follow points at a campaign or at a creator's user ID depending on Kind
+type Follow struct {
+	ID        int
+	UserID    int
+	Kind      string
+	TargetID  int
+	CreatedAt time.Time
+}
+
+type FeedItem struct {
+	Kind         string
+	CampaignID   int
+	CampaignName string
+	CampaignSlug string
+	Message      string
+	CreatedAt    time.Time
+}
@@ -0,0 +1,87 @@
+package follow
+
+import (
+	"rocketship/campaign"
+	"rocketship/storage"
+	"rocketship/user"
+	"time"
+)
+
+type FollowFormatter struct {
+	Kind       string    `json:"kind"`
+	TargetID   int       `json:"target_id"`
+	IsFollowed bool      `json:"is_followed"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type FollowingFormatter struct {
+	Campaigns []campaign.CampaignFormatter `json:"campaigns"`
+	Creators  []CreatorFormatter           `json:"creators"`
+}
+
+type CreatorFormatter struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	ImageURL string `json:"image_url"`
+}
+
+type FeedItemFormatter struct {
+	Kind         string    `json:"kind"`
+	CampaignID   int       `json:"campaign_id"`
+	CampaignName string    `json:"campaign_name"`
+	CampaignSlug string    `json:"campaign_slug"`
+	Message      string    `json:"message"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func FormatFollow(follow Follow) FollowFormatter {
+	formatter := FollowFormatter{
+		Kind:       follow.Kind,
+		TargetID:   follow.TargetID,
+		IsFollowed: true,
+		CreatedAt:  follow.CreatedAt,
+	}
+
+	return formatter
+}
+
+func FormatFollowing(following Following, storageService storage.Service) FollowingFormatter {
+	formatter := FollowingFormatter{
+		Campaigns: campaign.FormatCampaigns(following.Campaigns, storageService),
+		Creators:  []CreatorFormatter{},
+	}
+
+	for _, creator := range following.Creators {
+		formatter.Creators = append(formatter.Creators, FormatCreator(creator, storageService))
+	}
+
+	return formatter
+}
+
+func FormatCreator(creator user.User, storageService storage.Service) CreatorFormatter {
+	formatter := CreatorFormatter{
+		ID:       creator.ID,
+		Name:     creator.Name,
+		ImageURL: storage.URL(storageService, creator.AvatarFileName),
+	}
+
+	return formatter
+}
+
+func FormatFeed(feed []FeedItem) []FeedItemFormatter {
+	formatterList := []FeedItemFormatter{}
+
+	for _, item := range feed {
+		formatter := FeedItemFormatter{
+			Kind:         item.Kind,
+			CampaignID:   item.CampaignID,
+			CampaignName: item.CampaignName,
+			CampaignSlug: item.CampaignSlug,
+			Message:      item.Message,
+			CreatedAt:    item.CreatedAt,
+		}
+		formatterList = append(formatterList, formatter)
+	}
+
+	return formatterList
+}
@@ -0,0 +1,8 @@
+package follow
+
+import "rocketship/user"
+
+type FollowInput struct {
+	ID   int `uri:"id" binding:"required"`
+	User user.User
+}
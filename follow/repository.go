@@ -0,0 +1,71 @@
+package follow
+
+import "gorm.io/gorm"
+
+type Repository interface {
+	FindFollowByUserID(userID int) ([]Follow, error)
+	FindFollowerIDs(kind string, targetID int) ([]int, error)
+	CountFollowByTarget(kind string, targetID int) (int64, error)
+	CreateFollow(follow Follow) (Follow, error)
+	DeleteFollow(follow Follow) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{db}
+}
+
+func (repo *repository) FindFollowByUserID(userID int) ([]Follow, error) {
+	var followList []Follow
+
+	err := repo.db.Where("user_id = ?", userID).Order("created_at desc").Find(&followList).Error
+	if err != nil {
+		return followList, err
+	}
+
+	return followList, nil
+}
+
+func (repo *repository) FindFollowerIDs(kind string, targetID int) ([]int, error) {
+	var userIDs []int
+
+	err := repo.db.Model(&Follow{}).Where("kind = ? AND target_id = ?", kind, targetID).Distinct().Pluck("user_id", &userIDs).Error
+	if err != nil {
+		return userIDs, err
+	}
+
+	return userIDs, nil
+}
+
+func (repo *repository) CountFollowByTarget(kind string, targetID int) (int64, error) {
+	var count int64
+
+	err := repo.db.Model(&Follow{}).Where("kind = ? AND target_id = ?", kind, targetID).Distinct("user_id").Count(&count).Error
+	if err != nil {
+		return count, err
+	}
+
+	return count, nil
+}
+
+// CreateFollow returns the existing row when the user already follows the target
+func (repo *repository) CreateFollow(follow Follow) (Follow, error) {
+	err := repo.db.Where(Follow{UserID: follow.UserID, Kind: follow.Kind, TargetID: follow.TargetID}).FirstOrCreate(&follow).Error
+	if err != nil {
+		return follow, err
+	}
+
+	return follow, nil
+}
+
+func (repo *repository) DeleteFollow(follow Follow) error {
+	err := repo.db.Where("user_id = ? AND kind = ? AND target_id = ?", follow.UserID, follow.Kind, follow.TargetID).Delete(&Follow{}).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,249 @@
+package follow
+
+import (
+	"errors"
+	"rocketship/campaign"
+	"rocketship/user"
+	"sort"
+)
+
+// feedLimit caps the feed, older items are not paged yet
+const feedLimit = 50
+
+type Service interface {
+	FollowCampaign(input FollowInput) (Follow, error)
+	UnfollowCampaign(input FollowInput) error
+	FollowCreator(input FollowInput) (Follow, error)
+	UnfollowCreator(input FollowInput) error
+	FindFollowing(currentUser user.User) (Following, error)
+	FindFeed(currentUser user.User) ([]FeedItem, error)
+	FindFollowerIDs(kind string, targetID int) ([]int, error)
+	CountFollowers(kind string, targetID int) (int, error)
+}
+
+type Following struct {
+	Campaigns []campaign.Campaign
+	Creators  []user.User
+}
+
+type service struct {
+	repository         Repository
+	campaignRepository campaign.Repository
+	userRepository     user.Repository
+}
+
+func NewService(repository Repository, campaignRepository campaign.Repository, userRepository user.Repository) *service {
+	return &service{repository, campaignRepository, userRepository}
+}
+
+func (s *service) FollowCampaign(input FollowInput) (Follow, error) {
+	campaignByID, err := s.campaignRepository.FindCampaignByID(input.ID)
+	if err != nil {
+		return Follow{}, err
+	}
+
+	if campaignByID.ID == 0 {
+		return Follow{}, errors.New("No campaign found with this ID")
+	}
+
+	follow := Follow{
+		UserID:   input.User.ID,
+		Kind:     KindCampaign,
+		TargetID: campaignByID.ID,
+	}
+
+	return s.repository.CreateFollow(follow)
+}
+
+func (s *service) UnfollowCampaign(input FollowInput) error {
+	follow := Follow{
+		UserID:   input.User.ID,
+		Kind:     KindCampaign,
+		TargetID: input.ID,
+	}
+
+	return s.repository.DeleteFollow(follow)
+}
+
+func (s *service) FollowCreator(input FollowInput) (Follow, error) {
+	if input.ID == input.User.ID {
+		return Follow{}, errors.New("Users could not follow themselves")
+	}
+
+	creator, err := s.userRepository.FindUserByID(input.ID)
+	if err != nil {
+		return Follow{}, err
+	}
+
+	if creator.ID == 0 {
+		return Follow{}, errors.New("No user found with this ID")
+	}
+
+	follow := Follow{
+		UserID:   input.User.ID,
+		Kind:     KindCreator,
+		TargetID: creator.ID,
+	}
+
+	return s.repository.CreateFollow(follow)
+}
+
+func (s *service) UnfollowCreator(input FollowInput) error {
+	follow := Follow{
+		UserID:   input.User.ID,
+		Kind:     KindCreator,
+		TargetID: input.ID,
+	}
+
+	return s.repository.DeleteFollow(follow)
+}
+
+func (s *service) FindFollowing(currentUser user.User) (Following, error) {
+	following := Following{
+		Campaigns: []campaign.Campaign{},
+		Creators:  []user.User{},
+	}
+
+	campaignIDs, creatorIDs, err := s.findFollowedIDs(currentUser.ID)
+	if err != nil {
+		return following, err
+	}
+
+	if len(campaignIDs) > 0 {
+		following.Campaigns, err = s.campaignRepository.FindCampaignByIDs(campaignIDs)
+		if err != nil {
+			return following, err
+		}
+	}
+
+	if len(creatorIDs) > 0 {
+		following.Creators, err = s.userRepository.FindUserByIDs(creatorIDs)
+		if err != nil {
+			return following, err
+		}
+	}
+
+	return following, nil
+}
+
+// FindFeed lists what happened recently on followed campaigns and by followed creators, newest first
+func (s *service) FindFeed(currentUser user.User) ([]FeedItem, error) {
+	feed := []FeedItem{}
+
+	campaignIDs, creatorIDs, err := s.findFollowedIDs(currentUser.ID)
+	if err != nil {
+		return feed, err
+	}
+
+	//Updates posted on and milestones reached by followed campaigns
+	if len(campaignIDs) > 0 {
+		campaignUpdateList, err := s.campaignRepository.FindCampaignUpdateByCampaignIDs(campaignIDs, feedLimit)
+		if err != nil {
+			return feed, err
+		}
+
+		milestoneEventList, err := s.campaignRepository.FindMilestoneEventByCampaignIDs(campaignIDs, feedLimit)
+		if err != nil {
+			return feed, err
+		}
+
+		campaigns, err := s.campaignRepository.FindCampaignByIDs(campaignIDs)
+		if err != nil {
+			return feed, err
+		}
+
+		campaignByID := map[int]campaign.Campaign{}
+		for _, followedCampaign := range campaigns {
+			campaignByID[followedCampaign.ID] = followedCampaign
+		}
+
+		for _, campaignUpdate := range campaignUpdateList {
+			followedCampaign := campaignByID[campaignUpdate.CampaignID]
+
+			feed = append(feed, FeedItem{
+				Kind:         "update",
+				CampaignID:   campaignUpdate.CampaignID,
+				CampaignName: followedCampaign.Name,
+				CampaignSlug: followedCampaign.Slug,
+				Message:      followedCampaign.Name + " posted an update: " + campaignUpdate.Title,
+				CreatedAt:    campaignUpdate.CreatedAt,
+			})
+		}
+
+		for _, milestoneEvent := range milestoneEventList {
+			followedCampaign := campaignByID[milestoneEvent.CampaignID]
+
+			feed = append(feed, FeedItem{
+				Kind:         "milestone",
+				CampaignID:   milestoneEvent.CampaignID,
+				CampaignName: followedCampaign.Name,
+				CampaignSlug: followedCampaign.Slug,
+				Message:      campaign.MilestoneMessage(followedCampaign.Name, milestoneEvent),
+				CreatedAt:    milestoneEvent.CreatedAt,
+			})
+		}
+	}
+
+	//New campaigns by followed creators
+	if len(creatorIDs) > 0 {
+		campaigns, err := s.campaignRepository.FindCampaignByUserIDs(creatorIDs)
+		if err != nil {
+			return feed, err
+		}
+
+		for _, newCampaign := range campaigns {
+			feed = append(feed, FeedItem{
+				Kind:         "campaign_created",
+				CampaignID:   newCampaign.ID,
+				CampaignName: newCampaign.Name,
+				CampaignSlug: newCampaign.Slug,
+				Message:      "A creator you follow launched " + newCampaign.Name,
+				CreatedAt:    newCampaign.CreatedAt,
+			})
+		}
+	}
+
+	sort.SliceStable(feed, func(i, j int) bool {
+		return feed[i].CreatedAt.After(feed[j].CreatedAt)
+	})
+
+	if len(feed) > feedLimit {
+		feed = feed[:feedLimit]
+	}
+
+	return feed, nil
+}
+
+func (s *service) FindFollowerIDs(kind string, targetID int) ([]int, error) {
+	return s.repository.FindFollowerIDs(kind, targetID)
+}
+
+func (s *service) CountFollowers(kind string, targetID int) (int, error) {
+	count, err := s.repository.CountFollowByTarget(kind, targetID)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
+
+func (s *service) findFollowedIDs(userID int) ([]int, []int, error) {
+	campaignIDs := []int{}
+	creatorIDs := []int{}
+
+	followList, err := s.repository.FindFollowByUserID(userID)
+	if err != nil {
+		return campaignIDs, creatorIDs, err
+	}
+
+	for _, follow := range followList {
+		switch follow.Kind {
+		case KindCampaign:
+			campaignIDs = append(campaignIDs, follow.TargetID)
+		case KindCreator:
+			creatorIDs = append(creatorIDs, follow.TargetID)
+		}
+	}
+
+	return campaignIDs, creatorIDs, nil
+}
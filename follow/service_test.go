@@ -0,0 +1,83 @@
+package follow
+
+import (
+	"rocketship/campaign"
+	"rocketship/user"
+	"testing"
+	"time"
+)
+
+type fakeRepository struct {
+	Repository
+	follows []Follow
+}
+
+func (repo *fakeRepository) FindFollowByUserID(userID int) ([]Follow, error) {
+	return repo.follows, nil
+}
+
+// fakeCampaignRepository answers the feed queries, every other method comes from the nil interface
+type fakeCampaignRepository struct {
+	campaign.Repository
+	campaigns       []campaign.Campaign
+	campaignUpdates []campaign.CampaignUpdate
+	milestoneEvents []campaign.MilestoneEvent
+}
+
+func (repo *fakeCampaignRepository) FindCampaignByIDs(campaignIDs []int) ([]campaign.Campaign, error) {
+	return repo.campaigns, nil
+}
+
+func (repo *fakeCampaignRepository) FindCampaignByUserIDs(userIDs []int) ([]campaign.Campaign, error) {
+	return []campaign.Campaign{}, nil
+}
+
+func (repo *fakeCampaignRepository) FindCampaignUpdateByCampaignIDs(campaignIDs []int, limit int) ([]campaign.CampaignUpdate, error) {
+	return repo.campaignUpdates, nil
+}
+
+func (repo *fakeCampaignRepository) FindMilestoneEventByCampaignIDs(campaignIDs []int, limit int) ([]campaign.MilestoneEvent, error) {
+	return repo.milestoneEvents, nil
+}
+
+func TestFindFeedIncludesCampaignUpdates(t *testing.T) {
+	now := time.Date(2024, 3, 9, 12, 0, 0, 0, time.UTC)
+
+	campaignRepository := &fakeCampaignRepository{
+		campaigns: []campaign.Campaign{{ID: 7, Name: "Clean water", Slug: "clean-water-1"}},
+		campaignUpdates: []campaign.CampaignUpdate{
+			{ID: 1, CampaignID: 7, Title: "Wells are dug", CreatedAt: now},
+		},
+		milestoneEvents: []campaign.MilestoneEvent{
+			{CampaignID: 7, Kind: campaign.MilestoneGoal, Percent: 50, CreatedAt: now.Add(-time.Hour)},
+		},
+	}
+	repository := &fakeRepository{follows: []Follow{{UserID: 3, Kind: KindCampaign, TargetID: 7}}}
+
+	service := NewService(repository, campaignRepository, nil)
+
+	feed, err := service.FindFeed(user.User{ID: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(feed) != 2 {
+		t.Fatalf("FindFeed() returned %d items, expected 2", len(feed))
+	}
+
+	expected := FeedItem{
+		Kind:         "update",
+		CampaignID:   7,
+		CampaignName: "Clean water",
+		CampaignSlug: "clean-water-1",
+		Message:      "Clean water posted an update: Wells are dug",
+		CreatedAt:    now,
+	}
+	if feed[0] != expected {
+		t.Errorf("FindFeed()[0] = %+v, expected %+v", feed[0], expected)
+	}
+
+	if feed[1].Kind != "milestone" {
+		t.Errorf("FindFeed()[1].Kind = %q, expected %q", feed[1].Kind, "milestone")
+	}
+}
@@ -4,7 +4,12 @@ import (
 	"fmt"
 	"net/http"
 	"rocketship/campaign"
+	"rocketship/follow"
 	"rocketship/helper"
+	"rocketship/imaging"
+	"rocketship/matching"
+	"rocketship/money"
+	"rocketship/storage"
 	"rocketship/user"
 	"strconv"
 
@@ -12,11 +17,16 @@ import (
 )
 
 type campaignHandler struct {
-	service campaign.Service
+	service         campaign.Service
+	matchingService matching.Service
+	followService   follow.Service
+	storageService  storage.Service
+	variantWorker   imaging.Worker
+	rateSource      money.RateSource
 }
 
-func NewCampaignHandler(service campaign.Service) *campaignHandler {
-	return &campaignHandler{service}
+func NewCampaignHandler(service campaign.Service, matchingService matching.Service, followService follow.Service, storageService storage.Service, variantWorker imaging.Worker, rateSource money.RateSource) *campaignHandler {
+	return &campaignHandler{service, matchingService, followService, storageService, variantWorker, rateSource}
 }
 
 func (handler *campaignHandler) FindCampaigns(context *gin.Context) {
@@ -34,11 +44,33 @@ func (handler *campaignHandler) FindCampaigns(context *gin.Context) {
 		return
 	}
 
+	campaignFormatterList := campaign.FormatCampaigns(campaigns, handler.storageService)
+
+	//An optional currency adds converted amounts next to the original ones
+	currency := context.Query("currency")
+	if currency != "" {
+		for i, campaignByID := range campaigns {
+			display, err := campaign.FormatCampaignDisplay(campaignByID, currency, handler.rateSource)
+			if err != nil {
+				response := helper.APIResponse(
+					"Failed to convert campaign amounts",
+					http.StatusUnprocessableEntity,
+					"failed",
+					err.Error(),
+				)
+				context.JSON(http.StatusUnprocessableEntity, response)
+				return
+			}
+
+			campaignFormatterList[i].Display = &display
+		}
+	}
+
 	response := helper.APIResponse(
 		"Campaigns fetched!",
 		http.StatusOK,
 		"success",
-		campaign.FormatCampaigns(campaigns),
+		campaignFormatterList,
 	)
 
 	context.JSON(http.StatusOK, response)
@@ -71,11 +103,67 @@ func (handler *campaignHandler) FindCampaign(context *gin.Context) {
 		return
 	}
 
+	campaignFormatter := campaign.FormatCampaignDetail(campaignByID, handler.storageService)
+
+	fundList, err := handler.matchingService.FindActiveFunds(campaignByID)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to get campaign with that ID due to server error",
+			http.StatusBadRequest,
+			"error",
+			err,
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+	campaignFormatter.MatchedBy = matching.FormatSponsorNames(fundList)
+
+	campaignFormatter.FollowerCount, err = handler.followService.CountFollowers(follow.KindCampaign, campaignByID.ID)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to get campaign with that ID due to server error",
+			http.StatusBadRequest,
+			"error",
+			err,
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	campaignFormatter.User.FollowerCount, err = handler.followService.CountFollowers(follow.KindCreator, campaignByID.UserID)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to get campaign with that ID due to server error",
+			http.StatusBadRequest,
+			"error",
+			err,
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	currency := context.Query("currency")
+	if currency != "" {
+		display, err := campaign.FormatCampaignDisplay(campaignByID, currency, handler.rateSource)
+		if err != nil {
+			response := helper.APIResponse(
+				"Failed to convert campaign amounts",
+				http.StatusUnprocessableEntity,
+				"failed",
+				err.Error(),
+			)
+			context.JSON(http.StatusUnprocessableEntity, response)
+			return
+		}
+
+		campaignFormatter.Display = &display
+	}
+
 	response := helper.APIResponse(
 		"Campaign fetched",
 		http.StatusOK,
 		"success",
-		campaign.FormatCampaignDetail(campaignByID),
+		campaignFormatter,
 	)
 	context.JSON(http.StatusOK, response)
 }
@@ -114,7 +202,7 @@ func (handler *campaignHandler) CreateCampaign(context *gin.Context) {
 		"Campaign successfully created!",
 		http.StatusOK,
 		"success",
-		campaign.FormatCampaign(newCampaign),
+		campaign.FormatCampaign(newCampaign, handler.storageService),
 	)
 	context.JSON(http.StatusOK, response)
 }
@@ -166,7 +254,7 @@ func (handler *campaignHandler) UpdateCampaign(context *gin.Context) {
 		"Campaign successfully updated!",
 		http.StatusOK,
 		"success",
-		campaign.FormatCampaign(updatedCampaign),
+		campaign.FormatCampaign(updatedCampaign, handler.storageService),
 	)
 	context.JSON(http.StatusOK, response)
 }
@@ -174,7 +262,6 @@ func (handler *campaignHandler) UpdateCampaign(context *gin.Context) {
 func (handler *campaignHandler) UploadCampaignImage(context *gin.Context) {
 	var input campaign.CreateCampaignImageInput
 	currentUser := context.MustGet("currentUser").(user.User)
-	userID := currentUser.ID
 
 	err := context.ShouldBind(&input)
 	if err != nil {
@@ -211,48 +298,698 @@ func (handler *campaignHandler) UploadCampaignImage(context *gin.Context) {
 
 	input.User = currentUser
 
-	path := fmt.Sprintf("images/%d-%s", userID, file.Filename)
-	err = context.SaveUploadedFile(file, path)
+	key, err := saveUpload(handler.storageService, file, fmt.Sprintf("campaign-images/%d", input.CampaignID), campaignImageMaxSize)
+	if err != nil {
+		uploadFailed(context, "file", "Failed to save campaign image", err)
+		return
+	}
+
+	_, err = handler.service.CreateCampaignImage(input, key)
+	if err != nil {
+		//The same content may already be in the gallery under this key
+		inUse, inUseErr := handler.service.IsCampaignImageFileInUse(key)
+		if inUseErr == nil && !inUse {
+			handler.storageService.Delete(key)
+		}
+
+		response := helper.APIResponse(
+			"Failed to upload campaign image due to server error",
+			http.StatusBadRequest,
+			"error",
+			err.Error(),
+		)
+
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	handler.variantWorker.Notify()
+
+	data := gin.H{"is_uploaded": true}
+	response := helper.APIResponse(
+		"Campaign image uploaded",
+		http.StatusOK,
+		"success",
+		data,
+	)
+
+	context.JSON(http.StatusOK, response)
+}
+
+func (handler *campaignHandler) DeleteCampaignImage(context *gin.Context) {
+	var input campaign.CampaignImageDetailInput
+
+	err := context.ShouldBindUri(&input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to delete campaign image with that ID",
+			http.StatusUnprocessableEntity,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	currentUser := context.MustGet("currentUser").(user.User)
+
+	deletedImage, err := handler.service.DeleteCampaignImage(input, currentUser)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to delete campaign image",
+			http.StatusBadRequest,
+			"failed",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	//Files are only removed once no image row points at them anymore
+	inUse, err := handler.service.IsCampaignImageFileInUse(deletedImage.FileName)
+	if err == nil && !inUse {
+		deleteUpload(handler.storageService, deletedImage.FileName)
+	}
+
+	response := helper.APIResponse(
+		"Campaign image deleted",
+		http.StatusOK,
+		"success",
+		campaign.FormatCampaignImage(deletedImage, handler.storageService),
+	)
+	context.JSON(http.StatusOK, response)
+}
+
+func (handler *campaignHandler) MarkCampaignImageAsPrimary(context *gin.Context) {
+	var input campaign.CampaignImageDetailInput
+
+	err := context.ShouldBindUri(&input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to update campaign image with that ID",
+			http.StatusUnprocessableEntity,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	currentUser := context.MustGet("currentUser").(user.User)
+
+	updatedImage, err := handler.service.MarkCampaignImageAsPrimary(input, currentUser)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to update campaign image",
+			http.StatusBadRequest,
+			"failed",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"Campaign image set as primary",
+		http.StatusOK,
+		"success",
+		campaign.FormatCampaignImage(updatedImage, handler.storageService),
+	)
+	context.JSON(http.StatusOK, response)
+}
+
+func (handler *campaignHandler) ReorderCampaignImages(context *gin.Context) {
+	var inputID campaign.CampaignDetailInput
+	var input campaign.ReorderCampaignImagesInput
+
+	err := context.ShouldBindUri(&inputID)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to reorder images of campaign with that ID",
+			http.StatusUnprocessableEntity,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	err = context.ShouldBindJSON(&input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to reorder campaign images due to bad inputs",
+			http.StatusUnprocessableEntity,
+			"failed",
+			err.Error(),
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	input.User = context.MustGet("currentUser").(user.User)
+
+	imageList, err := handler.service.ReorderCampaignImages(inputID, input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to reorder campaign images",
+			http.StatusBadRequest,
+			"failed",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"Campaign images reordered",
+		http.StatusOK,
+		"success",
+		campaign.FormatCampaignImageList(imageList, handler.storageService),
+	)
+	context.JSON(http.StatusOK, response)
+}
 
+func (handler *campaignHandler) UploadCampaignVideo(context *gin.Context) {
+	var input campaign.CreateCampaignVideoInput
+
+	err := context.ShouldBind(&input)
 	if err != nil {
 		errors := helper.FormatError(err)
 		errorMessage := gin.H{"errors": errors}
 
-		data := gin.H{
-			"errors": errorMessage,
+		response := helper.APIResponse(
+			"Failed to upload campaign video due to bad input",
+			http.StatusUnprocessableEntity,
+			"error",
+			errorMessage,
+		)
+
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	file, err := context.FormFile("file")
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to upload campaign video due to bad input",
+			http.StatusUnprocessableEntity,
+			"error",
+			gin.H{"is_uploaded": false},
+		)
+
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	input.User = context.MustGet("currentUser").(user.User)
+
+	key, contentType, err := saveVideoUpload(handler.storageService, file, fmt.Sprintf("campaign-videos/%d", input.CampaignID), campaignVideoMaxSize)
+	if err != nil {
+		uploadFailed(context, "file", "Failed to save campaign video", err)
+		return
+	}
+
+	newMedia, err := handler.service.CreateCampaignVideo(input, key, contentType)
+	if err != nil {
+		inUse, inUseErr := handler.service.IsCampaignMediaFileInUse(key)
+		if inUseErr == nil && !inUse {
+			handler.storageService.Delete(key)
 		}
 
 		response := helper.APIResponse(
-			"Failed to save campaign image due to server error",
+			"Failed to upload campaign video due to server error",
 			http.StatusBadRequest,
 			"error",
-			data,
+			err.Error(),
 		)
 
 		context.JSON(http.StatusBadRequest, response)
 		return
 	}
 
-	_, err = handler.service.CreateCampaignImage(input, path)
+	response := helper.APIResponse(
+		"Campaign video uploaded",
+		http.StatusOK,
+		"success",
+		campaign.FormatCampaignMedia(newMedia, handler.storageService),
+	)
+
+	context.JSON(http.StatusOK, response)
+}
+
+func (handler *campaignHandler) CreateCampaignEmbed(context *gin.Context) {
+	var input campaign.CreateCampaignEmbedInput
+
+	err := context.ShouldBindJSON(&input)
 	if err != nil {
 		response := helper.APIResponse(
-			"Failed to upload campaign image due to server error",
-			http.StatusBadRequest,
-			"error",
+			"Failed to add campaign video due to bad inputs",
+			http.StatusUnprocessableEntity,
+			"failed",
 			err.Error(),
 		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
 
+	input.User = context.MustGet("currentUser").(user.User)
+
+	newMedia, err := handler.service.CreateCampaignEmbed(input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to add campaign video",
+			http.StatusBadRequest,
+			"failed",
+			err.Error(),
+		)
 		context.JSON(http.StatusBadRequest, response)
 		return
 	}
 
-	data := gin.H{"is_uploaded": true}
 	response := helper.APIResponse(
-		"Campaign image uploaded",
+		"Campaign video added",
 		http.StatusOK,
 		"success",
-		data,
+		campaign.FormatCampaignMedia(newMedia, handler.storageService),
 	)
+	context.JSON(http.StatusOK, response)
+}
+
+func (handler *campaignHandler) DeleteCampaignMedia(context *gin.Context) {
+	var input campaign.CampaignMediaDetailInput
+
+	err := context.ShouldBindUri(&input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to delete campaign media with that ID",
+			http.StatusUnprocessableEntity,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	currentUser := context.MustGet("currentUser").(user.User)
 
+	deletedMedia, err := handler.service.DeleteCampaignMedia(input, currentUser)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to delete campaign media",
+			http.StatusBadRequest,
+			"failed",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if deletedMedia.FileName != "" {
+		inUse, err := handler.service.IsCampaignMediaFileInUse(deletedMedia.FileName)
+		if err == nil && !inUse {
+			handler.storageService.Delete(deletedMedia.FileName)
+		}
+	}
+
+	response := helper.APIResponse(
+		"Campaign media deleted",
+		http.StatusOK,
+		"success",
+		campaign.FormatCampaignMedia(deletedMedia, handler.storageService),
+	)
+	context.JSON(http.StatusOK, response)
+}
+
+func (handler *campaignHandler) PreviewDescription(context *gin.Context) {
+	var input campaign.PreviewDescriptionInput
+
+	err := context.ShouldBindJSON(&input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to preview description due to bad inputs",
+			http.StatusUnprocessableEntity,
+			"failed",
+			err.Error(),
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"Description preview rendered",
+		http.StatusOK,
+		"success",
+		campaign.FormatDescriptionPreview(input.Description, handler.storageService),
+	)
+	context.JSON(http.StatusOK, response)
+}
+
+func (handler *campaignHandler) FindCollaborators(context *gin.Context) {
+	var input campaign.CampaignDetailInput
+
+	err := context.ShouldBindUri(&input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to get collaborators of campaign with that ID",
+			http.StatusUnprocessableEntity,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	currentUser := context.MustGet("currentUser").(user.User)
+
+	collaboratorList, err := handler.service.FindCollaborators(input, currentUser)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to get collaborators",
+			http.StatusBadRequest,
+			"failed",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"Collaborators fetched",
+		http.StatusOK,
+		"success",
+		campaign.FormatCollaboratorList(collaboratorList),
+	)
+	context.JSON(http.StatusOK, response)
+}
+
+func (handler *campaignHandler) InviteCollaborator(context *gin.Context) {
+	var inputID campaign.CampaignDetailInput
+	var input campaign.InviteCollaboratorInput
+
+	err := context.ShouldBindUri(&inputID)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to invite collaborator to campaign with that ID",
+			http.StatusUnprocessableEntity,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	err = context.ShouldBindJSON(&input)
+	if err != nil {
+		errors := helper.FormatError(err)
+		errorMessage := gin.H{"errors": errors}
+
+		response := helper.APIResponse(
+			"Failed to invite collaborator due to bad inputs",
+			http.StatusUnprocessableEntity,
+			"failed",
+			errorMessage,
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	input.User = context.MustGet("currentUser").(user.User)
+
+	collaborator, err := handler.service.InviteCollaborator(inputID, input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to invite collaborator",
+			http.StatusBadRequest,
+			"failed",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"Collaborator invited",
+		http.StatusOK,
+		"success",
+		campaign.FormatCollaborator(collaborator),
+	)
+	context.JSON(http.StatusOK, response)
+}
+
+func (handler *campaignHandler) CreateStretchGoal(context *gin.Context) {
+	var inputID campaign.CampaignDetailInput
+	var input campaign.CreateStretchGoalInput
+
+	err := context.ShouldBindUri(&inputID)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to add stretch goal to campaign with that ID",
+			http.StatusUnprocessableEntity,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	err = context.ShouldBindJSON(&input)
+	if err != nil {
+		errors := helper.FormatError(err)
+		errorMessage := gin.H{"errors": errors}
+
+		response := helper.APIResponse(
+			"Failed to add stretch goal due to bad inputs",
+			http.StatusUnprocessableEntity,
+			"failed",
+			errorMessage,
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	input.User = context.MustGet("currentUser").(user.User)
+
+	stretchGoal, err := handler.service.CreateStretchGoal(inputID, input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to add stretch goal",
+			http.StatusBadRequest,
+			"failed",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"Stretch goal added",
+		http.StatusOK,
+		"success",
+		campaign.FormatStretchGoal(stretchGoal, []campaign.MilestoneEvent{}),
+	)
+	context.JSON(http.StatusOK, response)
+}
+
+func (handler *campaignHandler) DeleteStretchGoal(context *gin.Context) {
+	var input campaign.StretchGoalDetailInput
+
+	err := context.ShouldBindUri(&input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to delete stretch goal with that ID",
+			http.StatusUnprocessableEntity,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	currentUser := context.MustGet("currentUser").(user.User)
+
+	stretchGoal, err := handler.service.DeleteStretchGoal(input, currentUser)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to delete stretch goal",
+			http.StatusBadRequest,
+			"failed",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"Stretch goal deleted",
+		http.StatusOK,
+		"success",
+		campaign.FormatStretchGoal(stretchGoal, []campaign.MilestoneEvent{}),
+	)
+	context.JSON(http.StatusOK, response)
+}
+
+func (handler *campaignHandler) AcceptInvitation(context *gin.Context) {
+	var input campaign.AcceptInvitationInput
+
+	err := context.ShouldBindJSON(&input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to accept invitation due to bad inputs",
+			http.StatusUnprocessableEntity,
+			"failed",
+			err.Error(),
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	input.User = context.MustGet("currentUser").(user.User)
+
+	collaborator, err := handler.service.AcceptInvitation(input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to accept invitation",
+			http.StatusBadRequest,
+			"failed",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"Invitation accepted",
+		http.StatusOK,
+		"success",
+		campaign.FormatCollaborator(collaborator),
+	)
+	context.JSON(http.StatusOK, response)
+}
+
+func (handler *campaignHandler) RemoveCollaborator(context *gin.Context) {
+	var input campaign.CollaboratorDetailInput
+
+	err := context.ShouldBindUri(&input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to remove collaborator with that ID",
+			http.StatusUnprocessableEntity,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	currentUser := context.MustGet("currentUser").(user.User)
+
+	collaborator, err := handler.service.RemoveCollaborator(input, currentUser)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to remove collaborator",
+			http.StatusBadRequest,
+			"failed",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"Collaborator removed",
+		http.StatusOK,
+		"success",
+		campaign.FormatCollaborator(collaborator),
+	)
+	context.JSON(http.StatusOK, response)
+}
+
+func (handler *campaignHandler) FindCampaignUpdates(context *gin.Context) {
+	var input campaign.CampaignDetailInput
+
+	err := context.ShouldBindUri(&input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to get updates of campaign with that ID",
+			http.StatusUnprocessableEntity,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	campaignUpdateList, err := handler.service.FindCampaignUpdates(input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to get campaign updates",
+			http.StatusBadRequest,
+			"failed",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"Campaign updates fetched",
+		http.StatusOK,
+		"success",
+		campaign.FormatCampaignUpdateList(campaignUpdateList, handler.storageService),
+	)
+	context.JSON(http.StatusOK, response)
+}
+
+func (handler *campaignHandler) CreateCampaignUpdate(context *gin.Context) {
+	var inputID campaign.CampaignDetailInput
+	var input campaign.CreateCampaignUpdateInput
+
+	err := context.ShouldBindUri(&inputID)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to post update to campaign with that ID",
+			http.StatusUnprocessableEntity,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	err = context.ShouldBindJSON(&input)
+	if err != nil {
+		errors := helper.FormatError(err)
+		errorMessage := gin.H{"errors": errors}
+
+		response := helper.APIResponse(
+			"Failed to post campaign update due to bad inputs",
+			http.StatusUnprocessableEntity,
+			"failed",
+			errorMessage,
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	input.User = context.MustGet("currentUser").(user.User)
+
+	campaignUpdate, err := handler.service.CreateCampaignUpdate(inputID, input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to post campaign update",
+			http.StatusBadRequest,
+			"failed",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"Campaign update posted",
+		http.StatusOK,
+		"success",
+		campaign.FormatCampaignUpdate(campaignUpdate, handler.storageService),
+	)
 	context.JSON(http.StatusOK, response)
 }
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"net/http"
+	"rocketship/fee"
+	"rocketship/helper"
+	"rocketship/user"
+
+	"github.com/gin-gonic/gin"
+)
+
+type feeHandler struct {
+	service fee.Service
+}
+
+func NewFeeHandler(service fee.Service) *feeHandler {
+	return &feeHandler{service}
+}
+
+func (handler *feeHandler) FindRules(context *gin.Context) {
+	currentUser := context.MustGet("currentUser").(user.User)
+
+	ruleList, err := handler.service.FindRules(currentUser)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to get fee rules due to server error",
+			http.StatusBadRequest,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"Fee rules fetched",
+		http.StatusOK,
+		"success",
+		fee.FormatRuleList(ruleList),
+	)
+	context.JSON(http.StatusOK, response)
+}
+
+func (handler *feeHandler) CreateRule(context *gin.Context) {
+	var input fee.CreateRuleInput
+
+	err := context.ShouldBindJSON(&input)
+	if err != nil {
+		errors := helper.FormatError(err)
+		errorMessage := gin.H{"errors": errors}
+
+		response := helper.APIResponse(
+			"Failed to create fee rule due to bad inputs",
+			http.StatusUnprocessableEntity,
+			"failed",
+			errorMessage,
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	currentUser := context.MustGet("currentUser").(user.User)
+	input.User = currentUser
+
+	newRule, err := handler.service.CreateRule(input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to create fee rule due to server error",
+			http.StatusBadRequest,
+			"failed",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"Fee rule successfully created!",
+		http.StatusOK,
+		"success",
+		fee.FormatRule(newRule),
+	)
+	context.JSON(http.StatusOK, response)
+}
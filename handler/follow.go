@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"net/http"
+	"rocketship/follow"
+	"rocketship/helper"
+	"rocketship/storage"
+	"rocketship/user"
+
+	"github.com/gin-gonic/gin"
+)
+
+type followHandler struct {
+	service        follow.Service
+	storageService storage.Service
+}
+
+func NewFollowHandler(service follow.Service, storageService storage.Service) *followHandler {
+	return &followHandler{service, storageService}
+}
+
+func (handler *followHandler) FollowCampaign(context *gin.Context) {
+	handler.follow(context, handler.service.FollowCampaign, "campaign")
+}
+
+func (handler *followHandler) UnfollowCampaign(context *gin.Context) {
+	handler.unfollow(context, follow.KindCampaign, handler.service.UnfollowCampaign, "campaign")
+}
+
+func (handler *followHandler) FollowCreator(context *gin.Context) {
+	handler.follow(context, handler.service.FollowCreator, "creator")
+}
+
+func (handler *followHandler) UnfollowCreator(context *gin.Context) {
+	handler.unfollow(context, follow.KindCreator, handler.service.UnfollowCreator, "creator")
+}
+
+func (handler *followHandler) FindFollowing(context *gin.Context) {
+	currentUser := context.MustGet("currentUser").(user.User)
+
+	following, err := handler.service.FindFollowing(currentUser)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to get followed campaigns and creators",
+			http.StatusBadRequest,
+			"failed",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"Followed campaigns and creators fetched",
+		http.StatusOK,
+		"success",
+		follow.FormatFollowing(following, handler.storageService),
+	)
+	context.JSON(http.StatusOK, response)
+}
+
+func (handler *followHandler) FindFeed(context *gin.Context) {
+	currentUser := context.MustGet("currentUser").(user.User)
+
+	feed, err := handler.service.FindFeed(currentUser)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to get feed",
+			http.StatusBadRequest,
+			"failed",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"Feed fetched",
+		http.StatusOK,
+		"success",
+		follow.FormatFeed(feed),
+	)
+	context.JSON(http.StatusOK, response)
+}
+
+func (handler *followHandler) follow(context *gin.Context, save func(follow.FollowInput) (follow.Follow, error), target string) {
+	var input follow.FollowInput
+
+	err := context.ShouldBindUri(&input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to follow "+target+" with that ID",
+			http.StatusUnprocessableEntity,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	input.User = context.MustGet("currentUser").(user.User)
+
+	newFollow, err := save(input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to follow "+target,
+			http.StatusBadRequest,
+			"failed",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"Now following this "+target,
+		http.StatusOK,
+		"success",
+		follow.FormatFollow(newFollow),
+	)
+	context.JSON(http.StatusOK, response)
+}
+
+func (handler *followHandler) unfollow(context *gin.Context, kind string, remove func(follow.FollowInput) error, target string) {
+	var input follow.FollowInput
+
+	err := context.ShouldBindUri(&input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to unfollow "+target+" with that ID",
+			http.StatusUnprocessableEntity,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	input.User = context.MustGet("currentUser").(user.User)
+
+	err = remove(input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to unfollow "+target,
+			http.StatusBadRequest,
+			"failed",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"No longer following this "+target,
+		http.StatusOK,
+		"success",
+		follow.FollowFormatter{Kind: kind, TargetID: input.ID, IsFollowed: false},
+	)
+	context.JSON(http.StatusOK, response)
+}
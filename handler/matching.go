@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"net/http"
+	"rocketship/helper"
+	"rocketship/matching"
+	"rocketship/user"
+
+	"github.com/gin-gonic/gin"
+)
+
+type matchingHandler struct {
+	service matching.Service
+}
+
+func NewMatchingHandler(service matching.Service) *matchingHandler {
+	return &matchingHandler{service}
+}
+
+func (handler *matchingHandler) FindFunds(context *gin.Context) {
+	currentUser := context.MustGet("currentUser").(user.User)
+
+	fundList, err := handler.service.FindFunds(currentUser)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to get matching funds due to server error",
+			http.StatusBadRequest,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"Matching funds fetched",
+		http.StatusOK,
+		"success",
+		matching.FormatFundList(fundList),
+	)
+	context.JSON(http.StatusOK, response)
+}
+
+func (handler *matchingHandler) CreateFund(context *gin.Context) {
+	var input matching.CreateFundInput
+
+	err := context.ShouldBindJSON(&input)
+	if err != nil {
+		errors := helper.FormatError(err)
+		errorMessage := gin.H{"errors": errors}
+
+		response := helper.APIResponse(
+			"Failed to create matching fund due to bad inputs",
+			http.StatusUnprocessableEntity,
+			"failed",
+			errorMessage,
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	currentUser := context.MustGet("currentUser").(user.User)
+	input.User = currentUser
+
+	newFund, err := handler.service.CreateFund(input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to create matching fund due to server error",
+			http.StatusBadRequest,
+			"failed",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"Matching fund successfully created!",
+		http.StatusOK,
+		"success",
+		matching.FormatFund(newFund),
+	)
+	context.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,306 @@
+package handler
+
+import (
+	"net/http"
+	"rocketship/helper"
+	"rocketship/payout"
+	"rocketship/user"
+
+	"github.com/gin-gonic/gin"
+)
+
+type payoutHandler struct {
+	service payout.Service
+}
+
+func NewPayoutHandler(service payout.Service) *payoutHandler {
+	return &payoutHandler{service}
+}
+
+func (handler *payoutHandler) FindBankAccount(context *gin.Context) {
+	currentUser := context.MustGet("currentUser").(user.User)
+
+	bankAccount, err := handler.service.FindBankAccount(currentUser.ID)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to get bank account due to server error",
+			http.StatusBadRequest,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"Bank account fetched",
+		http.StatusOK,
+		"success",
+		payout.FormatBankAccount(bankAccount),
+	)
+	context.JSON(http.StatusOK, response)
+}
+
+func (handler *payoutHandler) SaveBankAccount(context *gin.Context) {
+	var input payout.SaveBankAccountInput
+
+	err := context.ShouldBindJSON(&input)
+	if err != nil {
+		errors := helper.FormatError(err)
+		errorMessage := gin.H{"errors": errors}
+
+		response := helper.APIResponse(
+			"Failed to save bank account due to bad inputs",
+			http.StatusUnprocessableEntity,
+			"failed",
+			errorMessage,
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	currentUser := context.MustGet("currentUser").(user.User)
+	input.User = currentUser
+
+	bankAccount, err := handler.service.SaveBankAccount(input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to save bank account due to server error",
+			http.StatusBadRequest,
+			"failed",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"Bank account successfully saved!",
+		http.StatusOK,
+		"success",
+		payout.FormatBankAccount(bankAccount),
+	)
+	context.JSON(http.StatusOK, response)
+}
+
+func (handler *payoutHandler) FindBalance(context *gin.Context) {
+	var input payout.CampaignPayoutInput
+
+	err := context.ShouldBindUri(&input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to get balance with this Campaign ID",
+			http.StatusUnprocessableEntity,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	currentUser := context.MustGet("currentUser").(user.User)
+	input.User = currentUser
+
+	balance, err := handler.service.FindBalance(input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to get balance due to server error",
+			http.StatusBadRequest,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"Balance fetched",
+		http.StatusOK,
+		"success",
+		payout.FormatBalance(balance),
+	)
+	context.JSON(http.StatusOK, response)
+}
+
+func (handler *payoutHandler) FindPayoutByCampaignID(context *gin.Context) {
+	var input payout.CampaignPayoutInput
+
+	err := context.ShouldBindUri(&input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to get payouts with this Campaign ID",
+			http.StatusUnprocessableEntity,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	currentUser := context.MustGet("currentUser").(user.User)
+	input.User = currentUser
+
+	payoutList, err := handler.service.FindPayoutByCampaignID(input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to get payouts due to server error",
+			http.StatusBadRequest,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"Payouts fetched",
+		http.StatusOK,
+		"success",
+		payout.FormatPayoutList(payoutList),
+	)
+	context.JSON(http.StatusOK, response)
+}
+
+func (handler *payoutHandler) RequestPayout(context *gin.Context) {
+	var campaignInput payout.CampaignPayoutInput
+	var input payout.RequestPayoutInput
+
+	err := context.ShouldBindUri(&campaignInput)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to request payout with this Campaign ID",
+			http.StatusUnprocessableEntity,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	err = context.ShouldBindJSON(&input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to request payout due to bad inputs",
+			http.StatusUnprocessableEntity,
+			"failed",
+			err.Error(),
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	currentUser := context.MustGet("currentUser").(user.User)
+	campaignInput.User = currentUser
+	input.User = currentUser
+
+	newPayout, err := handler.service.RequestPayout(campaignInput, input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to request payout due to server error",
+			http.StatusBadRequest,
+			"failed",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"Payout successfully requested!",
+		http.StatusOK,
+		"success",
+		payout.FormatPayout(newPayout),
+	)
+	context.JSON(http.StatusOK, response)
+}
+
+func (handler *payoutHandler) FindPayouts(context *gin.Context) {
+	currentUser := context.MustGet("currentUser").(user.User)
+
+	payoutList, err := handler.service.FindPayoutByStatus(context.Query("status"), currentUser)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to get payouts due to server error",
+			http.StatusBadRequest,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"Payouts fetched",
+		http.StatusOK,
+		"success",
+		payout.FormatPayoutList(payoutList),
+	)
+	context.JSON(http.StatusOK, response)
+}
+
+func (handler *payoutHandler) ApprovePayout(context *gin.Context) {
+	handler.reviewPayout(context, handler.service.ApprovePayout, "approved")
+}
+
+func (handler *payoutHandler) RejectPayout(context *gin.Context) {
+	handler.reviewPayout(context, handler.service.RejectPayout, "rejected")
+}
+
+func (handler *payoutHandler) CompletePayout(context *gin.Context) {
+	handler.reviewPayout(context, handler.service.CompletePayout, "marked as paid")
+}
+
+func (handler *payoutHandler) reviewPayout(context *gin.Context, review func(payoutID payout.PayoutDetailInput, input payout.ReviewPayoutInput) (payout.Payout, error), action string) {
+	var payoutID payout.PayoutDetailInput
+	var input payout.ReviewPayoutInput
+
+	err := context.ShouldBindUri(&payoutID)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to review payout with that ID",
+			http.StatusUnprocessableEntity,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	//The review note is optional, so an empty body is accepted
+	if context.Request.ContentLength > 0 {
+		err = context.ShouldBindJSON(&input)
+		if err != nil {
+			response := helper.APIResponse(
+				"Failed to review payout due to bad inputs",
+				http.StatusUnprocessableEntity,
+				"failed",
+				err.Error(),
+			)
+			context.JSON(http.StatusUnprocessableEntity, response)
+			return
+		}
+	}
+
+	currentUser := context.MustGet("currentUser").(user.User)
+	input.User = currentUser
+
+	reviewedPayout, err := review(payoutID, input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to review payout due to server error",
+			http.StatusBadRequest,
+			"failed",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"Payout successfully "+action+"!",
+		http.StatusOK,
+		"success",
+		payout.FormatPayout(reviewedPayout),
+	)
+	context.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"net/http"
+	"rocketship/helper"
+	"rocketship/profile"
+	"rocketship/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+type profileHandler struct {
+	service        profile.Service
+	storageService storage.Service
+}
+
+func NewProfileHandler(service profile.Service, storageService storage.Service) *profileHandler {
+	return &profileHandler{service, storageService}
+}
+
+func (handler *profileHandler) FindProfile(context *gin.Context) {
+	var input profile.ProfileInput
+
+	err := context.ShouldBindUri(&input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to get profile of user with that ID",
+			http.StatusUnprocessableEntity,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	profileByID, err := handler.service.FindProfile(input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to get profile",
+			http.StatusBadRequest,
+			"failed",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"Profile fetched",
+		http.StatusOK,
+		"success",
+		profile.FormatProfile(profileByID, handler.storageService),
+	)
+	context.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"net/http"
+	"rocketship/helper"
+	"rocketship/subscription"
+	"rocketship/user"
+
+	"github.com/gin-gonic/gin"
+)
+
+type subscriptionHandler struct {
+	service subscription.Service
+}
+
+func NewSubscriptionHandler(service subscription.Service) *subscriptionHandler {
+	return &subscriptionHandler{service}
+}
+
+func (handler *subscriptionHandler) CreateSubscription(context *gin.Context) {
+	var input subscription.CreateSubscriptionInput
+
+	err := context.ShouldBindJSON(&input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to create subscription due to bad inputs",
+			http.StatusUnprocessableEntity,
+			"failed",
+			err.Error(),
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	currentUser := context.MustGet("currentUser").(user.User)
+	input.User = currentUser
+
+	newSubscription, firstTransaction, err := handler.service.CreateSubscription(input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to create subscription due to server error",
+			http.StatusBadRequest,
+			"failed",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"Subscription successfully created!",
+		http.StatusOK,
+		"success",
+		subscription.FormatSubscription(newSubscription, firstTransaction.PaymentURL),
+	)
+	context.JSON(http.StatusOK, response)
+}
+
+func (handler *subscriptionHandler) FindSubscriptions(context *gin.Context) {
+	currentUser := context.MustGet("currentUser").(user.User)
+
+	subscriptionList, err := handler.service.FindSubscriptionByUserID(currentUser.ID)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to get subscriptions due to server error",
+			http.StatusBadRequest,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"Subscriptions fetched",
+		http.StatusOK,
+		"success",
+		subscription.FormatSubscriptionList(subscriptionList),
+	)
+	context.JSON(http.StatusOK, response)
+}
+
+func (handler *subscriptionHandler) PauseSubscription(context *gin.Context) {
+	handler.changeSubscription(context, handler.service.PauseSubscription, "paused")
+}
+
+func (handler *subscriptionHandler) ResumeSubscription(context *gin.Context) {
+	handler.changeSubscription(context, handler.service.ResumeSubscription, "resumed")
+}
+
+func (handler *subscriptionHandler) CancelSubscription(context *gin.Context) {
+	handler.changeSubscription(context, handler.service.CancelSubscription, "cancelled")
+}
+
+func (handler *subscriptionHandler) FindCampaignStats(context *gin.Context) {
+	var input subscription.SubscriptionDetailInput
+
+	err := context.ShouldBindUri(&input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to get subscription stats with this Campaign ID",
+			http.StatusUnprocessableEntity,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	currentUser := context.MustGet("currentUser").(user.User)
+
+	stats, err := handler.service.FindCampaignStats(input.ID, currentUser)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to get subscription stats due to server error",
+			http.StatusBadRequest,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"Subscription stats fetched",
+		http.StatusOK,
+		"success",
+		subscription.FormatStats(stats),
+	)
+	context.JSON(http.StatusOK, response)
+}
+
+func (handler *subscriptionHandler) changeSubscription(context *gin.Context, change func(input subscription.SubscriptionDetailInput) (subscription.Subscription, error), action string) {
+	var input subscription.SubscriptionDetailInput
+
+	err := context.ShouldBindUri(&input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to update subscription with that ID",
+			http.StatusUnprocessableEntity,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	currentUser := context.MustGet("currentUser").(user.User)
+	input.User = currentUser
+
+	updatedSubscription, err := change(input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to update subscription due to server error",
+			http.StatusBadRequest,
+			"failed",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"Subscription successfully "+action+"!",
+		http.StatusOK,
+		"success",
+		subscription.FormatSubscription(updatedSubscription, ""),
+	)
+	context.JSON(http.StatusOK, response)
+}
@@ -1,11 +1,16 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
 	"rocketship/helper"
 	"rocketship/payment"
+	"rocketship/receipt"
+	"rocketship/spreadsheet"
+	"rocketship/storage"
 	"rocketship/transaction"
 	"rocketship/user"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -13,10 +18,11 @@ import (
 type transactionHandler struct {
 	service        transaction.Service
 	paymentService payment.Service
+	storageService storage.Service
 }
 
-func NewTransactionHandler(service transaction.Service, paymentService payment.Service) *transactionHandler {
-	return &transactionHandler{service, paymentService}
+func NewTransactionHandler(service transaction.Service, paymentService payment.Service, storageService storage.Service) *transactionHandler {
+	return &transactionHandler{service, paymentService, storageService}
 }
 
 func (handler *transactionHandler) FindTransactionByCampaignID(context *gin.Context) {
@@ -37,6 +43,12 @@ func (handler *transactionHandler) FindTransactionByCampaignID(context *gin.Cont
 	currentUser := context.MustGet("currentUser").(user.User)
 	input.User = currentUser
 
+	format := exportFormat(context)
+	if format != "" {
+		handler.exportTransactionByCampaignID(context, input, format)
+		return
+	}
+
 	transactionList, err := handler.service.FindTransactionByCampaignID(input)
 	if err != nil {
 		response := helper.APIResponse(
@@ -58,6 +70,149 @@ func (handler *transactionHandler) FindTransactionByCampaignID(context *gin.Cont
 	context.JSON(http.StatusOK, response)
 }
 
+const xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// exportFormat picks the export format from the format query first and the Accept header second
+func exportFormat(context *gin.Context) string {
+	format := context.Query("format")
+	if format == "csv" || format == "xlsx" {
+		return format
+	}
+
+	accept := context.GetHeader("Accept")
+	if strings.Contains(accept, "text/csv") {
+		return "csv"
+	}
+	if strings.Contains(accept, xlsxContentType) {
+		return "xlsx"
+	}
+
+	return ""
+}
+
+func (handler *transactionHandler) exportTransactionByCampaignID(context *gin.Context, campaignID transaction.FindTransactionByIDInput, format string) {
+	var input transaction.ExportTransactionInput
+
+	err := context.ShouldBindQuery(&input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to export transactions due to bad inputs",
+			http.StatusUnprocessableEntity,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	//Nothing is written until the service has checked the credentials, so errors can still be sent as JSON
+	var exportWriter spreadsheet.Writer
+	startExport := func() error {
+		fileName := fmt.Sprintf("campaign-%d-transactions.%s", campaignID.ID, format)
+		context.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", fileName))
+
+		if format == "xlsx" {
+			context.Header("Content-Type", xlsxContentType)
+			xlsxWriter, err := spreadsheet.NewXLSXWriter(context.Writer)
+			if err != nil {
+				return err
+			}
+			exportWriter = xlsxWriter
+		} else {
+			context.Header("Content-Type", "text/csv; charset=utf-8")
+			exportWriter = spreadsheet.NewCSVWriter(context.Writer)
+		}
+
+		return exportWriter.WriteRow(transaction.ExportHeader)
+	}
+
+	err = handler.service.ExportTransactionByCampaignID(campaignID, input, func(transactionList []transaction.Transaction) error {
+		if exportWriter == nil {
+			err := startExport()
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, campaignTransaction := range transactionList {
+			err := exportWriter.WriteRow(transaction.FormatExportRow(campaignTransaction))
+			if err != nil {
+				return err
+			}
+		}
+
+		err := exportWriter.Flush()
+		if err != nil {
+			return err
+		}
+		context.Writer.Flush()
+
+		return nil
+	})
+
+	if err == nil && exportWriter == nil {
+		err = startExport()
+	}
+
+	if err != nil {
+		//Once the file has started streaming the status can no longer change
+		if context.Writer.Written() {
+			context.Error(err)
+			return
+		}
+
+		response := helper.APIResponse(
+			"Failed to export transactions due to server error",
+			http.StatusBadRequest,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	err = exportWriter.Close()
+	if err != nil {
+		context.Error(err)
+	}
+}
+
+func (handler *transactionHandler) FindSupporters(context *gin.Context) {
+	var input transaction.FindTransactionByIDInput
+
+	err := context.ShouldBindUri(&input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to get supporters with this Campaign ID",
+			http.StatusUnprocessableEntity,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	transactionList, err := handler.service.FindSupporters(input.ID)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to get supporters due to server error",
+			http.StatusBadRequest,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"Supporters fetched",
+		http.StatusOK,
+		"success",
+		transaction.FormatSupporterList(transactionList),
+	)
+	context.JSON(http.StatusOK, response)
+}
+
 func (handler *transactionHandler) FindTransactionByUserID(context *gin.Context) {
 	currentUser := context.MustGet("currentUser").(user.User)
 	userID := currentUser.ID
@@ -78,7 +233,46 @@ func (handler *transactionHandler) FindTransactionByUserID(context *gin.Context)
 		"Transaction fetched",
 		http.StatusOK,
 		"success",
-		transaction.FormatUserTransactionList(transactionList),
+		transaction.FormatUserTransactionList(transactionList, handler.storageService),
+	)
+	context.JSON(http.StatusOK, response)
+}
+
+func (handler *transactionHandler) FindTransaction(context *gin.Context) {
+	var input transaction.FindTransactionDetailInput
+
+	err := context.ShouldBindUri(&input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to get transaction with this ID or code",
+			http.StatusUnprocessableEntity,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	currentUser := context.MustGet("currentUser").(user.User)
+	input.User = currentUser
+
+	transactionDetail, err := handler.service.FindTransactionDetail(input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to get transaction due to server error",
+			http.StatusBadRequest,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"Transaction fetched",
+		http.StatusOK,
+		"success",
+		transaction.FormatTransactionDetail(transactionDetail, handler.storageService),
 	)
 	context.JSON(http.StatusOK, response)
 }
@@ -122,6 +316,160 @@ func (handler *transactionHandler) CreateTransaction(context *gin.Context) {
 	context.JSON(http.StatusOK, response)
 }
 
+func (handler *transactionHandler) ResumePayment(context *gin.Context) {
+	var input transaction.FindTransactionDetailInput
+
+	err := context.ShouldBindUri(&input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to resume payment for this transaction",
+			http.StatusUnprocessableEntity,
+			"failed",
+			err.Error(),
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	currentUser := context.MustGet("currentUser").(user.User)
+	input.User = currentUser
+
+	resumedTransaction, err := handler.service.ResumePayment(input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to resume payment due to server error",
+			http.StatusBadRequest,
+			"failed",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"Payment successfully resumed!",
+		http.StatusOK,
+		"success",
+		transaction.FormatTransaction(resumedTransaction),
+	)
+	context.JSON(http.StatusOK, response)
+}
+
+func (handler *transactionHandler) ModerateMessage(context *gin.Context) {
+	var inputID transaction.FindTransactionByIDInput
+	var input transaction.ModerateMessageInput
+
+	err := context.ShouldBindUri(&inputID)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to moderate message of transaction with that ID",
+			http.StatusUnprocessableEntity,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	err = context.ShouldBindJSON(&input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to moderate message due to bad inputs",
+			http.StatusUnprocessableEntity,
+			"failed",
+			err.Error(),
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	currentUser := context.MustGet("currentUser").(user.User)
+	input.User = currentUser
+
+	moderatedTransaction, err := handler.service.ModerateMessage(inputID, input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to moderate message due to server error",
+			http.StatusBadRequest,
+			"failed",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"Message successfully moderated!",
+		http.StatusOK,
+		"success",
+		transaction.FormatCampaignTransaction(moderatedTransaction),
+	)
+	context.JSON(http.StatusOK, response)
+}
+
+func (handler *transactionHandler) GetReceipt(context *gin.Context) {
+	var input transaction.FindTransactionDetailInput
+
+	err := context.ShouldBindUri(&input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to get receipt for this transaction",
+			http.StatusUnprocessableEntity,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	currentUser := context.MustGet("currentUser").(user.User)
+	input.User = currentUser
+
+	transactionReceipt, err := handler.service.FindReceipt(input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to get receipt due to server error",
+			http.StatusBadRequest,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if context.Query("format") == "html" {
+		body, err := receipt.RenderHTML(transactionReceipt)
+		if err != nil {
+			response := helper.APIResponse(
+				"Failed to render receipt due to server error",
+				http.StatusBadRequest,
+				"error",
+				err.Error(),
+			)
+			context.JSON(http.StatusBadRequest, response)
+			return
+		}
+
+		context.Data(http.StatusOK, "text/html; charset=utf-8", []byte(body))
+		return
+	}
+
+	pdf, err := receipt.RenderPDF(transactionReceipt)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to render receipt due to server error",
+			http.StatusBadRequest,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	context.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.pdf\"", transactionReceipt.Code))
+	context.Data(http.StatusOK, "application/pdf", pdf)
+}
+
 func (handler *transactionHandler) GetTransactionNotification(context *gin.Context) {
 	var input transaction.TransactionNotificationInput
 
@@ -151,3 +499,79 @@ func (handler *transactionHandler) GetTransactionNotification(context *gin.Conte
 
 	context.JSON(http.StatusOK, input)
 }
+
+func (handler *transactionHandler) FindDiscrepancies(context *gin.Context) {
+	currentUser := context.MustGet("currentUser").(user.User)
+
+	discrepancyList, err := handler.service.FindDiscrepancies(currentUser)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to get payment discrepancies due to server error",
+			http.StatusBadRequest,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"Payment discrepancies fetched",
+		http.StatusOK,
+		"success",
+		transaction.FormatDiscrepancyList(discrepancyList),
+	)
+	context.JSON(http.StatusOK, response)
+}
+
+func (handler *transactionHandler) CreateRefund(context *gin.Context) {
+	var inputID transaction.FindTransactionByIDInput
+	var input transaction.CreateRefundInput
+
+	err := context.ShouldBindUri(&inputID)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to refund transaction with that ID",
+			http.StatusUnprocessableEntity,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	err = context.ShouldBindJSON(&input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to refund transaction due to bad inputs",
+			http.StatusUnprocessableEntity,
+			"failed",
+			err.Error(),
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	currentUser := context.MustGet("currentUser").(user.User)
+	input.User = currentUser
+
+	newRefund, err := handler.service.CreateRefund(inputID, input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to refund transaction due to server error",
+			http.StatusBadRequest,
+			"failed",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"Transaction successfully refunded!",
+		http.StatusOK,
+		"success",
+		transaction.FormatRefund(newRefund),
+	)
+	context.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"rocketship/helper"
+	"rocketship/imaging"
+	"rocketship/storage"
+	"rocketship/upload"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	avatarMaxSize        = 2 << 20
+	campaignImageMaxSize = 5 << 20
+	campaignVideoMaxSize = 50 << 20
+)
+
+// saveUpload validates the file and stores it under prefix, returning the generated key.
+func saveUpload(storageService storage.Service, file *multipart.FileHeader, prefix string, maxSize int64) (string, error) {
+	processed, err := upload.Process(file, maxSize)
+	if err != nil {
+		return "", err
+	}
+
+	key := processed.Key(prefix)
+	err = storageService.Put(key, processed.Reader(), int64(len(processed.Content)), processed.ContentType)
+	if err != nil {
+		return "", err
+	}
+
+	return key, nil
+}
+
+// saveVideoUpload checks the container and streams the video to storage, returning the key and content type.
+func saveVideoUpload(storageService storage.Service, file *multipart.FileHeader, prefix string, maxSize int64) (string, string, error) {
+	video, err := upload.ProcessVideo(file, maxSize)
+	if err != nil {
+		return "", "", err
+	}
+
+	content, err := file.Open()
+	if err != nil {
+		return "", "", err
+	}
+	defer content.Close()
+
+	key := video.Key(prefix)
+	err = storageService.Put(key, content, video.Size, video.ContentType)
+	if err != nil {
+		return "", "", err
+	}
+
+	return key, video.ContentType, nil
+}
+
+// deleteUpload removes an original with its variants, missing variants are not an error
+func deleteUpload(storageService storage.Service, key string) {
+	keys := append([]string{key}, imaging.VariantKeys(key)...)
+
+	for _, key := range keys {
+		err := storageService.Delete(key)
+		if err != nil {
+			log.Println("Failed to delete stored file", key+":", err)
+		}
+	}
+}
+
+// uploadFailed answers with the validation details when the file itself was rejected.
+func uploadFailed(context *gin.Context, field string, message string, err error) {
+	var validationError *upload.ValidationError
+	if !errors.As(err, &validationError) {
+		response := helper.APIResponse(
+			message+" due to server error",
+			http.StatusBadRequest,
+			"error",
+			gin.H{"is_uploaded": false},
+		)
+
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	data := gin.H{
+		"is_uploaded": false,
+		"errors": []gin.H{{
+			"field":   field,
+			"code":    validationError.Code,
+			"message": validationError.Message,
+		}},
+	}
+
+	response := helper.APIResponse(
+		message+" due to invalid file",
+		http.StatusUnprocessableEntity,
+		"failed",
+		data,
+	)
+
+	context.JSON(http.StatusUnprocessableEntity, response)
+}
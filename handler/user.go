@@ -5,18 +5,22 @@ import (
 	"net/http"
 	"rocketship/auth"
 	"rocketship/helper"
+	"rocketship/imaging"
+	"rocketship/storage"
 	"rocketship/user"
 
 	"github.com/gin-gonic/gin"
 )
 
 type userHandler struct {
-	userService user.Service
-	authService auth.Service
+	userService    user.Service
+	authService    auth.Service
+	storageService storage.Service
+	variantWorker  imaging.Worker
 }
 
-func NewUserHandler(userService user.Service, authService auth.Service) *userHandler {
-	return &userHandler{userService, authService}
+func NewUserHandler(userService user.Service, authService auth.Service, storageService storage.Service, variantWorker imaging.Worker) *userHandler {
+	return &userHandler{userService, authService, storageService, variantWorker}
 }
 
 func (handler *userHandler) RegisterUser(context *gin.Context) {
@@ -62,7 +66,7 @@ func (handler *userHandler) RegisterUser(context *gin.Context) {
 		return
 	}
 
-	formattedUser := user.FormatUser(newUser, token)
+	formattedUser := user.FormatUser(newUser, token, handler.storageService)
 	response := helper.APIResponse(
 		"Account has been registered",
 		http.StatusOK,
@@ -129,7 +133,7 @@ func (handler *userHandler) Login(context *gin.Context) {
 		return
 	}
 
-	formattedUser := user.FormatUser(loggedUser, token)
+	formattedUser := user.FormatUser(loggedUser, token, handler.storageService)
 	response := helper.APIResponse(
 		"Log in successful",
 		http.StatusOK,
@@ -212,27 +216,19 @@ func (handler *userHandler) UploadAvatar(context *gin.Context) {
 		)
 
 		context.JSON(http.StatusUnprocessableEntity, response)
+		return
 	}
 
-	path := fmt.Sprintf("images/%d-%s", userID, file.Filename)
-	err = context.SaveUploadedFile(file, path)
+	key, err := saveUpload(handler.storageService, file, fmt.Sprintf("avatars/%d", userID), avatarMaxSize)
 	if err != nil {
-		data := gin.H{
-			"is_uploaded": false,
-		}
-
-		response := helper.APIResponse(
-			"Failed to save avatar due to server error",
-			http.StatusBadRequest,
-			"error",
-			data,
-		)
-
-		context.JSON(http.StatusBadRequest, response)
+		uploadFailed(context, "avatar", "Failed to save avatar", err)
+		return
 	}
 
-	_, err = handler.userService.UploadAvatar(userID, path)
+	_, err = handler.userService.UploadAvatar(userID, key)
 	if err != nil {
+		handler.storageService.Delete(key)
+
 		data := gin.H{
 			"is_uploaded": false,
 		}
@@ -245,8 +241,11 @@ func (handler *userHandler) UploadAvatar(context *gin.Context) {
 		)
 
 		context.JSON(http.StatusBadRequest, response)
+		return
 	}
 
+	handler.variantWorker.Notify()
+
 	data := gin.H{"is_uploaded": true}
 	response := helper.APIResponse(
 		"User's avatar updated",
@@ -261,7 +260,7 @@ func (handler *userHandler) UploadAvatar(context *gin.Context) {
 func (hanlder *userHandler) FetchCurrentUser(context *gin.Context) {
 	currentUser := context.MustGet("currentUser").(user.User)
 
-	formatter := user.FormatUser(currentUser, "")
+	formatter := user.FormatUser(currentUser, "", hanlder.storageService)
 
 	response := helper.APIResponse(
 		"User fetched",
@@ -272,3 +271,44 @@ func (hanlder *userHandler) FetchCurrentUser(context *gin.Context) {
 
 	context.JSON(http.StatusOK, response)
 }
+
+func (handler *userHandler) UpdateProfile(context *gin.Context) {
+	var input user.UpdateProfileInput
+
+	err := context.ShouldBindJSON(&input)
+	if err != nil {
+		errors := helper.FormatError(err)
+		errorMessage := gin.H{"errors": errors}
+
+		response := helper.APIResponse(
+			"Failed to update profile due to bad inputs",
+			http.StatusUnprocessableEntity,
+			"failed",
+			errorMessage,
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	currentUser := context.MustGet("currentUser").(user.User)
+
+	updatedUser, err := handler.userService.UpdateProfile(currentUser.ID, input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to update profile",
+			http.StatusBadRequest,
+			"failed",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"Profile updated",
+		http.StatusOK,
+		"success",
+		user.FormatUser(updatedUser, "", handler.storageService),
+	)
+	context.JSON(http.StatusOK, response)
+}
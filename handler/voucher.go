@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"net/http"
+	"rocketship/helper"
+	"rocketship/user"
+	"rocketship/voucher"
+
+	"github.com/gin-gonic/gin"
+)
+
+type voucherHandler struct {
+	service voucher.Service
+}
+
+func NewVoucherHandler(service voucher.Service) *voucherHandler {
+	return &voucherHandler{service}
+}
+
+func (handler *voucherHandler) FindVouchers(context *gin.Context) {
+	currentUser := context.MustGet("currentUser").(user.User)
+
+	voucherList, err := handler.service.FindVouchers(currentUser)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to get vouchers due to server error",
+			http.StatusBadRequest,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"Vouchers fetched",
+		http.StatusOK,
+		"success",
+		voucher.FormatVoucherList(voucherList),
+	)
+	context.JSON(http.StatusOK, response)
+}
+
+func (handler *voucherHandler) CreateVoucher(context *gin.Context) {
+	var input voucher.CreateVoucherInput
+
+	err := context.ShouldBindJSON(&input)
+	if err != nil {
+		errors := helper.FormatError(err)
+		errorMessage := gin.H{"errors": errors}
+
+		response := helper.APIResponse(
+			"Failed to create voucher due to bad inputs",
+			http.StatusUnprocessableEntity,
+			"failed",
+			errorMessage,
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	currentUser := context.MustGet("currentUser").(user.User)
+	input.User = currentUser
+
+	newVoucher, err := handler.service.CreateVoucher(input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to create voucher due to server error",
+			http.StatusBadRequest,
+			"failed",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"Voucher successfully created!",
+		http.StatusOK,
+		"success",
+		voucher.FormatVoucher(newVoucher),
+	)
+	context.JSON(http.StatusOK, response)
+}
+
+func (handler *voucherHandler) FindVoucher(context *gin.Context) {
+	var input voucher.VoucherDetailInput
+
+	err := context.ShouldBindUri(&input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to get voucher due to bad inputs",
+			http.StatusUnprocessableEntity,
+			"failed",
+			err.Error(),
+		)
+		context.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	currentUser := context.MustGet("currentUser").(user.User)
+	input.User = currentUser
+
+	voucherDetail, err := handler.service.FindVoucher(input)
+	if err != nil {
+		response := helper.APIResponse(
+			"Failed to get voucher due to server error",
+			http.StatusBadRequest,
+			"error",
+			err.Error(),
+		)
+		context.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(
+		"Voucher fetched",
+		http.StatusOK,
+		"success",
+		voucher.FormatVoucherDetail(voucherDetail),
+	)
+	context.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,16 @@
+package helper
+
+import (
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// mysqlDuplicateEntry is the MySQL error number for a unique index violation
+const mysqlDuplicateEntry = 1062
+
+func IsDuplicateKey(err error) bool {
+	var mysqlError *mysql.MySQLError
+
+	return errors.As(err, &mysqlError) && mysqlError.Number == mysqlDuplicateEntry
+}
@@ -0,0 +1,47 @@
+package imaging
+
+import "image"
+
+// orient turns decoded pixels upright according to their EXIF orientation, since variants are written without EXIF
+func orient(original image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return original
+	}
+
+	bounds := original.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	//Orientations 5 to 8 are stored on their side, so width and height swap
+	outputWidth, outputHeight := width, height
+	if orientation >= 5 {
+		outputWidth, outputHeight = height, width
+	}
+
+	oriented := image.NewNRGBA(image.Rect(0, 0, outputWidth, outputHeight))
+	for y := 0; y < outputHeight; y++ {
+		for x := 0; x < outputWidth; x++ {
+			var sourceX, sourceY int
+
+			switch orientation {
+			case 2:
+				sourceX, sourceY = width-1-x, y
+			case 3:
+				sourceX, sourceY = width-1-x, height-1-y
+			case 4:
+				sourceX, sourceY = x, height-1-y
+			case 5:
+				sourceX, sourceY = y, x
+			case 6:
+				sourceX, sourceY = y, height-1-x
+			case 7:
+				sourceX, sourceY = width-1-y, height-1-x
+			case 8:
+				sourceX, sourceY = width-1-y, x
+			}
+
+			oriented.Set(x, y, original.At(bounds.Min.X+sourceX, bounds.Min.Y+sourceY))
+		}
+	}
+
+	return oriented
+}
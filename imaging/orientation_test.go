@@ -0,0 +1,48 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestOrient(t *testing.T) {
+	red := color.NRGBA{255, 0, 0, 255}
+	blue := color.NRGBA{0, 0, 255, 255}
+
+	//Red on the left, blue on the right
+	original := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	original.Set(0, 0, red)
+	original.Set(1, 0, blue)
+
+	testCases := []struct {
+		orientation int
+		width       int
+		height      int
+		first       color.NRGBA
+	}{
+		{1, 2, 1, red},
+		{2, 2, 1, blue},
+		{3, 2, 1, blue},
+		{4, 2, 1, red},
+		{5, 1, 2, red},
+		{6, 1, 2, red},
+		{7, 1, 2, blue},
+		{8, 1, 2, blue},
+	}
+
+	for _, testCase := range testCases {
+		oriented := orient(original, testCase.orientation)
+
+		bounds := oriented.Bounds()
+		if bounds.Dx() != testCase.width || bounds.Dy() != testCase.height {
+			t.Errorf("orientation %d: size %dx%d, expected %dx%d", testCase.orientation, bounds.Dx(), bounds.Dy(), testCase.width, testCase.height)
+			continue
+		}
+
+		first := color.NRGBAModel.Convert(oriented.At(0, 0)).(color.NRGBA)
+		if first != testCase.first {
+			t.Errorf("orientation %d: top left pixel %v, expected %v", testCase.orientation, first, testCase.first)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"path"
+	"rocketship/storage"
+	"rocketship/upload"
+	"strings"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	StatusPending = ""
+	StatusReady   = "ready"
+	StatusFailed  = "failed"
+)
+
+// Originals larger than this are not decoded, which keeps a crafted header from exhausting memory
+const maxPixels = 40000000
+
+// ErrInvalidImage marks originals that will never produce variants, other errors are retried
+var ErrInvalidImage = errors.New("image could not be decoded")
+
+type Variant struct {
+	Name   string
+	Width  int
+	Height int
+}
+
+// Variants are scaled to fit their box and never enlarged
+var Variants = []Variant{
+	{"thumbnail", 200, 200},
+	{"card", 640, 480},
+	{"hero", 1600, 900},
+}
+
+// VariantKey stores a variant next to its original, "campaign-images/3/ab12.jpg" becomes "campaign-images/3/ab12-card.webp"
+func VariantKey(key string, name string, extension string) string {
+	return strings.TrimSuffix(key, path.Ext(key)) + "-" + name + extension
+}
+
+// VariantKeys lists every file the worker may have stored for an original
+func VariantKeys(key string) []string {
+	keys := []string{}
+	extension := variantExtension(key)
+
+	for _, variant := range Variants {
+		if extension != ".webp" {
+			keys = append(keys, VariantKey(key, variant.Name, extension))
+		}
+
+		keys = append(keys, VariantKey(key, variant.Name, ".webp"))
+	}
+
+	return keys
+}
+
+// VariantURLs lists the download URLs once the worker has stored every variant, clients fall back to the original before that
+func VariantURLs(key string, status string, storageService storage.Service) map[string]string {
+	urls := map[string]string{}
+	if key == "" || status != StatusReady {
+		return urls
+	}
+
+	extension := variantExtension(key)
+	for _, variant := range Variants {
+		urls[variant.Name] = storage.URL(storageService, VariantKey(key, variant.Name, extension))
+		urls[variant.Name+"_webp"] = storage.URL(storageService, VariantKey(key, variant.Name, ".webp"))
+	}
+
+	return urls
+}
+
+// Generate reads an original and stores every variant in its own format and as WebP.
+func Generate(storageService storage.Service, key string) error {
+	file, err := storageService.Get(key)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return err
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	if config.Width*config.Height > maxPixels {
+		return fmt.Errorf("%w: too many pixels", ErrInvalidImage)
+	}
+
+	original, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	original = orient(original, upload.Orientation(content))
+
+	extension := variantExtension(key)
+	for _, variant := range Variants {
+		resized := resize(original, variant.Width, variant.Height)
+
+		if extension != ".webp" {
+			err := put(storageService, VariantKey(key, variant.Name, extension), resized, extension)
+			if err != nil {
+				return err
+			}
+		}
+
+		err := put(storageService, VariantKey(key, variant.Name, ".webp"), resized, ".webp")
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// variantExtension keeps PNG and WebP originals in their format, anything else is served as JPEG
+func variantExtension(key string) string {
+	switch strings.ToLower(path.Ext(key)) {
+	case ".png":
+		return ".png"
+	case ".webp":
+		return ".webp"
+	}
+
+	return ".jpg"
+}
+
+func resize(original image.Image, maxWidth int, maxHeight int) image.Image {
+	bounds := original.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	if width > maxWidth {
+		height = height * maxWidth / width
+		width = maxWidth
+	}
+
+	if height > maxHeight {
+		width = width * maxHeight / height
+		height = maxHeight
+	}
+
+	if width < 1 {
+		width = 1
+	}
+
+	if height < 1 {
+		height = 1
+	}
+
+	resized := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(resized, resized.Bounds(), original, bounds, draw.Src, nil)
+
+	return resized
+}
+
+func put(storageService storage.Service, key string, img image.Image, extension string) error {
+	var buffer bytes.Buffer
+	var err error
+	contentType := "image/jpeg"
+
+	switch extension {
+	case ".png":
+		contentType = "image/png"
+		err = png.Encode(&buffer, img)
+	case ".webp":
+		contentType = "image/webp"
+		err = encodeWebP(&buffer, img)
+	default:
+		err = jpeg.Encode(&buffer, img, &jpeg.Options{Quality: 82})
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return storageService.Put(key, &buffer, int64(buffer.Len()), contentType)
+}
@@ -0,0 +1,348 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+	"sort"
+)
+
+// The standard library cannot write WebP, so variants are stored as lossless
+// VP8L using the subtract green transform and one set of prefix codes.
+
+const maxCodeLength = 15
+
+var codeLengthOrder = []int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+type bitWriter struct {
+	buffer bytes.Buffer
+	bits   uint64
+	count  uint
+}
+
+func (writer *bitWriter) write(value uint32, count uint) {
+	writer.bits = writer.bits | uint64(value)<<writer.count
+	writer.count = writer.count + count
+
+	for writer.count >= 8 {
+		writer.buffer.WriteByte(byte(writer.bits))
+		writer.bits = writer.bits >> 8
+		writer.count = writer.count - 8
+	}
+}
+
+func (writer *bitWriter) bytes() []byte {
+	if writer.count > 0 {
+		writer.buffer.WriteByte(byte(writer.bits))
+		writer.bits = 0
+		writer.count = 0
+	}
+
+	return writer.buffer.Bytes()
+}
+
+type prefixCode struct {
+	lengths []int
+	codes   []uint32
+}
+
+func (code prefixCode) write(writer *bitWriter, symbol int) {
+	writer.write(code.codes[symbol], uint(code.lengths[symbol]))
+}
+
+func encodeWebP(output io.Writer, img image.Image) error {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width < 1 || height < 1 || width > 16384 || height > 16384 {
+		return errors.New("image size is not supported by WebP")
+	}
+
+	//Pixels as green, red, blue and alpha with red and blue stored relative to green
+	pixels := make([][4]int, 0, width*height)
+	hasAlpha := false
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			pixel := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			if pixel.A != 255 {
+				hasAlpha = true
+			}
+
+			r, g, b := int(pixel.R), int(pixel.G), int(pixel.B)
+			pixels = append(pixels, [4]int{g, (r - g) & 0xFF, (b - g) & 0xFF, int(pixel.A)})
+		}
+	}
+
+	writer := &bitWriter{}
+	writer.write(0x2F, 8)
+	writer.write(uint32(width-1), 14)
+	writer.write(uint32(height-1), 14)
+	if hasAlpha {
+		writer.write(1, 1)
+	} else {
+		writer.write(0, 1)
+	}
+	writer.write(0, 3)
+
+	//Subtract green transform, then no further transforms
+	writer.write(1, 1)
+	writer.write(2, 2)
+	writer.write(0, 1)
+
+	//No color cache and a single prefix code group
+	writer.write(0, 1)
+	writer.write(0, 1)
+
+	alphabetSizes := []int{256 + 24, 256, 256, 256, 40}
+	codes := make([]prefixCode, len(alphabetSizes))
+	for i, size := range alphabetSizes {
+		histogram := make([]int, size)
+		if i < 4 {
+			for _, pixel := range pixels {
+				histogram[pixel[i]]++
+			}
+		}
+
+		codes[i] = writePrefixCode(writer, histogram)
+	}
+
+	for _, pixel := range pixels {
+		for i := 0; i < 4; i++ {
+			codes[i].write(writer, pixel[i])
+		}
+	}
+
+	data := writer.bytes()
+	chunkSize := len(data)
+	padding := chunkSize % 2
+
+	header := make([]byte, 20)
+	copy(header[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:8], uint32(12+chunkSize+padding))
+	copy(header[8:12], "WEBP")
+	copy(header[12:16], "VP8L")
+	binary.LittleEndian.PutUint32(header[16:20], uint32(chunkSize))
+
+	_, err := output.Write(header)
+	if err != nil {
+		return err
+	}
+
+	_, err = output.Write(data)
+	if err != nil {
+		return err
+	}
+
+	if padding == 1 {
+		_, err = output.Write([]byte{0})
+	}
+
+	return err
+}
+
+// writePrefixCode stores the code for a histogram and returns it for encoding symbols.
+func writePrefixCode(writer *bitWriter, histogram []int) prefixCode {
+	symbols := []int{}
+	for symbol, count := range histogram {
+		if count > 0 {
+			symbols = append(symbols, symbol)
+		}
+	}
+
+	code := prefixCode{
+		lengths: make([]int, len(histogram)),
+		codes:   make([]uint32, len(histogram)),
+	}
+
+	//Up to two symbols below 256 fit the simple code, a single symbol takes no bits at all
+	if len(symbols) <= 2 && (len(symbols) == 0 || symbols[len(symbols)-1] < 256) {
+		if len(symbols) == 0 {
+			symbols = append(symbols, 0)
+		}
+
+		writer.write(1, 1)
+		writer.write(uint32(len(symbols)-1), 1)
+		if symbols[0] < 2 {
+			writer.write(0, 1)
+			writer.write(uint32(symbols[0]), 1)
+		} else {
+			writer.write(1, 1)
+			writer.write(uint32(symbols[0]), 8)
+		}
+
+		if len(symbols) == 2 {
+			writer.write(uint32(symbols[1]), 8)
+			code.lengths[symbols[0]] = 1
+			code.lengths[symbols[1]] = 1
+			code.codes[symbols[1]] = 1
+		}
+
+		return code
+	}
+
+	code.lengths = codeLengths(histogram, maxCodeLength)
+	code.codes = canonicalCodes(code.lengths)
+
+	//Code lengths are written literally, so only symbols 0 to 15 of the code length code are used
+	lengthHistogram := make([]int, len(codeLengthOrder))
+	for _, length := range code.lengths {
+		lengthHistogram[length]++
+	}
+
+	lengthCode := prefixCode{lengths: codeLengths(lengthHistogram, 7)}
+	used := 0
+	for _, length := range lengthCode.lengths {
+		if length > 0 {
+			used++
+		}
+	}
+
+	//A code length code needs two symbols to be a complete tree
+	if used == 1 {
+		for symbol, length := range lengthCode.lengths {
+			if length > 0 {
+				lengthCode.lengths[(symbol+1)%len(lengthCode.lengths)] = 1
+				lengthCode.lengths[symbol] = 1
+				break
+			}
+		}
+	}
+	lengthCode.codes = canonicalCodes(lengthCode.lengths)
+
+	count := len(codeLengthOrder)
+	for count > 4 && lengthCode.lengths[codeLengthOrder[count-1]] == 0 {
+		count--
+	}
+
+	writer.write(0, 1)
+	writer.write(uint32(count-4), 4)
+	for _, symbol := range codeLengthOrder[:count] {
+		writer.write(uint32(lengthCode.lengths[symbol]), 3)
+	}
+
+	//Every symbol of the alphabet is listed
+	writer.write(0, 1)
+	for _, length := range code.lengths {
+		lengthCode.write(writer, length)
+	}
+
+	return code
+}
+
+// codeLengths builds Huffman code lengths, flattening the histogram until no code exceeds maxLength.
+func codeLengths(histogram []int, maxLength int) []int {
+	counts := append([]int{}, histogram...)
+
+	for {
+		lengths := huffmanLengths(counts)
+
+		longest := 0
+		for _, length := range lengths {
+			if length > longest {
+				longest = length
+			}
+		}
+
+		if longest <= maxLength {
+			return lengths
+		}
+
+		for i, count := range counts {
+			if count > 0 {
+				counts[i] = (count + 1) / 2
+			}
+		}
+	}
+}
+
+func huffmanLengths(counts []int) []int {
+	type node struct {
+		count  int
+		symbol int
+		left   int
+		right  int
+	}
+
+	nodes := []node{}
+	for symbol, count := range counts {
+		if count > 0 {
+			nodes = append(nodes, node{count, symbol, -1, -1})
+		}
+	}
+
+	lengths := make([]int, len(counts))
+	if len(nodes) == 1 {
+		lengths[nodes[0].symbol] = 1
+		return lengths
+	}
+
+	queue := make([]int, len(nodes))
+	for i := range nodes {
+		queue[i] = i
+	}
+
+	for len(queue) > 1 {
+		sort.SliceStable(queue, func(i, j int) bool {
+			return nodes[queue[i]].count < nodes[queue[j]].count
+		})
+
+		nodes = append(nodes, node{nodes[queue[0]].count + nodes[queue[1]].count, -1, queue[0], queue[1]})
+		queue = append(queue[2:], len(nodes)-1)
+	}
+
+	var walk func(index int, depth int)
+	walk = func(index int, depth int) {
+		if nodes[index].symbol >= 0 {
+			lengths[nodes[index].symbol] = depth
+			return
+		}
+
+		walk(nodes[index].left, depth+1)
+		walk(nodes[index].right, depth+1)
+	}
+	if len(queue) == 1 {
+		walk(queue[0], 0)
+	}
+
+	return lengths
+}
+
+// canonicalCodes assigns codes like DEFLATE does, bit reversed because the stream is read LSB first.
+func canonicalCodes(lengths []int) []uint32 {
+	lengthCount := make([]uint32, maxCodeLength+1)
+	for _, length := range lengths {
+		lengthCount[length]++
+	}
+	lengthCount[0] = 0
+
+	nextCode := make([]uint32, maxCodeLength+2)
+	code := uint32(0)
+	for length := 1; length <= maxCodeLength; length++ {
+		code = (code + lengthCount[length-1]) << 1
+		nextCode[length] = code
+	}
+
+	codes := make([]uint32, len(lengths))
+	for symbol, length := range lengths {
+		if length == 0 {
+			continue
+		}
+
+		codes[symbol] = reverseBits(nextCode[length], length)
+		nextCode[length]++
+	}
+
+	return codes
+}
+
+func reverseBits(code uint32, length int) uint32 {
+	reversed := uint32(0)
+	for i := 0; i < length; i++ {
+		reversed = reversed<<1 | code&1
+		code = code >> 1
+	}
+
+	return reversed
+}
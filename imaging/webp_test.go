@@ -0,0 +1,78 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+
+	"golang.org/x/image/webp"
+)
+
+func TestEncodeWebPRoundTrip(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+
+	noise := image.NewNRGBA(image.Rect(0, 0, 37, 23))
+	for y := 0; y < 23; y++ {
+		for x := 0; x < 37; x++ {
+			noise.Set(x, y, color.NRGBA{uint8(random.Intn(256)), uint8(random.Intn(256)), uint8(random.Intn(256)), uint8(random.Intn(256))})
+		}
+	}
+
+	gradient := image.NewNRGBA(image.Rect(0, 0, 64, 16))
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 64; x++ {
+			gradient.Set(x, y, color.NRGBA{uint8(x * 4), uint8(y * 16), 128, 255})
+		}
+	}
+
+	plain := image.NewNRGBA(image.Rect(0, 0, 5, 5))
+	for y := 0; y < 5; y++ {
+		for x := 0; x < 5; x++ {
+			plain.Set(x, y, color.NRGBA{10, 200, 30, 255})
+		}
+	}
+
+	single := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	single.Set(0, 0, color.NRGBA{1, 2, 3, 4})
+
+	testCases := map[string]*image.NRGBA{
+		"noise":    noise,
+		"gradient": gradient,
+		"plain":    plain,
+		"single":   single,
+	}
+
+	for name, original := range testCases {
+		var buffer bytes.Buffer
+
+		err := encodeWebP(&buffer, original)
+		if err != nil {
+			t.Errorf("%s: encodeWebP() failed: %v", name, err)
+			continue
+		}
+
+		decoded, err := webp.Decode(&buffer)
+		if err != nil {
+			t.Errorf("%s: decoding failed: %v", name, err)
+			continue
+		}
+
+		if decoded.Bounds().Size() != original.Bounds().Size() {
+			t.Errorf("%s: size %v, expected %v", name, decoded.Bounds().Size(), original.Bounds().Size())
+			continue
+		}
+
+		//VP8L is lossless, so every pixel has to come back as it went in
+		for y := 0; y < original.Bounds().Dy(); y++ {
+			for x := 0; x < original.Bounds().Dx(); x++ {
+				expected := original.NRGBAAt(x, y)
+				actual := color.NRGBAModel.Convert(decoded.At(x, y)).(color.NRGBA)
+				if actual != expected {
+					t.Fatalf("%s: pixel %d,%d is %v, expected %v", name, x, y, actual, expected)
+				}
+			}
+		}
+	}
+}
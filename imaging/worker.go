@@ -0,0 +1,91 @@
+package imaging
+
+import (
+	"errors"
+	"log"
+	"os"
+	"rocketship/storage"
+	"time"
+)
+
+// Job is an original that still needs its variants, ID belongs to whichever source listed it
+type Job struct {
+	ID  int
+	Key string
+}
+
+type Source interface {
+	FindPendingVariants() ([]Job, error)
+	SaveVariantStatus(job Job, status string) error
+}
+
+type Worker interface {
+	Start()
+	Notify()
+}
+
+type worker struct {
+	storageService storage.Service
+	interval       time.Duration
+	sources        []Source
+	wake           chan struct{}
+}
+
+// NewWorker polls the sources for pending originals, so uploads missed by a restart are still processed
+func NewWorker(storageService storage.Service, interval time.Duration, sources ...Source) *worker {
+	return &worker{storageService, interval, sources, make(chan struct{}, 1)}
+}
+
+func (worker *worker) Start() {
+	go func() {
+		ticker := time.NewTicker(worker.interval)
+		defer ticker.Stop()
+
+		for {
+			worker.run()
+
+			select {
+			case <-ticker.C:
+			case <-worker.wake:
+			}
+		}
+	}()
+}
+
+// Notify starts a run right away instead of waiting for the next tick
+func (worker *worker) Notify() {
+	select {
+	case worker.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (worker *worker) run() {
+	for _, source := range worker.sources {
+		jobList, err := source.FindPendingVariants()
+		if err != nil {
+			log.Println("Failed to find images without variants:", err)
+			continue
+		}
+
+		for _, job := range jobList {
+			status := StatusReady
+
+			//Storage errors leave the job pending for the next run, originals that cannot be read are given up on
+			err := Generate(worker.storageService, job.Key)
+			if err != nil {
+				log.Println("Failed to generate image variants for", job.Key+":", err)
+				if !errors.Is(err, ErrInvalidImage) && !errors.Is(err, os.ErrNotExist) {
+					continue
+				}
+
+				status = StatusFailed
+			}
+
+			err = source.SaveVariantStatus(job, status)
+			if err != nil {
+				log.Println("Failed to save image variant status:", err)
+			}
+		}
+	}
+}
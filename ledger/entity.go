@@ -0,0 +1,56 @@
+package ledger
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	AccountBackerPayments = "backer_payments"
+	AccountPlatformFees   = "platform_fees"
+	AccountProviderFees   = "provider_fees"
+	AccountRefunds        = "refunds"
+	AccountPayouts        = "payouts"
+	AccountMatchingFunds  = "matching_funds"
+	AccountVouchers       = "vouchers"
+)
+
+func CampaignAccount(campaignID int) string {
+	return fmt.Sprintf("campaign:%d", campaignID)
+}
+
+// JournalEntry is append-only, a correction is always a new entry
+type JournalEntry struct {
+	ID           int
+	Kind         string
+	Reference    string `gorm:"uniqueIndex;size:100"`
+	CampaignID   int
+	Currency     string
+	Description  string
+	CreatedAt    time.Time
+	JournalLines []JournalLine
+}
+
+type JournalLine struct {
+	ID             int
+	JournalEntryID int
+	AccountCode    string
+	CampaignID     int
+	Debit          int
+	Credit         int
+	CreatedAt      time.Time
+}
+
+type CampaignTotals struct {
+	Payments int
+	Matches  int
+	Refunds  int
+	Fees     int
+	Payouts  int
+}
+
+type Inconsistency struct {
+	CampaignID int
+	EntryID    int
+	Message    string
+}
@@ -0,0 +1,112 @@
+package ledger
+
+import (
+	"rocketship/helper"
+
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	FindEntryByReference(reference string) (JournalEntry, error)
+	CreateEntry(entry JournalEntry) (JournalEntry, error)
+	SumCampaignAccountByKind(campaignID int) (map[string]CampaignTotalsRow, error)
+	FindUnbalancedEntryID() ([]int, error)
+}
+
+type CampaignTotalsRow struct {
+	Kind   string
+	Debit  int
+	Credit int
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{db}
+}
+
+func (repo *repository) FindEntryByReference(reference string) (JournalEntry, error) {
+	var entry JournalEntry
+
+	err := repo.db.Where("reference = ?", reference).Find(&entry).Error
+	if err != nil {
+		return entry, err
+	}
+
+	return entry, nil
+}
+
+func (repo *repository) CreateEntry(entry JournalEntry) (JournalEntry, error) {
+	//The entry and its lines are saved together, so a half-written entry can never exist
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		return tx.Create(&entry).Error
+	})
+
+	if err != nil {
+		return entry, err
+	}
+
+	return entry, nil
+}
+
+// CreateEntries saves entries inside a database transaction of another package, so they are posted together with the
+// change they belong to. A reference that is already posted is skipped
+func CreateEntries(tx *gorm.DB, entryList []JournalEntry) error {
+	for _, entry := range entryList {
+		var existingCount int64
+
+		err := tx.Model(&JournalEntry{}).Where("reference = ?", entry.Reference).Count(&existingCount).Error
+		if err != nil {
+			return err
+		}
+
+		if existingCount > 0 {
+			continue
+		}
+
+		err = tx.Create(&entry).Error
+		if err != nil && !helper.IsDuplicateKey(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (repo *repository) SumCampaignAccountByKind(campaignID int) (map[string]CampaignTotalsRow, error) {
+	var rowList []CampaignTotalsRow
+
+	err := repo.db.Model(&JournalLine{}).
+		Select("journal_entries.kind AS kind, COALESCE(SUM(journal_lines.debit), 0) AS debit, COALESCE(SUM(journal_lines.credit), 0) AS credit").
+		Joins("JOIN journal_entries ON journal_entries.id = journal_lines.journal_entry_id").
+		Where("journal_lines.account_code = ?", CampaignAccount(campaignID)).
+		Group("journal_entries.kind").
+		Scan(&rowList).Error
+	if err != nil {
+		return nil, err
+	}
+
+	totals := map[string]CampaignTotalsRow{}
+	for _, row := range rowList {
+		totals[row.Kind] = row
+	}
+
+	return totals, nil
+}
+
+func (repo *repository) FindUnbalancedEntryID() ([]int, error) {
+	var entryIDList []int
+
+	err := repo.db.Model(&JournalLine{}).
+		Select("journal_entry_id").
+		Group("journal_entry_id").
+		Having("SUM(debit) <> SUM(credit)").
+		Scan(&entryIDList).Error
+	if err != nil {
+		return entryIDList, err
+	}
+
+	return entryIDList, nil
+}
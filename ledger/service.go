@@ -0,0 +1,334 @@
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"rocketship/campaign"
+	"rocketship/helper"
+	"rocketship/money"
+)
+
+type Service interface {
+	RecordPayment(campaignID int, transactionID int, amount int, voucherAmount int, platformFee int, providerFee int) error
+	PaymentEntries(campaignID int, transactionID int, amount int, voucherAmount int, platformFee int, providerFee int) ([]JournalEntry, error)
+	RecordRefund(campaignID int, refundID int, amount int) error
+	RefundEntries(campaignID int, refundID int, amount int) ([]JournalEntry, error)
+	RecordPayout(campaignID int, payoutID int, amount int) error
+	PayoutEntries(campaignID int, payoutID int, amount int) ([]JournalEntry, error)
+	RecordMatch(campaignID int, contributionID int, amount int) error
+	RecordMatchReversal(campaignID int, contributionID int, refundID int, amount int) error
+	FindCampaignTotals(campaignID int) (CampaignTotals, error)
+	ProjectCampaign(campaignID int) error
+	RebuildProjections() error
+	VerifyConsistency() ([]Inconsistency, error)
+}
+
+type service struct {
+	repository Repository
+	campaign   campaign.Repository
+}
+
+func NewService(repository Repository, campaignRepository campaign.Repository) *service {
+	return &service{repository, campaignRepository}
+}
+
+func (service *service) RecordPayment(campaignID int, transactionID int, amount int, voucherAmount int, platformFee int, providerFee int) error {
+	entryList, err := service.PaymentEntries(campaignID, transactionID, amount, voucherAmount, platformFee, providerFee)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entryList {
+		err := service.save(entry)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PaymentEntries builds the entries of a payment without saving them, so they can be saved together with the payment itself.
+// The part of a pledge covered by a voucher is paid from the voucher account instead of by the backer.
+func (service *service) PaymentEntries(campaignID int, transactionID int, amount int, voucherAmount int, platformFee int, providerFee int) ([]JournalEntry, error) {
+	account := CampaignAccount(campaignID)
+	entryList := []JournalEntry{}
+
+	paymentEntry, err := service.buildEntry("payment", fmt.Sprintf("transaction:%d:payment", transactionID), campaignID, "backer payment", []JournalLine{
+		{AccountCode: AccountBackerPayments, Debit: amount - voucherAmount},
+		{AccountCode: AccountVouchers, Debit: voucherAmount},
+		{AccountCode: account, CampaignID: campaignID, Credit: amount},
+	})
+	if err != nil {
+		return entryList, err
+	}
+
+	feeEntry, err := service.buildEntry("fee", fmt.Sprintf("transaction:%d:fee", transactionID), campaignID, "platform and provider fee", []JournalLine{
+		{AccountCode: account, CampaignID: campaignID, Debit: platformFee + providerFee},
+		{AccountCode: AccountPlatformFees, Credit: platformFee},
+		{AccountCode: AccountProviderFees, Credit: providerFee},
+	})
+	if err != nil {
+		return entryList, err
+	}
+
+	for _, entry := range []JournalEntry{paymentEntry, feeEntry} {
+		if len(entry.JournalLines) > 0 {
+			entryList = append(entryList, entry)
+		}
+	}
+
+	return entryList, nil
+}
+
+func (service *service) RecordRefund(campaignID int, refundID int, amount int) error {
+	entryList, err := service.RefundEntries(campaignID, refundID, amount)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entryList {
+		err := service.save(entry)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RefundEntries builds the entry of a completed refund without saving it, so it can be saved together with the refund status
+func (service *service) RefundEntries(campaignID int, refundID int, amount int) ([]JournalEntry, error) {
+	return service.buildEntries("refund", fmt.Sprintf("refund:%d", refundID), campaignID, "refund to backer", []JournalLine{
+		{AccountCode: CampaignAccount(campaignID), CampaignID: campaignID, Debit: amount},
+		{AccountCode: AccountRefunds, Credit: amount},
+	})
+}
+
+func (service *service) RecordPayout(campaignID int, payoutID int, amount int) error {
+	entryList, err := service.PayoutEntries(campaignID, payoutID, amount)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entryList {
+		err := service.save(entry)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PayoutEntries builds the entry of a paid payout without saving it, so it can be saved together with the payout status
+func (service *service) PayoutEntries(campaignID int, payoutID int, amount int) ([]JournalEntry, error) {
+	return service.buildEntries("payout", fmt.Sprintf("payout:%d", payoutID), campaignID, "payout to creator", []JournalLine{
+		{AccountCode: CampaignAccount(campaignID), CampaignID: campaignID, Debit: amount},
+		{AccountCode: AccountPayouts, Credit: amount},
+	})
+}
+
+func (service *service) RecordMatch(campaignID int, contributionID int, amount int) error {
+	return service.post("match", fmt.Sprintf("match:%d", contributionID), campaignID, "sponsor matched contribution", []JournalLine{
+		{AccountCode: AccountMatchingFunds, Debit: amount},
+		{AccountCode: CampaignAccount(campaignID), CampaignID: campaignID, Credit: amount},
+	})
+}
+
+// A reversal is a match entry the other way round, so the campaign's matched total simply goes down
+func (service *service) RecordMatchReversal(campaignID int, contributionID int, refundID int, amount int) error {
+	return service.post("match", fmt.Sprintf("match:%d:refund:%d", contributionID, refundID), campaignID, "matched contribution reversed by refund", []JournalLine{
+		{AccountCode: CampaignAccount(campaignID), CampaignID: campaignID, Debit: amount},
+		{AccountCode: AccountMatchingFunds, Credit: amount},
+	})
+}
+
+func (service *service) post(kind string, reference string, campaignID int, description string, lineList []JournalLine) error {
+	entry, err := service.buildEntry(kind, reference, campaignID, description, lineList)
+	if err != nil {
+		return err
+	}
+
+	if len(entry.JournalLines) == 0 {
+		return nil
+	}
+
+	return service.save(entry)
+}
+
+func (service *service) save(entry JournalEntry) error {
+	//A reference is posted once, so replayed notifications never move money twice
+	existingEntry, err := service.repository.FindEntryByReference(entry.Reference)
+	if err != nil {
+		return err
+	}
+
+	if existingEntry.ID != 0 {
+		return nil
+	}
+
+	//The unique reference catches a concurrent post of the same entry, which counts as already posted
+	_, err = service.repository.CreateEntry(entry)
+	if err != nil && !helper.IsDuplicateKey(err) {
+		return err
+	}
+
+	return nil
+}
+
+// buildEntries is buildEntry for callers that save a list, an entry without any amount leaves the list empty
+func (service *service) buildEntries(kind string, reference string, campaignID int, description string, lineList []JournalLine) ([]JournalEntry, error) {
+	entry, err := service.buildEntry(kind, reference, campaignID, description, lineList)
+	if err != nil {
+		return []JournalEntry{}, err
+	}
+
+	if len(entry.JournalLines) == 0 {
+		return []JournalEntry{}, nil
+	}
+
+	return []JournalEntry{entry}, nil
+}
+
+// buildEntry checks an entry without saving it, an entry without any amount comes back without lines
+func (service *service) buildEntry(kind string, reference string, campaignID int, description string, lineList []JournalLine) (JournalEntry, error) {
+	debit := 0
+	credit := 0
+	postedLineList := []JournalLine{}
+	for _, line := range lineList {
+		if line.Debit < 0 || line.Credit < 0 {
+			return JournalEntry{}, errors.New("journal line amount cannot be negative")
+		}
+
+		if line.Debit == 0 && line.Credit == 0 {
+			continue
+		}
+
+		debit = debit + line.Debit
+		credit = credit + line.Credit
+		postedLineList = append(postedLineList, line)
+	}
+
+	if debit != credit {
+		return JournalEntry{}, errors.New("journal entry is not balanced")
+	}
+
+	if debit == 0 {
+		return JournalEntry{}, nil
+	}
+
+	//Every line of an entry is in the currency of the campaign it belongs to
+	campaign, err := service.campaign.FindCampaignByID(campaignID)
+	if err != nil {
+		return JournalEntry{}, err
+	}
+
+	entry := JournalEntry{
+		Kind:         kind,
+		Reference:    reference,
+		CampaignID:   campaignID,
+		Currency:     money.Normalize(campaign.Currency),
+		Description:  description,
+		JournalLines: postedLineList,
+	}
+
+	return entry, nil
+}
+
+func (service *service) FindCampaignTotals(campaignID int) (CampaignTotals, error) {
+	var totals CampaignTotals
+
+	kindTotals, err := service.repository.SumCampaignAccountByKind(campaignID)
+	if err != nil {
+		return totals, err
+	}
+
+	totals.Payments = kindTotals["payment"].Credit - kindTotals["payment"].Debit
+	totals.Matches = kindTotals["match"].Credit - kindTotals["match"].Debit
+	totals.Refunds = kindTotals["refund"].Debit - kindTotals["refund"].Credit
+	totals.Fees = kindTotals["fee"].Debit - kindTotals["fee"].Credit
+	totals.Payouts = kindTotals["payout"].Debit - kindTotals["payout"].Credit
+
+	return totals, nil
+}
+
+func (service *service) ProjectCampaign(campaignID int) error {
+	totals, err := service.FindCampaignTotals(campaignID)
+	if err != nil {
+		return err
+	}
+
+	//The campaign amounts are only a cached view of the ledger, so only those columns are written
+	err = service.campaign.UpdateCampaignAmounts(campaignID, currentAmount(totals), totals.Fees)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (service *service) RebuildProjections() error {
+	campaignList, err := service.campaign.FindAllCampaign()
+	if err != nil {
+		return err
+	}
+
+	for _, campaign := range campaignList {
+		err := service.ProjectCampaign(campaign.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (service *service) VerifyConsistency() ([]Inconsistency, error) {
+	inconsistencyList := []Inconsistency{}
+
+	entryIDList, err := service.repository.FindUnbalancedEntryID()
+	if err != nil {
+		return inconsistencyList, err
+	}
+
+	for _, entryID := range entryIDList {
+		inconsistencyList = append(inconsistencyList, Inconsistency{
+			EntryID: entryID,
+			Message: "journal entry debits and credits do not match",
+		})
+	}
+
+	campaignList, err := service.campaign.FindAllCampaign()
+	if err != nil {
+		return inconsistencyList, err
+	}
+
+	for _, campaign := range campaignList {
+		totals, err := service.FindCampaignTotals(campaign.ID)
+		if err != nil {
+			return inconsistencyList, err
+		}
+
+		if campaign.CurrentAmount != currentAmount(totals) {
+			inconsistencyList = append(inconsistencyList, Inconsistency{
+				CampaignID: campaign.ID,
+				Message:    fmt.Sprintf("current amount is %d, ledger says %d", campaign.CurrentAmount, currentAmount(totals)),
+			})
+		}
+
+		if campaign.FeeAmount != totals.Fees {
+			inconsistencyList = append(inconsistencyList, Inconsistency{
+				CampaignID: campaign.ID,
+				Message:    fmt.Sprintf("fee amount is %d, ledger says %d", campaign.FeeAmount, totals.Fees),
+			})
+		}
+	}
+
+	return inconsistencyList, nil
+}
+
+// Matched contributions count towards the goal just like backer payments
+func currentAmount(totals CampaignTotals) int {
+	return totals.Payments + totals.Matches - totals.Refunds
+}
@@ -0,0 +1,125 @@
+package ledger
+
+import (
+	"rocketship/campaign"
+	"testing"
+)
+
+// fakeRepository keeps what is posted, every reference is new to it
+type fakeRepository struct {
+	Repository
+	entries []JournalEntry
+}
+
+func (repo *fakeRepository) FindEntryByReference(reference string) (JournalEntry, error) {
+	return JournalEntry{}, nil
+}
+
+func (repo *fakeRepository) CreateEntry(entry JournalEntry) (JournalEntry, error) {
+	repo.entries = append(repo.entries, entry)
+	return entry, nil
+}
+
+type fakeCampaignRepository struct {
+	campaign.Repository
+}
+
+func (repo *fakeCampaignRepository) FindCampaignByID(campaignID int) (campaign.Campaign, error) {
+	return campaign.Campaign{ID: campaignID, Currency: "idr"}, nil
+}
+
+func TestEntriesBalanceToZero(t *testing.T) {
+	repository := &fakeRepository{}
+	service := NewService(repository, &fakeCampaignRepository{})
+
+	//A 100000 pledge with 20000 covered by a voucher, partly refunded, matched by a sponsor and paid out
+	paymentEntries, err := service.PaymentEntries(7, 1, 100000, 20000, 2500, 4900)
+	if err != nil {
+		t.Fatalf("PaymentEntries() returned %v", err)
+	}
+
+	refundEntries, err := service.RefundEntries(7, 2, 30000)
+	if err != nil {
+		t.Fatalf("RefundEntries() returned %v", err)
+	}
+
+	payoutEntries, err := service.PayoutEntries(7, 3, 50000)
+	if err != nil {
+		t.Fatalf("PayoutEntries() returned %v", err)
+	}
+
+	err = service.RecordMatch(7, 4, 10000)
+	if err != nil {
+		t.Fatalf("RecordMatch() returned %v", err)
+	}
+
+	err = service.RecordMatchReversal(7, 4, 2, 3000)
+	if err != nil {
+		t.Fatalf("RecordMatchReversal() returned %v", err)
+	}
+
+	entryList := append(append(append(paymentEntries, refundEntries...), payoutEntries...), repository.entries...)
+	if len(entryList) != 6 {
+		t.Fatalf("posted %d entries, expected 6", len(entryList))
+	}
+
+	accountBalances := map[string]int{}
+	for _, entry := range entryList {
+		entryBalance := 0
+		for _, line := range entry.JournalLines {
+			entryBalance = entryBalance + line.Debit - line.Credit
+			accountBalances[line.AccountCode] = accountBalances[line.AccountCode] + line.Credit - line.Debit
+		}
+
+		if entryBalance != 0 {
+			t.Errorf("entry %s is off by %d", entry.Reference, entryBalance)
+		}
+
+		if entry.Currency != "IDR" || entry.CampaignID != 7 {
+			t.Errorf("entry %s = %+v, expected campaign 7 in IDR", entry.Reference, entry)
+		}
+	}
+
+	total := 0
+	for _, balance := range accountBalances {
+		total = total + balance
+	}
+
+	if total != 0 {
+		t.Errorf("accounts add up to %d, expected 0", total)
+	}
+
+	expectedCampaignBalance := 100000 - 2500 - 4900 - 30000 - 50000 + 10000 - 3000
+	if accountBalances[CampaignAccount(7)] != expectedCampaignBalance {
+		t.Errorf("campaign account = %d, expected %d", accountBalances[CampaignAccount(7)], expectedCampaignBalance)
+	}
+}
+
+func TestBuildEntryChecksLines(t *testing.T) {
+	testCases := []struct {
+		name          string
+		lineList      []JournalLine
+		expectErr     bool
+		expectedLines int
+	}{
+		{"balanced", []JournalLine{{AccountCode: "a", Debit: 500}, {AccountCode: "b", Credit: 300}, {AccountCode: "c", Credit: 200}}, false, 3},
+		{"empty lines dropped", []JournalLine{{AccountCode: "a", Debit: 500}, {AccountCode: "b"}, {AccountCode: "c", Credit: 500}}, false, 2},
+		{"nothing to post", []JournalLine{{AccountCode: "a"}, {AccountCode: "b"}}, false, 0},
+		{"unbalanced", []JournalLine{{AccountCode: "a", Debit: 500}, {AccountCode: "b", Credit: 499}}, true, 0},
+		{"negative", []JournalLine{{AccountCode: "a", Debit: -500}, {AccountCode: "b", Credit: -500}}, true, 0},
+	}
+
+	service := NewService(&fakeRepository{}, &fakeCampaignRepository{})
+
+	for _, testCase := range testCases {
+		entry, err := service.buildEntry("payment", "test", 7, "test", testCase.lineList)
+		if (err != nil) != testCase.expectErr {
+			t.Errorf("%s: buildEntry() returned %v, expected an error: %t", testCase.name, err, testCase.expectErr)
+			continue
+		}
+
+		if len(entry.JournalLines) != testCase.expectedLines {
+			t.Errorf("%s: buildEntry() kept %d lines, expected %d", testCase.name, len(entry.JournalLines), testCase.expectedLines)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package mailer
+
+type Mail struct {
+	To          string
+	Subject     string
+	HTMLBody    string
+	Attachments []Attachment
+}
+
+type Attachment struct {
+	FileName    string
+	ContentType string
+	Content     []byte
+}
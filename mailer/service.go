@@ -0,0 +1,122 @@
+package mailer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+)
+
+type Service interface {
+	Send(mail Mail) error
+}
+
+type smtpService struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+}
+
+func NewSMTPService(host string, port string, username string, password string, from string) *smtpService {
+	return &smtpService{host, port, username, password, from}
+}
+
+func (service *smtpService) Send(mail Mail) error {
+	auth := smtp.PlainAuth("", service.username, service.password, service.host)
+
+	message, err := buildMessage(service.from, mail)
+	if err != nil {
+		return err
+	}
+
+	address := fmt.Sprintf("%s:%s", service.host, service.port)
+	err = smtp.SendMail(address, auth, service.from, []string{mail.To}, message)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type logService struct{}
+
+func NewLogService() *logService {
+	return &logService{}
+}
+
+func (service *logService) Send(mail Mail) error {
+	log.Printf("Mail to %s: %s (%d attachments)", mail.To, mail.Subject, len(mail.Attachments))
+
+	return nil
+}
+
+func buildMessage(from string, mail Mail) ([]byte, error) {
+	var message bytes.Buffer
+
+	headers := []string{
+		"From: " + from,
+		"To: " + headerValue(mail.To),
+		"Subject: " + mime.QEncoding.Encode("UTF-8", headerValue(mail.Subject)),
+		"MIME-Version: 1.0",
+	}
+
+	if len(mail.Attachments) == 0 {
+		headers = append(headers, "Content-Type: text/html; charset=\"UTF-8\"")
+		message.WriteString(strings.Join(headers, "\r\n") + "\r\n\r\n" + mail.HTMLBody)
+
+		return message.Bytes(), nil
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	htmlPart, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {"text/html; charset=\"UTF-8\""},
+	})
+	if err != nil {
+		return nil, err
+	}
+	htmlPart.Write([]byte(mail.HTMLBody))
+
+	for _, attachment := range mail.Attachments {
+		attachmentPart, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {attachment.ContentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {fmt.Sprintf("attachment; filename=\"%s\"", attachment.FileName)},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		//Encoded lines must not exceed 76 characters
+		encoded := base64.StdEncoding.EncodeToString(attachment.Content)
+		for len(encoded) > 76 {
+			attachmentPart.Write([]byte(encoded[:76] + "\r\n"))
+			encoded = encoded[76:]
+		}
+		attachmentPart.Write([]byte(encoded))
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	headers = append(headers, "Content-Type: multipart/mixed; boundary="+writer.Boundary())
+	message.WriteString(strings.Join(headers, "\r\n") + "\r\n\r\n")
+	message.Write(body.Bytes())
+
+	return message.Bytes(), nil
+}
+
+// headerValue keeps values such as campaign names in subjects from starting a header of their own
+func headerValue(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
@@ -6,12 +6,27 @@ import (
 	"os"
 	"rocketship/auth"
 	"rocketship/campaign"
+	"rocketship/fee"
+	"rocketship/follow"
 	"rocketship/handler"
 	"rocketship/helper"
+	"rocketship/imaging"
+	"rocketship/ledger"
+	"rocketship/mailer"
+	"rocketship/matching"
+	"rocketship/media"
+	"rocketship/milestone"
+	"rocketship/money"
 	"rocketship/payment"
+	"rocketship/payout"
+	"rocketship/profile"
+	"rocketship/storage"
+	"rocketship/subscription"
 	"rocketship/transaction"
 	"rocketship/user"
+	"rocketship/voucher"
 	"strings"
+	"time"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
@@ -27,32 +42,237 @@ func main() {
 	db_port := os.Getenv("DB_PORT")
 
 	dsn := db_name + ":" + db_pass + "@tcp(" + db_url + ":" + db_port + ")/" + db_name + "?charset=utf8mb4&parseTime=True&loc=Local"
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
+		DisableForeignKeyConstraintWhenMigrating: true,
+	})
 
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	//Transactions from before codes existed get their ID as code, which is the order ID they were sent to the provider with,
+	//so the unique index on codes can be created
+	if db.Migrator().HasTable(&transaction.Transaction{}) {
+		err = db.Exec("UPDATE transactions SET code = id WHERE code = '' OR code IS NULL").Error
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	//Profile columns may have been added as nullable, they are filled before becoming NOT NULL
+	for column, value := range map[string]interface{}{"bio": "", "location": "", "links": "", "show_backed_campaigns": false} {
+		if db.Migrator().HasColumn(&user.User{}, column) {
+			err = db.Model(&user.User{}).Where(column+" IS NULL").UpdateColumn(column, value).Error
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+
+	//Payments settled before SettledAt existed are marked once the column is added, the reconciler would settle them again otherwise
+	settledAtAdded := !db.Migrator().HasColumn(&transaction.Transaction{}, "settled_at")
+
+	//MIGRATION
+	err = db.AutoMigrate(
+		&user.User{},
+		&campaign.Campaign{},
+		&campaign.CampaignImage{},
+		&campaign.CampaignMedia{},
+		&campaign.CampaignUpdate{},
+		&campaign.Collaborator{},
+		&campaign.StretchGoal{},
+		&campaign.MilestoneEvent{},
+		&follow.Follow{},
+		&transaction.Transaction{},
+		&transaction.StatusHistory{},
+		&transaction.Discrepancy{},
+		&transaction.Refund{},
+		&subscription.Subscription{},
+		&payout.BankAccount{},
+		&payout.Payout{},
+		&fee.Rule{},
+		&ledger.JournalEntry{},
+		&ledger.JournalLine{},
+		&matching.Fund{},
+		&matching.Contribution{},
+		&voucher.Voucher{},
+		&voucher.BalanceEntry{},
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if settledAtAdded {
+		err = db.Model(&transaction.Transaction{}).Where("status IN ?", []string{"paid", "refunded"}).UpdateColumns(map[string]interface{}{
+			"paid_at":    gorm.Expr("updated_at"),
+			"settled_at": gorm.Expr("updated_at"),
+		}).Error
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	//Keys used to be stored as paths below the local images directory
+	for _, statement := range []string{
+		"UPDATE users SET avatar_file_name = SUBSTRING(avatar_file_name, 8) WHERE avatar_file_name LIKE 'images/%'",
+		"UPDATE campaign_images SET file_name = SUBSTRING(file_name, 8) WHERE file_name LIKE 'images/%'",
+	} {
+		err = db.Exec(statement).Error
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	//STORAGE
+	var storageService storage.Service = storage.NewLocalService("images", "/images")
+	if os.Getenv("STORAGE_DRIVER") == "s3" {
+		urlExpiry, _ := time.ParseDuration(os.Getenv("S3_URL_EXPIRY"))
+
+		s3Service, err := storage.NewS3Service(storage.S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			PublicURL: os.Getenv("S3_PUBLIC_URL"),
+			URLExpiry: urlExpiry,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		storageService = s3Service
+	}
+
 	//AUTH
 	authService := auth.NewJWTService()
 
+	//MAILER
+	var mailerService mailer.Service = mailer.NewLogService()
+	if os.Getenv("SMTP_HOST") != "" {
+		mailerService = mailer.NewSMTPService(
+			os.Getenv("SMTP_HOST"),
+			os.Getenv("SMTP_PORT"),
+			os.Getenv("SMTP_USERNAME"),
+			os.Getenv("SMTP_PASSWORD"),
+			os.Getenv("MAIL_FROM"),
+		)
+	}
+
+	//EXCHANGE RATES
+	exchangeRatesFile := os.Getenv("EXCHANGE_RATES_FILE")
+	if exchangeRatesFile == "" {
+		exchangeRatesFile = "exchange_rates.json"
+	}
+
+	rateSource, err := money.NewStaticRateSource(exchangeRatesFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	//MEDIA
+	var mediaResolver media.Resolver = media.NewOEmbedResolver()
+	if os.Getenv("OEMBED_DISABLED") == "true" {
+		mediaResolver = media.NewStaticResolver(map[string]media.Metadata{})
+	}
+
 	//USER
 	userRepository := user.NewRepository(db)
 	userService := user.NewService(userRepository)
-	userHandler := handler.NewUserHandler(userService, authService)
 
 	//CAMPAIGN
 	campaignRepository := campaign.NewRepository(db)
-	campaignService := campaign.NewService(campaignRepository)
-	campaignHandler := handler.NewCampaignHandler(campaignService)
+	campaignService := campaign.NewService(campaignRepository, mediaResolver, mailerService)
+
+	//FOLLOW
+	followRepository := follow.NewRepository(db)
+	followService := follow.NewService(followRepository, campaignRepository, userRepository)
+	followHandler := handler.NewFollowHandler(followService, storageService)
+
+	//IMAGE VARIANTS
+	variantWorker := imaging.NewWorker(storageService, 10*time.Minute, campaignService, userService)
+	userHandler := handler.NewUserHandler(userService, authService, storageService, variantWorker)
+
+	//FEE
+	feeRepository := fee.NewRepository(db)
+	feeService := fee.NewService(feeRepository)
+	feeHandler := handler.NewFeeHandler(feeService)
+
+	//LEDGER
+	ledgerRepository := ledger.NewRepository(db)
+	ledgerService := ledger.NewService(ledgerRepository, campaignRepository)
+
+	//MATCHING
+	matchingRepository := matching.NewRepository(db)
+	matchingService := matching.NewService(matchingRepository, campaignRepository, ledgerService, rateSource)
+	matchingHandler := handler.NewMatchingHandler(matchingService)
+	campaignHandler := handler.NewCampaignHandler(campaignService, matchingService, followService, storageService, variantWorker, rateSource)
+
+	//VOUCHER
+	voucherRepository := voucher.NewRepository(db)
+	voucherService := voucher.NewService(voucherRepository, campaignRepository)
+	voucherHandler := handler.NewVoucherHandler(voucherService)
+
+	//MILESTONE
+	milestoneService := milestone.NewService(campaignService, campaignRepository, followService, userRepository, mailerService)
 
 	//PAYMENT
 	paymentService := payment.NewPaymentService(campaignRepository)
 
+	//PAYOUT
+	payoutRepository := payout.NewRepository(db)
+	payoutService := payout.NewService(payoutRepository, campaignRepository, ledgerService)
+	payoutHandler := handler.NewPayoutHandler(payoutService)
+
 	//TRANSACTION
 	transactionRepository := transaction.NewRepository(db)
-	transactionService := transaction.NewService(transactionRepository, campaignRepository, paymentService)
-	transactionHandler := handler.NewTransactionHandler(transactionService, paymentService)
+	transactionService := transaction.NewService(transactionRepository, campaignRepository, paymentService, feeService, ledgerService, matchingService, voucherService, milestoneService, payoutService, rateSource, mailerService)
+	transactionHandler := handler.NewTransactionHandler(transactionService, paymentService, storageService)
+
+	//SUBSCRIPTION
+	subscriptionRepository := subscription.NewRepository(db)
+	subscriptionService := subscription.NewService(subscriptionRepository, campaignRepository, transactionRepository, transactionService, mailerService)
+	subscriptionHandler := handler.NewSubscriptionHandler(subscriptionService)
+
+	//PROFILE
+	profileService := profile.NewService(userService, campaignRepository, transactionRepository)
+	profileHandler := handler.NewProfileHandler(profileService, storageService)
+
+	//LEDGER COMMANDS
+	if len(os.Args) > 1 {
+		runLedgerCommand(os.Args[1], ledgerService, transactionService, payoutService)
+		return
+	}
+
+	//LEDGER BACKFILL
+	//Campaign totals are projected from the ledger, so money settled before the ledger existed is posted before anything is projected
+	var unpostedCount int64
+	err = db.Raw(`SELECT
+		(SELECT COUNT(*) FROM transactions WHERE status IN ('paid', 'refunded') AND NOT EXISTS
+			(SELECT 1 FROM journal_entries WHERE journal_entries.reference = CONCAT('transaction:', transactions.id, ':payment'))) +
+		(SELECT COUNT(*) FROM payouts WHERE status = 'paid' AND NOT EXISTS
+			(SELECT 1 FROM journal_entries WHERE journal_entries.reference = CONCAT('payout:', payouts.id))) +
+		(SELECT COUNT(*) FROM refunds WHERE status = 'completed' AND NOT EXISTS
+			(SELECT 1 FROM journal_entries WHERE journal_entries.reference = CONCAT('refund:', refunds.id)))`).Scan(&unpostedCount).Error
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if unpostedCount > 0 {
+		log.Printf("posting %d settled records to the ledger", unpostedCount)
+
+		err = rebuildLedger(ledgerService, transactionService, payoutService)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	//BACKGROUND JOBS
+	transactionReconciler := transaction.NewReconciler(transactionService, 15*time.Minute, time.Hour, 24*time.Hour)
+	transactionReconciler.Start()
+	subscriptionScheduler := subscription.NewScheduler(subscriptionService, time.Hour)
+	subscriptionScheduler.Start()
+	variantWorker.Start()
 
 	//SANDBOX HERE===========================================
 
@@ -61,7 +281,9 @@ func main() {
 	//ROUTER CONFIG
 	router := gin.Default()
 	router.Use(cors.Default())
-	router.Static("/images", "./images")
+	if os.Getenv("STORAGE_DRIVER") != "s3" {
+		router.Static("/images", "./images")
+	}
 	api := router.Group("api/v1")
 
 	//AUTH ROUTES
@@ -70,6 +292,8 @@ func main() {
 	api.POST("/sessions", userHandler.Login)
 	api.POST("/validate_email", userHandler.ValidateEmail)
 	api.POST("/avatars", authMiddleware(authService, userService), userHandler.UploadAvatar)
+	api.PUT("/users/me/profile", authMiddleware(authService, userService), userHandler.UpdateProfile)
+	api.GET("/users/:id/profile", profileHandler.FindProfile)
 
 	//CAMPAIGN ROUTES
 	api.GET("/campaigns", campaignHandler.FindCampaigns)
@@ -77,18 +301,125 @@ func main() {
 	api.POST("/campaigns", authMiddleware(authService, userService), campaignHandler.CreateCampaign)
 	api.POST("/campaign-images", authMiddleware(authService, userService), campaignHandler.UploadCampaignImage)
 	api.PUT("/campaigns/:id", authMiddleware(authService, userService), campaignHandler.UpdateCampaign)
+	api.PUT("/campaigns/:id/images/order", authMiddleware(authService, userService), campaignHandler.ReorderCampaignImages)
+	api.PUT("/campaign-images/:id/primary", authMiddleware(authService, userService), campaignHandler.MarkCampaignImageAsPrimary)
+	api.DELETE("/campaign-images/:id", authMiddleware(authService, userService), campaignHandler.DeleteCampaignImage)
+	api.GET("/campaigns/:id/collaborators", authMiddleware(authService, userService), campaignHandler.FindCollaborators)
+	api.POST("/campaigns/:id/collaborators", authMiddleware(authService, userService), campaignHandler.InviteCollaborator)
+	api.POST("/collaborators/accept", authMiddleware(authService, userService), campaignHandler.AcceptInvitation)
+	api.DELETE("/collaborators/:id", authMiddleware(authService, userService), campaignHandler.RemoveCollaborator)
+	api.POST("/campaign-descriptions/preview", authMiddleware(authService, userService), campaignHandler.PreviewDescription)
+	api.POST("/campaign-videos", authMiddleware(authService, userService), campaignHandler.UploadCampaignVideo)
+	api.POST("/campaign-embeds", authMiddleware(authService, userService), campaignHandler.CreateCampaignEmbed)
+	api.DELETE("/campaign-media/:id", authMiddleware(authService, userService), campaignHandler.DeleteCampaignMedia)
+	api.POST("/campaigns/:id/stretch-goals", authMiddleware(authService, userService), campaignHandler.CreateStretchGoal)
+	api.DELETE("/stretch-goals/:id", authMiddleware(authService, userService), campaignHandler.DeleteStretchGoal)
+	api.GET("/campaigns/:id/updates", campaignHandler.FindCampaignUpdates)
+	api.POST("/campaigns/:id/updates", authMiddleware(authService, userService), campaignHandler.CreateCampaignUpdate)
+
+	//FOLLOW ROUTES
+	api.POST("/campaigns/:id/follow", authMiddleware(authService, userService), followHandler.FollowCampaign)
+	api.DELETE("/campaigns/:id/follow", authMiddleware(authService, userService), followHandler.UnfollowCampaign)
+	api.POST("/users/:id/follow", authMiddleware(authService, userService), followHandler.FollowCreator)
+	api.DELETE("/users/:id/follow", authMiddleware(authService, userService), followHandler.UnfollowCreator)
+	api.GET("/users/me/following", authMiddleware(authService, userService), followHandler.FindFollowing)
+	api.GET("/users/me/feed", authMiddleware(authService, userService), followHandler.FindFeed)
 
 	//TRANSACTION ROUTES
 	api.GET("/campaigns/:id/transactions", authMiddleware(authService, userService), transactionHandler.FindTransactionByCampaignID)
+	api.GET("/campaigns/:id/supporters", transactionHandler.FindSupporters)
 	api.GET("/transactions", authMiddleware(authService, userService), transactionHandler.FindTransactionByUserID)
+	api.GET("/transactions/:id", authMiddleware(authService, userService), transactionHandler.FindTransaction)
+	api.GET("/transactions/:id/receipt", authMiddleware(authService, userService), transactionHandler.GetReceipt)
+	api.POST("/transactions/:id/payment", authMiddleware(authService, userService), transactionHandler.ResumePayment)
+	api.PUT("/transactions/:id/message", authMiddleware(authService, userService), transactionHandler.ModerateMessage)
 	api.POST("/transactions", authMiddleware(authService, userService), transactionHandler.CreateTransaction)
+	api.POST("/transactions/:id/refunds", authMiddleware(authService, userService), transactionHandler.CreateRefund)
+
+	//SUBSCRIPTION ROUTES
+	api.GET("/subscriptions", authMiddleware(authService, userService), subscriptionHandler.FindSubscriptions)
+	api.POST("/subscriptions", authMiddleware(authService, userService), subscriptionHandler.CreateSubscription)
+	api.POST("/subscriptions/:id/pause", authMiddleware(authService, userService), subscriptionHandler.PauseSubscription)
+	api.POST("/subscriptions/:id/resume", authMiddleware(authService, userService), subscriptionHandler.ResumeSubscription)
+	api.POST("/subscriptions/:id/cancel", authMiddleware(authService, userService), subscriptionHandler.CancelSubscription)
+	api.GET("/campaigns/:id/subscriptions/stats", authMiddleware(authService, userService), subscriptionHandler.FindCampaignStats)
+
+	//PAYOUT ROUTES
+	api.GET("/bank-account", authMiddleware(authService, userService), payoutHandler.FindBankAccount)
+	api.PUT("/bank-account", authMiddleware(authService, userService), payoutHandler.SaveBankAccount)
+	api.GET("/campaigns/:id/balance", authMiddleware(authService, userService), payoutHandler.FindBalance)
+	api.GET("/campaigns/:id/payouts", authMiddleware(authService, userService), payoutHandler.FindPayoutByCampaignID)
+	api.POST("/campaigns/:id/payouts", authMiddleware(authService, userService), payoutHandler.RequestPayout)
+	api.GET("/payouts", authMiddleware(authService, userService), payoutHandler.FindPayouts)
+	api.POST("/payouts/:id/approve", authMiddleware(authService, userService), payoutHandler.ApprovePayout)
+	api.POST("/payouts/:id/reject", authMiddleware(authService, userService), payoutHandler.RejectPayout)
+	api.POST("/payouts/:id/complete", authMiddleware(authService, userService), payoutHandler.CompletePayout)
+
+	//FEE ROUTES
+	api.GET("/fee-rules", authMiddleware(authService, userService), feeHandler.FindRules)
+	api.POST("/fee-rules", authMiddleware(authService, userService), feeHandler.CreateRule)
+
+	//MATCHING ROUTES
+	api.GET("/matching-funds", authMiddleware(authService, userService), matchingHandler.FindFunds)
+	api.POST("/matching-funds", authMiddleware(authService, userService), matchingHandler.CreateFund)
+
+	//VOUCHER ROUTES
+	api.GET("/vouchers", authMiddleware(authService, userService), voucherHandler.FindVouchers)
+	api.POST("/vouchers", authMiddleware(authService, userService), voucherHandler.CreateVoucher)
+	api.GET("/vouchers/:id", authMiddleware(authService, userService), voucherHandler.FindVoucher)
 
 	//PAYMENT ROUTES
 	api.POST("/transactions/notification", transactionHandler.GetTransactionNotification)
+	api.GET("/transactions/discrepancies", authMiddleware(authService, userService), transactionHandler.FindDiscrepancies)
 
 	router.Run()
 }
 
+// ledger-check reports drift between the ledger and campaign totals, ledger-rebuild repairs it
+func runLedgerCommand(command string, ledgerService ledger.Service, transactionService transaction.Service, payoutService payout.Service) {
+	switch command {
+	case "ledger-check":
+		inconsistencyList, err := ledgerService.VerifyConsistency()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		for _, inconsistency := range inconsistencyList {
+			log.Printf("campaign %d, entry %d: %s", inconsistency.CampaignID, inconsistency.EntryID, inconsistency.Message)
+		}
+
+		if len(inconsistencyList) > 0 {
+			log.Fatalf("ledger check found %d inconsistencies", len(inconsistencyList))
+		}
+
+		log.Println("ledger is consistent")
+	case "ledger-rebuild":
+		err := rebuildLedger(ledgerService, transactionService, payoutService)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		log.Println("campaign totals rebuilt from the ledger")
+	default:
+		log.Fatalf("unknown command %q, expected ledger-check or ledger-rebuild", command)
+	}
+}
+
+// rebuildLedger posts whatever is missing from the ledger and projects every campaign from it again
+func rebuildLedger(ledgerService ledger.Service, transactionService transaction.Service, payoutService payout.Service) error {
+	err := transactionService.BackfillLedger()
+	if err != nil {
+		return err
+	}
+
+	err = payoutService.BackfillLedger()
+	if err != nil {
+		return err
+	}
+
+	return ledgerService.RebuildProjections()
+}
+
 func authMiddleware(authService auth.Service, userService user.Service) gin.HandlerFunc {
 	return func(context *gin.Context) {
 		authHeader := context.GetHeader("Authorization")
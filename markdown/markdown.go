@@ -0,0 +1,383 @@
+package markdown
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Render turns a subset of Markdown into HTML. Every tag is written by the renderer itself and all text
+// is escaped, so raw HTML in the source only ever shows up as text. The allowed tags are p, br, h2 to h4,
+// strong, em, code, pre, blockquote, ul, ol, li, hr, a and img.
+//
+// imageURL decides which image sources may be shown, images it rejects are rendered as their alt text.
+func Render(source string, imageURL func(src string) (string, bool)) string {
+	renderer := renderer{imageURL}
+	lines := strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n")
+
+	var output strings.Builder
+	renderer.renderBlocks(&output, lines)
+
+	return output.String()
+}
+
+var (
+	headingPattern     = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	ruleLinePattern    = regexp.MustCompile(`^(\*\s*){3,}$|^(-\s*){3,}$|^(_\s*){3,}$`)
+	unorderedPattern   = regexp.MustCompile(`^[-*+]\s+(.*)$`)
+	orderedItemPattern = regexp.MustCompile(`^(\d{1,9})[.)]\s+(.*)$`)
+)
+
+type renderer struct {
+	imageURL func(src string) (string, bool)
+}
+
+func (renderer renderer) renderBlocks(output *strings.Builder, lines []string) {
+	paragraph := []string{}
+
+	flush := func() {
+		if len(paragraph) == 0 {
+			return
+		}
+
+		output.WriteString("<p>")
+		for i, line := range paragraph {
+			//Two trailing spaces mark a hard line break
+			hardBreak := strings.HasSuffix(line, "  ") && i < len(paragraph)-1
+			output.WriteString(renderer.renderInline(strings.TrimSpace(line)))
+
+			if hardBreak {
+				output.WriteString("<br>")
+			}
+
+			if i < len(paragraph)-1 {
+				output.WriteString("\n")
+			}
+		}
+		output.WriteString("</p>\n")
+
+		paragraph = []string{}
+	}
+
+	for i := 0; i < len(lines); {
+		trimmed := strings.TrimSpace(lines[i])
+
+		switch {
+		case trimmed == "":
+			flush()
+			i++
+
+		case strings.HasPrefix(trimmed, "```"):
+			flush()
+
+			code := []string{}
+			i++
+			for i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```") {
+				code = append(code, lines[i])
+				i++
+			}
+			i++
+
+			output.WriteString("<pre><code>")
+			output.WriteString(escape(strings.Join(code, "\n")))
+			output.WriteString("</code></pre>\n")
+
+		case headingPattern.MatchString(trimmed):
+			flush()
+
+			//Level one is kept for the campaign name, so headings start at h2
+			match := headingPattern.FindStringSubmatch(trimmed)
+			level := len(match[1]) + 1
+			if level > 4 {
+				level = 4
+			}
+
+			tag := "h" + strconv.Itoa(level)
+			output.WriteString("<" + tag + ">" + renderer.renderInline(match[2]) + "</" + tag + ">\n")
+			i++
+
+		case ruleLinePattern.MatchString(trimmed):
+			flush()
+			output.WriteString("<hr>\n")
+			i++
+
+		case strings.HasPrefix(trimmed, ">"):
+			flush()
+
+			quote := []string{}
+			for i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">") {
+				line := strings.TrimPrefix(strings.TrimSpace(lines[i]), ">")
+				quote = append(quote, strings.TrimPrefix(line, " "))
+				i++
+			}
+
+			output.WriteString("<blockquote>\n")
+			renderer.renderBlocks(output, quote)
+			output.WriteString("</blockquote>\n")
+
+		case unorderedPattern.MatchString(trimmed) || orderedItemPattern.MatchString(trimmed):
+			flush()
+			i = renderer.renderList(output, lines, i)
+
+		default:
+			paragraph = append(paragraph, lines[i])
+			i++
+		}
+	}
+
+	flush()
+}
+
+// renderList writes consecutive items of one list and returns the index of the first line after it.
+func (renderer renderer) renderList(output *strings.Builder, lines []string, start int) int {
+	ordered := !unorderedPattern.MatchString(strings.TrimSpace(lines[start]))
+	pattern := unorderedPattern
+	tag := "ul"
+	if ordered {
+		pattern = orderedItemPattern
+		tag = "ol"
+	}
+
+	items := []string{}
+	i := start
+	for i < len(lines) {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		match := pattern.FindStringSubmatch(trimmed)
+		if match != nil {
+			items = append(items, match[len(match)-1])
+			i++
+			continue
+		}
+
+		//Indented lines continue the previous item
+		if trimmed != "" && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			items[len(items)-1] = items[len(items)-1] + "\n" + trimmed
+			i++
+			continue
+		}
+
+		break
+	}
+
+	output.WriteString("<" + tag)
+	if ordered {
+		first := orderedItemPattern.FindStringSubmatch(strings.TrimSpace(lines[start]))[1]
+		number, _ := strconv.Atoi(first)
+		if number != 1 {
+			output.WriteString(` start="` + strconv.Itoa(number) + `"`)
+		}
+	}
+	output.WriteString(">\n")
+
+	for _, item := range items {
+		output.WriteString("<li>" + renderer.renderInline(item) + "</li>\n")
+	}
+
+	output.WriteString("</" + tag + ">\n")
+
+	return i
+}
+
+func (renderer renderer) renderInline(text string) string {
+	var output strings.Builder
+
+	//Remembers delimiters that have no closing run left, so unbalanced input stays linear
+	unclosed := map[string]bool{}
+
+	for i := 0; i < len(text); {
+		c := text[i]
+
+		switch {
+		case c == '\\' && i+1 < len(text) && strings.IndexByte(escapablePunctuation, text[i+1]) >= 0:
+			output.WriteString(escape(text[i+1 : i+2]))
+			i = i + 2
+			continue
+
+		case c == '`':
+			end := strings.IndexByte(text[i+1:], '`')
+			if end >= 0 {
+				output.WriteString("<code>" + escape(text[i+1:i+1+end]) + "</code>")
+				i = i + end + 2
+				continue
+			}
+
+		case c == '!' && i+1 < len(text) && text[i+1] == '[':
+			label, destination, end, ok := parseLink(text, i+1)
+			if ok {
+				output.WriteString(renderer.renderImage(label, destination))
+				i = end
+				continue
+			}
+
+		case c == '[':
+			label, destination, end, ok := parseLink(text, i)
+			if ok {
+				output.WriteString(renderLink(renderer.renderInline(label), destination))
+				i = end
+				continue
+			}
+
+		case c == '*' || c == '_':
+			delimiter := string(c)
+			if i+1 < len(text) && text[i+1] == c {
+				delimiter = delimiter + delimiter
+			}
+
+			if !unclosed[delimiter] && canOpen(text, i, delimiter) {
+				end := findCloser(text, i+len(delimiter), delimiter)
+				if end < 0 {
+					unclosed[delimiter] = true
+				} else {
+					tag := "em"
+					if len(delimiter) == 2 {
+						tag = "strong"
+					}
+
+					content := text[i+len(delimiter) : end]
+					output.WriteString("<" + tag + ">" + renderer.renderInline(content) + "</" + tag + ">")
+					i = end + len(delimiter)
+					continue
+				}
+			}
+
+			output.WriteString(delimiter)
+			i = i + len(delimiter)
+			continue
+		}
+
+		output.WriteString(escape(text[i : i+1]))
+		i++
+	}
+
+	return output.String()
+}
+
+func (renderer renderer) renderImage(alt string, source string) string {
+	imageURL, ok := renderer.imageURL(source)
+	if !ok {
+		return escape(alt)
+	}
+
+	return `<img src="` + escape(imageURL) + `" alt="` + escape(alt) + `">`
+}
+
+var allowedLinkSchemes = map[string]bool{
+	"http":   true,
+	"https":  true,
+	"mailto": true,
+}
+
+// renderLink only links absolute web and mail addresses, anything else keeps just its label.
+func renderLink(label string, destination string) string {
+	parsedURL, err := url.Parse(destination)
+	if err != nil || !allowedLinkSchemes[strings.ToLower(parsedURL.Scheme)] {
+		return label
+	}
+
+	return `<a href="` + escape(parsedURL.String()) + `" rel="nofollow noopener noreferrer">` + label + `</a>`
+}
+
+// parseLink reads "[label](destination)" starting at the opening bracket.
+func parseLink(text string, start int) (string, string, int, bool) {
+	closeLabel := strings.IndexByte(text[start:], ']')
+	if closeLabel < 0 {
+		return "", "", 0, false
+	}
+	closeLabel = closeLabel + start
+
+	if closeLabel+1 >= len(text) || text[closeLabel+1] != '(' {
+		return "", "", 0, false
+	}
+
+	//Parentheses inside the destination have to be balanced
+	closeDestination := -1
+	depth := 0
+	for i := closeLabel + 2; i < len(text) && closeDestination < 0; i++ {
+		switch text[i] {
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				closeDestination = i
+			}
+			depth--
+		}
+	}
+
+	if closeDestination < 0 {
+		return "", "", 0, false
+	}
+
+	//A title after the destination is allowed and ignored
+	fields := strings.Fields(text[closeLabel+2 : closeDestination])
+	if len(fields) == 0 {
+		return "", "", 0, false
+	}
+
+	destination := strings.TrimSuffix(strings.TrimPrefix(fields[0], "<"), ">")
+
+	return text[start+1 : closeLabel], destination, closeDestination + 1, true
+}
+
+// canOpen requires text right after the delimiter, underscores also must not sit inside a word.
+func canOpen(text string, i int, delimiter string) bool {
+	next := i + len(delimiter)
+	if next >= len(text) || text[next] == ' ' || text[next] == '\t' || text[next] == '\n' {
+		return false
+	}
+
+	if delimiter[0] == '_' && i > 0 && isWordCharacter(text[i-1]) {
+		return false
+	}
+
+	return true
+}
+
+// findCloser looks at whole runs of the delimiter character, so a run of another length belongs to a
+// nested emphasis and never closes this one.
+func findCloser(text string, start int, delimiter string) int {
+	for i := start + 1; i < len(text); i++ {
+		if text[i] != delimiter[0] {
+			continue
+		}
+
+		after := i
+		for after < len(text) && text[after] == delimiter[0] {
+			after++
+		}
+
+		run := i
+		i = after - 1
+
+		if after-run != len(delimiter) {
+			continue
+		}
+
+		previous := text[run-1]
+		if previous == ' ' || previous == '\t' || previous == '\n' || previous == '\\' {
+			continue
+		}
+
+		if delimiter[0] == '_' && after < len(text) && isWordCharacter(text[after]) {
+			continue
+		}
+
+		return run
+	}
+
+	return -1
+}
+
+func isWordCharacter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+const escapablePunctuation = "\\`*_{}[]()#+-.!>|~\""
+
+func escape(text string) string {
+	return html.EscapeString(text)
+}
@@ -0,0 +1,91 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func allowImages(prefix string) func(string) (string, bool) {
+	return func(source string) (string, bool) {
+		return source, strings.HasPrefix(source, prefix)
+	}
+}
+
+func TestRenderInlineNesting(t *testing.T) {
+	testCases := []struct {
+		source   string
+		expected string
+	}{
+		{"*a*", "<p><em>a</em></p>\n"},
+		{"**a**", "<p><strong>a</strong></p>\n"},
+		{"*a **b** c*", "<p><em>a <strong>b</strong> c</em></p>\n"},
+		{"**a *b* c**", "<p><strong>a <em>b</em> c</strong></p>\n"},
+		{"_a __b__ c_", "<p><em>a <strong>b</strong> c</em></p>\n"},
+		{"*a **b c*", "<p><em>a **b c</em></p>\n"},
+		{"**a* b**", "<p><strong>a* b</strong></p>\n"},
+		{"*a ***b*", "<p><em>a ***b</em></p>\n"},
+		{"snake_case_name", "<p>snake_case_name</p>\n"},
+		{"* not emphasis*", "<ul>\n<li>not emphasis*</li>\n</ul>\n"},
+		{"a * b * c", "<p>a * b * c</p>\n"},
+		{"\\*literal\\*", "<p>*literal*</p>\n"},
+		{"`*code*`", "<p><code>*code*</code></p>\n"},
+		{"[**bold** link](https://example.com)", `<p><a href="https://example.com" rel="nofollow noopener noreferrer"><strong>bold</strong> link</a></p>` + "\n"},
+	}
+
+	for _, testCase := range testCases {
+		rendered := Render(testCase.source, allowImages("https://cdn.example.com/"))
+		if rendered != testCase.expected {
+			t.Errorf("Render(%q) = %q, expected %q", testCase.source, rendered, testCase.expected)
+		}
+	}
+}
+
+func TestRenderEscapesUntrustedInput(t *testing.T) {
+	testCases := []struct {
+		source   string
+		expected string
+	}{
+		{"<script>alert(1)</script>", "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>\n"},
+		{"<img src=x onerror=alert(1)>", "<p>&lt;img src=x onerror=alert(1)&gt;</p>\n"},
+		{"[click](javascript:alert(1))", "<p>click</p>\n"},
+		{"[click](JavaScript:alert(1))", "<p>click</p>\n"},
+		{"[click](data:text/html;base64,PHNjcmlwdD4=)", "<p>click</p>\n"},
+		{"[click](//evil.example.com)", "<p>click</p>\n"},
+		{`[click](https://example.com/"onmouseover="alert(1))`, `<p><a href="https://example.com/%22onmouseover=%22alert%281%29" rel="nofollow noopener noreferrer">click</a></p>` + "\n"},
+		{"[<b>x</b>](https://example.com)", `<p><a href="https://example.com" rel="nofollow noopener noreferrer">&lt;b&gt;x&lt;/b&gt;</a></p>` + "\n"},
+		{"![alt](https://evil.example.com/x.png)", "<p>alt</p>\n"},
+		{`![a" onerror="alert(1)](https://cdn.example.com/x.png)`, `<p><img src="https://cdn.example.com/x.png" alt="a&#34; onerror=&#34;alert(1)"></p>` + "\n"},
+		{"```\n<script>alert(1)</script>\n```", "<pre><code>&lt;script&gt;alert(1)&lt;/script&gt;</code></pre>\n"},
+		{"`<b>`", "<p><code>&lt;b&gt;</code></p>\n"},
+	}
+
+	for _, testCase := range testCases {
+		rendered := Render(testCase.source, allowImages("https://cdn.example.com/"))
+		if rendered != testCase.expected {
+			t.Errorf("Render(%q) = %q, expected %q", testCase.source, rendered, testCase.expected)
+		}
+	}
+}
+
+func TestRenderBlocks(t *testing.T) {
+	testCases := []struct {
+		source   string
+		expected string
+	}{
+		{"# Title", "<h2>Title</h2>\n"},
+		{"#### Deep", "<h4>Deep</h4>\n"},
+		{"one\ntwo", "<p>one\ntwo</p>\n"},
+		{"one  \ntwo", "<p>one<br>\ntwo</p>\n"},
+		{"> quoted *text*", "<blockquote>\n<p>quoted <em>text</em></p>\n</blockquote>\n"},
+		{"- a\n- b", "<ul>\n<li>a</li>\n<li>b</li>\n</ul>\n"},
+		{"3. c\n4. d", "<ol start=\"3\">\n<li>c</li>\n<li>d</li>\n</ol>\n"},
+		{"---", "<hr>\n"},
+	}
+
+	for _, testCase := range testCases {
+		rendered := Render(testCase.source, allowImages("https://cdn.example.com/"))
+		if rendered != testCase.expected {
+			t.Errorf("Render(%q) = %q, expected %q", testCase.source, rendered, testCase.expected)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package matching
+
+import (
+	"rocketship/campaign"
+	"time"
+)
+
+// A fund matches either one campaign or every campaign in a category.
+// RatioPercent of 100 matches one to one, 200 doubles every donation.
+// Cap and MatchedAmount are in the fund currency.
+type Fund struct {
+	ID            int
+	SponsorName   string
+	CampaignID    int
+	Category      string
+	Currency      string
+	RatioPercent  int
+	Cap           int
+	MatchedAmount int
+	StartsAt      time.Time
+	EndsAt        time.Time
+	CreatedBy     int
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// The reversed amounts grow as the matched donation is refunded, the fund gets its share of the cap back
+type Contribution struct {
+	ID                 int
+	FundID             int
+	TransactionID      int
+	CampaignID         int
+	Amount             int
+	FundAmount         int
+	ReversedAmount     int
+	ReversedFundAmount int
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+	Fund               Fund
+	Campaign           campaign.Campaign
+}
@@ -0,0 +1,61 @@
+package matching
+
+import "time"
+
+type FundFormatter struct {
+	ID            int       `json:"id"`
+	SponsorName   string    `json:"sponsor_name"`
+	CampaignID    int       `json:"campaign_id"`
+	Category      string    `json:"category"`
+	Currency      string    `json:"currency"`
+	RatioPercent  int       `json:"ratio_percent"`
+	Cap           int       `json:"cap"`
+	MatchedAmount int       `json:"matched_amount"`
+	StartsAt      time.Time `json:"starts_at"`
+	EndsAt        time.Time `json:"ends_at"`
+}
+
+func FormatFund(fund Fund) FundFormatter {
+	formatter := FundFormatter{
+		ID:            fund.ID,
+		SponsorName:   fund.SponsorName,
+		CampaignID:    fund.CampaignID,
+		Category:      fund.Category,
+		Currency:      fund.Currency,
+		RatioPercent:  fund.RatioPercent,
+		Cap:           fund.Cap,
+		MatchedAmount: fund.MatchedAmount,
+		StartsAt:      fund.StartsAt,
+		EndsAt:        fund.EndsAt,
+	}
+
+	return formatter
+}
+
+func FormatFundList(fundList []Fund) []FundFormatter {
+	formatterList := []FundFormatter{}
+
+	for _, fund := range fundList {
+		formatter := FormatFund(fund)
+		formatterList = append(formatterList, formatter)
+	}
+
+	return formatterList
+}
+
+// Sponsors are listed once even when they fund a campaign more than once
+func FormatSponsorNames(fundList []Fund) []string {
+	sponsorNames := []string{}
+	seen := map[string]bool{}
+
+	for _, fund := range fundList {
+		if seen[fund.SponsorName] {
+			continue
+		}
+
+		seen[fund.SponsorName] = true
+		sponsorNames = append(sponsorNames, fund.SponsorName)
+	}
+
+	return sponsorNames
+}
@@ -0,0 +1,18 @@
+package matching
+
+import (
+	"rocketship/user"
+	"time"
+)
+
+type CreateFundInput struct {
+	SponsorName  string    `json:"sponsor_name" binding:"required"`
+	CampaignID   int       `json:"campaign_id" binding:"gte=0"`
+	Category     string    `json:"category"`
+	Currency     string    `json:"currency"`
+	RatioPercent int       `json:"ratio_percent" binding:"required,gt=0"`
+	Cap          int       `json:"cap" binding:"required,gt=0"`
+	StartsAt     time.Time `json:"starts_at" binding:"required"`
+	EndsAt       time.Time `json:"ends_at" binding:"required"`
+	User         user.User
+}
@@ -0,0 +1,145 @@
+package matching
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository interface {
+	FindAllFund() ([]Fund, error)
+	FindActiveFund(campaignID int, category string, at time.Time) ([]Fund, error)
+	CreateFund(fund Fund) (Fund, error)
+	FindContributionByTransactionID(transactionID int) ([]Contribution, error)
+	SaveContribution(fundID int, match func(fund Fund) (Contribution, error)) (Contribution, error)
+	ReverseContribution(contributionID int, reverse func(contribution Contribution) Contribution) (Contribution, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{db}
+}
+
+func (repo *repository) FindAllFund() ([]Fund, error) {
+	var fundList []Fund
+
+	err := repo.db.Order("starts_at desc").Find(&fundList).Error
+	if err != nil {
+		return fundList, err
+	}
+
+	return fundList, nil
+}
+
+func (repo *repository) FindActiveFund(campaignID int, category string, at time.Time) ([]Fund, error) {
+	var fundList []Fund
+
+	query := repo.db.Where("starts_at <= ? AND ends_at > ? AND matched_amount < cap", at, at)
+	if category != "" {
+		query = query.Where("campaign_id = ? OR (campaign_id = 0 AND category = ?)", campaignID, category)
+	} else {
+		query = query.Where("campaign_id = ?", campaignID)
+	}
+
+	err := query.Order("id asc").Find(&fundList).Error
+	if err != nil {
+		return fundList, err
+	}
+
+	return fundList, nil
+}
+
+func (repo *repository) CreateFund(fund Fund) (Fund, error) {
+	err := repo.db.Create(&fund).Error
+
+	if err != nil {
+		return fund, err
+	}
+
+	return fund, nil
+}
+
+func (repo *repository) FindContributionByTransactionID(transactionID int) ([]Contribution, error) {
+	var contributionList []Contribution
+
+	err := repo.db.Where("transaction_id = ?", transactionID).Find(&contributionList).Error
+	if err != nil {
+		return contributionList, err
+	}
+
+	return contributionList, nil
+}
+
+func (repo *repository) SaveContribution(fundID int, match func(fund Fund) (Contribution, error)) (Contribution, error) {
+	var contribution Contribution
+
+	//The fund row stays locked until the contribution is saved, so concurrent payments never overrun the cap
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		var fund Fund
+
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&fund, fundID).Error
+		if err != nil {
+			return err
+		}
+
+		contribution, err = match(fund)
+		if err != nil {
+			return err
+		}
+
+		if contribution.FundAmount == 0 {
+			return nil
+		}
+
+		err = tx.Model(&fund).Update("matched_amount", gorm.Expr("matched_amount + ?", contribution.FundAmount)).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(&contribution).Error
+	})
+
+	if err != nil {
+		return contribution, err
+	}
+
+	return contribution, nil
+}
+
+func (repo *repository) ReverseContribution(contributionID int, reverse func(contribution Contribution) Contribution) (Contribution, error) {
+	var contribution Contribution
+
+	//The contribution row stays locked while the reversal is worked out, so concurrent refunds never reverse the same share twice
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		var lockedContribution Contribution
+
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&lockedContribution, contributionID).Error
+		if err != nil {
+			return err
+		}
+
+		contribution = reverse(lockedContribution)
+
+		fundAmount := contribution.ReversedFundAmount - lockedContribution.ReversedFundAmount
+		if fundAmount == 0 && contribution.ReversedAmount == lockedContribution.ReversedAmount {
+			return nil
+		}
+
+		err = tx.Model(&Fund{}).Where("id = ?", contribution.FundID).Update("matched_amount", gorm.Expr("matched_amount - ?", fundAmount)).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Save(&contribution).Error
+	})
+
+	if err != nil {
+		return contribution, err
+	}
+
+	return contribution, nil
+}
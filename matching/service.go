@@ -0,0 +1,226 @@
+package matching
+
+import (
+	"errors"
+	"rocketship/campaign"
+	"rocketship/ledger"
+	"rocketship/money"
+	"rocketship/user"
+	"strings"
+	"time"
+)
+
+type Service interface {
+	FindFunds(currentUser user.User) ([]Fund, error)
+	CreateFund(input CreateFundInput) (Fund, error)
+	FindActiveFunds(campaign campaign.Campaign) ([]Fund, error)
+	MatchTransaction(campaign campaign.Campaign, transactionID int, amount int, paidAt time.Time) ([]Contribution, error)
+	ReverseTransaction(transactionID int, refundID int, refundedAmount int, paidAmount int) error
+}
+
+type service struct {
+	repository    Repository
+	campaign      campaign.Repository
+	ledgerService ledger.Service
+	rateSource    money.RateSource
+}
+
+func NewService(repository Repository, campaignRepository campaign.Repository, ledgerService ledger.Service, rateSource money.RateSource) *service {
+	return &service{repository, campaignRepository, ledgerService, rateSource}
+}
+
+func (service *service) FindFunds(currentUser user.User) ([]Fund, error) {
+	if currentUser.Role != "admin" {
+		return []Fund{}, errors.New("could not find matching funds due to lack of credentials")
+	}
+
+	fundList, err := service.repository.FindAllFund()
+	if err != nil {
+		return fundList, err
+	}
+
+	return fundList, nil
+}
+
+func (service *service) CreateFund(input CreateFundInput) (Fund, error) {
+	if input.User.Role != "admin" {
+		return Fund{}, errors.New("could not create matching fund due to lack of credentials")
+	}
+
+	category := strings.ToLower(strings.TrimSpace(input.Category))
+	if (input.CampaignID == 0) == (category == "") {
+		return Fund{}, errors.New("a matching fund needs either a campaign or a category")
+	}
+
+	if !input.EndsAt.After(input.StartsAt) {
+		return Fund{}, errors.New("a matching fund must end after it starts")
+	}
+
+	if !money.IsSupported(input.Currency) {
+		return Fund{}, errors.New("currency is not supported")
+	}
+
+	if input.CampaignID != 0 {
+		campaign, err := service.campaign.FindCampaignByID(input.CampaignID)
+		if err != nil {
+			return Fund{}, err
+		}
+
+		if campaign.ID == 0 {
+			return Fund{}, errors.New("no campaign found with this ID")
+		}
+	}
+
+	fund := Fund{
+		SponsorName:  strings.TrimSpace(input.SponsorName),
+		CampaignID:   input.CampaignID,
+		Category:     category,
+		Currency:     money.Normalize(input.Currency),
+		RatioPercent: input.RatioPercent,
+		Cap:          input.Cap,
+		StartsAt:     input.StartsAt,
+		EndsAt:       input.EndsAt,
+		CreatedBy:    input.User.ID,
+	}
+
+	newFund, err := service.repository.CreateFund(fund)
+	if err != nil {
+		return newFund, err
+	}
+
+	return newFund, nil
+}
+
+func (service *service) FindActiveFunds(campaign campaign.Campaign) ([]Fund, error) {
+	fundList, err := service.repository.FindActiveFund(campaign.ID, campaign.Category, time.Now())
+	if err != nil {
+		return fundList, err
+	}
+
+	return fundList, nil
+}
+
+// MatchTransaction is safe to call again for the same transaction, every fund matches it at most once.
+// Funds are picked by when the donation was paid, so a retry after a fund ended still matches it.
+func (service *service) MatchTransaction(campaign campaign.Campaign, transactionID int, amount int, paidAt time.Time) ([]Contribution, error) {
+	contributionList := []Contribution{}
+
+	existingList, err := service.repository.FindContributionByTransactionID(transactionID)
+	if err != nil {
+		return contributionList, err
+	}
+
+	matchedFunds := map[int]bool{}
+	for _, existing := range existingList {
+		matchedFunds[existing.FundID] = true
+
+		//An earlier attempt may have saved the contribution and failed before posting it, posting it twice is a no-op
+		err := service.ledgerService.RecordMatch(campaign.ID, existing.ID, existing.Amount)
+		if err != nil {
+			return contributionList, err
+		}
+	}
+
+	fundList, err := service.repository.FindActiveFund(campaign.ID, campaign.Category, paidAt)
+	if err != nil {
+		return contributionList, err
+	}
+
+	for _, fund := range fundList {
+		if matchedFunds[fund.ID] {
+			continue
+		}
+
+		contribution, err := service.repository.SaveContribution(fund.ID, func(fund Fund) (Contribution, error) {
+			return service.buildContribution(fund, campaign, transactionID, amount, paidAt)
+		})
+		if err != nil {
+			return contributionList, err
+		}
+
+		if contribution.ID == 0 {
+			continue
+		}
+
+		err = service.ledgerService.RecordMatch(campaign.ID, contribution.ID, contribution.Amount)
+		if err != nil {
+			return contributionList, err
+		}
+
+		contributionList = append(contributionList, contribution)
+	}
+
+	return contributionList, nil
+}
+
+// ReverseTransaction takes back the matched share of a refunded donation.
+// refundedAmount is everything refunded so far out of paidAmount, so repeated partial refunds add up to the whole contribution.
+func (service *service) ReverseTransaction(transactionID int, refundID int, refundedAmount int, paidAmount int) error {
+	contributionList, err := service.repository.FindContributionByTransactionID(transactionID)
+	if err != nil {
+		return err
+	}
+
+	for _, contribution := range contributionList {
+		var reversedAmount int
+
+		reversedContribution, err := service.repository.ReverseContribution(contribution.ID, func(contribution Contribution) Contribution {
+			previousAmount := contribution.ReversedAmount
+			contribution.ReversedAmount = money.Share(contribution.Amount, refundedAmount, paidAmount)
+			contribution.ReversedFundAmount = money.Share(contribution.FundAmount, refundedAmount, paidAmount)
+			reversedAmount = contribution.ReversedAmount - previousAmount
+
+			return contribution
+		})
+		if err != nil {
+			return err
+		}
+
+		if reversedAmount <= 0 {
+			continue
+		}
+
+		err = service.ledgerService.RecordMatchReversal(reversedContribution.CampaignID, reversedContribution.ID, refundID, reversedAmount)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// The fund passed in is freshly locked, so the remaining cap is exact
+func (service *service) buildContribution(fund Fund, campaign campaign.Campaign, transactionID int, amount int, paidAt time.Time) (Contribution, error) {
+	if paidAt.Before(fund.StartsAt) || !paidAt.Before(fund.EndsAt) {
+		return Contribution{}, nil
+	}
+
+	donation, err := money.Convert(money.New(amount, campaign.Currency), fund.Currency, service.rateSource)
+	if err != nil {
+		return Contribution{}, err
+	}
+
+	fundAmount := money.Share(donation.Amount, fund.RatioPercent, 100)
+	if remaining := fund.Cap - fund.MatchedAmount; fundAmount > remaining {
+		fundAmount = remaining
+	}
+
+	if fundAmount <= 0 {
+		return Contribution{}, nil
+	}
+
+	matchedAmount, err := money.Convert(money.New(fundAmount, fund.Currency), campaign.Currency, service.rateSource)
+	if err != nil {
+		return Contribution{}, err
+	}
+
+	contribution := Contribution{
+		FundID:        fund.ID,
+		TransactionID: transactionID,
+		CampaignID:    campaign.ID,
+		Amount:        matchedAmount.Amount,
+		FundAmount:    fundAmount,
+	}
+
+	return contribution, nil
+}
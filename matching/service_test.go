@@ -0,0 +1,137 @@
+package matching
+
+import (
+	"math/big"
+	"rocketship/campaign"
+	"rocketship/ledger"
+	"testing"
+	"time"
+)
+
+// fakeRateSource knows one US dollar as 16250 rupiah
+type fakeRateSource struct{}
+
+func (source fakeRateSource) Rate(from string, to string) (*big.Rat, error) {
+	if from == "USD" {
+		return big.NewRat(16250, 1), nil
+	}
+
+	return big.NewRat(1, 16250), nil
+}
+
+func TestBuildContributionStaysWithinTheCap(t *testing.T) {
+	startsAt := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	endsAt := startsAt.AddDate(0, 1, 0)
+	paidAt := startsAt.AddDate(0, 0, 10)
+
+	testCases := []struct {
+		name               string
+		fund               Fund
+		amount             int
+		paidAt             time.Time
+		expectedAmount     int
+		expectedFundAmount int
+	}{
+		{"one to one", Fund{Currency: "IDR", RatioPercent: 100, Cap: 1000000}, 50000, paidAt, 50000, 50000},
+		{"doubled", Fund{Currency: "IDR", RatioPercent: 200, Cap: 1000000}, 50000, paidAt, 100000, 100000},
+		{"capped", Fund{Currency: "IDR", RatioPercent: 100, Cap: 1000000, MatchedAmount: 990000}, 50000, paidAt, 10000, 10000},
+		{"cap used up", Fund{Currency: "IDR", RatioPercent: 100, Cap: 1000000, MatchedAmount: 1000000}, 50000, paidAt, 0, 0},
+		{"fund currency", Fund{Currency: "USD", RatioPercent: 100, Cap: 100000}, 162500, paidAt, 162500, 1000},
+		{"capped in fund currency", Fund{Currency: "USD", RatioPercent: 100, Cap: 100000, MatchedAmount: 99500}, 162500, paidAt, 81250, 500},
+		{"before the fund starts", Fund{Currency: "IDR", RatioPercent: 100, Cap: 1000000}, 50000, startsAt.Add(-time.Second), 0, 0},
+		{"when the fund ends", Fund{Currency: "IDR", RatioPercent: 100, Cap: 1000000}, 50000, endsAt, 0, 0},
+	}
+
+	service := NewService(nil, nil, nil, fakeRateSource{})
+	matchedCampaign := campaign.Campaign{ID: 7, Currency: "IDR"}
+
+	for _, testCase := range testCases {
+		fund := testCase.fund
+		fund.ID = 1
+		fund.StartsAt = startsAt
+		fund.EndsAt = endsAt
+
+		contribution, err := service.buildContribution(fund, matchedCampaign, 4, testCase.amount, testCase.paidAt)
+		if err != nil {
+			t.Errorf("%s: buildContribution() returned %v", testCase.name, err)
+			continue
+		}
+
+		if contribution.Amount != testCase.expectedAmount || contribution.FundAmount != testCase.expectedFundAmount {
+			t.Errorf("%s: buildContribution() = %d (%d in fund currency), expected %d (%d)", testCase.name, contribution.Amount, contribution.FundAmount, testCase.expectedAmount, testCase.expectedFundAmount)
+		}
+	}
+}
+
+type fakeRepository struct {
+	Repository
+	funds         []Fund
+	contributions []Contribution
+}
+
+func (repo *fakeRepository) FindContributionByTransactionID(transactionID int) ([]Contribution, error) {
+	return repo.contributions, nil
+}
+
+func (repo *fakeRepository) FindActiveFund(campaignID int, category string, at time.Time) ([]Fund, error) {
+	return repo.funds, nil
+}
+
+func (repo *fakeRepository) SaveContribution(fundID int, match func(fund Fund) (Contribution, error)) (Contribution, error) {
+	for _, fund := range repo.funds {
+		if fund.ID != fundID {
+			continue
+		}
+
+		contribution, err := match(fund)
+		if err != nil || contribution.FundAmount == 0 {
+			return Contribution{}, err
+		}
+
+		contribution.ID = len(repo.contributions) + 1
+		repo.contributions = append(repo.contributions, contribution)
+
+		return contribution, nil
+	}
+
+	return Contribution{}, nil
+}
+
+type fakeLedgerService struct {
+	ledger.Service
+	matches map[int]int
+}
+
+func (ledgerService *fakeLedgerService) RecordMatch(campaignID int, contributionID int, amount int) error {
+	ledgerService.matches[contributionID] = amount
+	return nil
+}
+
+// A retry after a failure in between matches the remaining funds and posts what the first attempt left unposted
+func TestMatchTransactionCanBeRetried(t *testing.T) {
+	paidAt := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	repository := &fakeRepository{
+		funds: []Fund{
+			{ID: 1, Currency: "IDR", RatioPercent: 100, Cap: 1000000, StartsAt: paidAt.AddDate(0, -1, 0), EndsAt: paidAt.AddDate(0, 1, 0)},
+			{ID: 2, Currency: "IDR", RatioPercent: 50, Cap: 1000000, StartsAt: paidAt.AddDate(0, -1, 0), EndsAt: paidAt.AddDate(0, 1, 0)},
+		},
+		contributions: []Contribution{{ID: 1, FundID: 1, TransactionID: 4, CampaignID: 7, Amount: 50000, FundAmount: 50000}},
+	}
+	ledgerService := &fakeLedgerService{matches: map[int]int{}}
+
+	service := NewService(repository, nil, ledgerService, fakeRateSource{})
+
+	contributionList, err := service.MatchTransaction(campaign.Campaign{ID: 7, Currency: "IDR"}, 4, 50000, paidAt)
+	if err != nil {
+		t.Fatalf("MatchTransaction() returned %v", err)
+	}
+
+	if len(contributionList) != 1 || contributionList[0].FundID != 2 || contributionList[0].Amount != 25000 {
+		t.Errorf("MatchTransaction() = %+v, expected only fund 2 to match 25000", contributionList)
+	}
+
+	if len(ledgerService.matches) != 2 || ledgerService.matches[1] != 50000 || ledgerService.matches[2] != 25000 {
+		t.Errorf("posted matches %v, expected both contributions", ledgerService.matches)
+	}
+}
@@ -0,0 +1,86 @@
+package media
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const (
+	ProviderYouTube = "youtube"
+	ProviderVimeo   = "vimeo"
+)
+
+var (
+	youTubeIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+	vimeoIDPattern   = regexp.MustCompile(`^[0-9]{1,12}$`)
+)
+
+// Embed only keeps the parsed video ID, the player URL is built from it so no client markup is ever stored
+type Embed struct {
+	Provider  string
+	VideoID   string
+	SourceURL string
+	EmbedURL  string
+}
+
+func ParseVideoURL(rawURL string) (Embed, error) {
+	parsedURL, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (parsedURL.Scheme != "https" && parsedURL.Scheme != "http") {
+		return Embed{}, errors.New("video URL must be a YouTube or Vimeo link")
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(parsedURL.Hostname()), "www.")
+	segments := strings.Split(strings.Trim(parsedURL.Path, "/"), "/")
+
+	switch host {
+	case "youtube.com", "m.youtube.com", "youtube-nocookie.com":
+		videoID := parsedURL.Query().Get("v")
+		if len(segments) == 2 && (segments[0] == "embed" || segments[0] == "shorts" || segments[0] == "live") {
+			videoID = segments[1]
+		}
+
+		return youTubeEmbed(videoID)
+	case "youtu.be":
+		return youTubeEmbed(segments[0])
+	case "vimeo.com":
+		return vimeoEmbed(segments[len(segments)-1])
+	case "player.vimeo.com":
+		if len(segments) == 2 && segments[0] == "video" {
+			return vimeoEmbed(segments[1])
+		}
+	}
+
+	return Embed{}, errors.New("video URL must be a YouTube or Vimeo link")
+}
+
+func youTubeEmbed(videoID string) (Embed, error) {
+	if !youTubeIDPattern.MatchString(videoID) {
+		return Embed{}, errors.New("YouTube link does not point to a video")
+	}
+
+	embed := Embed{
+		Provider:  ProviderYouTube,
+		VideoID:   videoID,
+		SourceURL: "https://www.youtube.com/watch?v=" + videoID,
+		EmbedURL:  "https://www.youtube-nocookie.com/embed/" + videoID,
+	}
+
+	return embed, nil
+}
+
+func vimeoEmbed(videoID string) (Embed, error) {
+	if !vimeoIDPattern.MatchString(videoID) {
+		return Embed{}, errors.New("Vimeo link does not point to a video")
+	}
+
+	embed := Embed{
+		Provider:  ProviderVimeo,
+		VideoID:   videoID,
+		SourceURL: "https://vimeo.com/" + videoID,
+		EmbedURL:  "https://player.vimeo.com/video/" + videoID,
+	}
+
+	return embed, nil
+}
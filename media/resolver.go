@@ -0,0 +1,107 @@
+package media
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrNotFound means the provider does not know the video or does not allow embedding it
+var ErrNotFound = errors.New("video could not be found or is not embeddable")
+
+type Metadata struct {
+	Title        string
+	AuthorName   string
+	ThumbnailURL string
+	Width        int
+	Height       int
+}
+
+type Resolver interface {
+	Resolve(embed Embed) (Metadata, error)
+}
+
+var oEmbedEndpoints = map[string]string{
+	ProviderYouTube: "https://www.youtube.com/oembed",
+	ProviderVimeo:   "https://vimeo.com/api/oembed.json",
+}
+
+type oEmbedResolver struct {
+	client    *http.Client
+	endpoints map[string]string
+}
+
+func NewOEmbedResolver() *oEmbedResolver {
+	return &oEmbedResolver{&http.Client{Timeout: 10 * time.Second}, oEmbedEndpoints}
+}
+
+func (resolver *oEmbedResolver) Resolve(embed Embed) (Metadata, error) {
+	endpoint, ok := resolver.endpoints[embed.Provider]
+	if !ok {
+		return Metadata{}, fmt.Errorf("no oEmbed endpoint for %s", embed.Provider)
+	}
+
+	query := url.Values{}
+	query.Set("url", embed.SourceURL)
+	query.Set("format", "json")
+
+	response, err := resolver.client.Get(endpoint + "?" + query.Encode())
+	if err != nil {
+		return Metadata{}, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound || response.StatusCode == http.StatusUnauthorized || response.StatusCode == http.StatusForbidden {
+		return Metadata{}, ErrNotFound
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return Metadata{}, fmt.Errorf("oEmbed request failed with %s", response.Status)
+	}
+
+	//The returned html is ignored, players are always built from the parsed video ID
+	var body struct {
+		Title        string `json:"title"`
+		AuthorName   string `json:"author_name"`
+		ThumbnailURL string `json:"thumbnail_url"`
+		Width        int    `json:"width"`
+		Height       int    `json:"height"`
+	}
+
+	err = json.NewDecoder(io.LimitReader(response.Body, 64*1024)).Decode(&body)
+	if err != nil {
+		return Metadata{}, err
+	}
+
+	metadata := Metadata{
+		Title:      body.Title,
+		AuthorName: body.AuthorName,
+		Width:      body.Width,
+		Height:     body.Height,
+	}
+
+	if strings.HasPrefix(body.ThumbnailURL, "https://") {
+		metadata.ThumbnailURL = body.ThumbnailURL
+	}
+
+	return metadata, nil
+}
+
+type staticResolver struct {
+	metadata map[string]Metadata
+}
+
+// NewStaticResolver answers from a fixed map keyed by source URL and never touches the network,
+// unknown videos resolve to empty metadata
+func NewStaticResolver(metadata map[string]Metadata) *staticResolver {
+	return &staticResolver{metadata}
+}
+
+func (resolver *staticResolver) Resolve(embed Embed) (Metadata, error) {
+	return resolver.metadata[embed.SourceURL], nil
+}
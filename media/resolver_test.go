@@ -0,0 +1,107 @@
+package media
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseVideoURL(t *testing.T) {
+	testCases := []struct {
+		url      string
+		provider string
+		videoID  string
+	}{
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ", ProviderYouTube, "dQw4w9WgXcQ"},
+		{"https://youtu.be/dQw4w9WgXcQ", ProviderYouTube, "dQw4w9WgXcQ"},
+		{"https://www.youtube.com/shorts/dQw4w9WgXcQ", ProviderYouTube, "dQw4w9WgXcQ"},
+		{"https://vimeo.com/76979871", ProviderVimeo, "76979871"},
+		{"https://player.vimeo.com/video/76979871", ProviderVimeo, "76979871"},
+		{"https://www.youtube.com/watch?v=short", "", ""},
+		{"https://example.com/watch?v=dQw4w9WgXcQ", "", ""},
+		{"javascript:alert(1)", "", ""},
+	}
+
+	for _, testCase := range testCases {
+		embed, err := ParseVideoURL(testCase.url)
+		if testCase.provider == "" {
+			if err == nil {
+				t.Errorf("ParseVideoURL(%q) should fail", testCase.url)
+			}
+			continue
+		}
+
+		if err != nil || embed.Provider != testCase.provider || embed.VideoID != testCase.videoID {
+			t.Errorf("ParseVideoURL(%q) = %s %s, %v, expected %s %s", testCase.url, embed.Provider, embed.VideoID, err, testCase.provider, testCase.videoID)
+		}
+	}
+}
+
+func TestOEmbedResolver(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		switch request.URL.Query().Get("url") {
+		case "https://www.youtube.com/watch?v=dQw4w9WgXcQ":
+			writer.Header().Set("Content-Type", "application/json")
+			writer.Write([]byte(`{"title":"Launch video","author_name":"Rocketship","thumbnail_url":"https://i.ytimg.com/vi/dQw4w9WgXcQ/hqdefault.jpg","width":480,"height":270,"html":"<script>alert(1)</script>"}`))
+		case "https://www.youtube.com/watch?v=insecureThu":
+			writer.Write([]byte(`{"title":"Plain thumbnail","thumbnail_url":"http://example.com/thumb.jpg"}`))
+		default:
+			http.Error(writer, "Not Found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	resolver := &oEmbedResolver{server.Client(), map[string]string{ProviderYouTube: server.URL}}
+
+	metadata, err := resolver.Resolve(Embed{Provider: ProviderYouTube, SourceURL: "https://www.youtube.com/watch?v=dQw4w9WgXcQ"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Metadata{
+		Title:        "Launch video",
+		AuthorName:   "Rocketship",
+		ThumbnailURL: "https://i.ytimg.com/vi/dQw4w9WgXcQ/hqdefault.jpg",
+		Width:        480,
+		Height:       270,
+	}
+	if metadata != expected {
+		t.Errorf("Resolve() = %+v, expected %+v", metadata, expected)
+	}
+
+	metadata, err = resolver.Resolve(Embed{Provider: ProviderYouTube, SourceURL: "https://www.youtube.com/watch?v=insecureThu"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if metadata.ThumbnailURL != "" {
+		t.Errorf("Resolve() kept the insecure thumbnail %q", metadata.ThumbnailURL)
+	}
+
+	_, err = resolver.Resolve(Embed{Provider: ProviderYouTube, SourceURL: "https://www.youtube.com/watch?v=missingVide"})
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Resolve() of an unknown video = %v, expected ErrNotFound", err)
+	}
+
+	_, err = resolver.Resolve(Embed{Provider: ProviderVimeo, SourceURL: "https://vimeo.com/76979871"})
+	if err == nil {
+		t.Error("Resolve() without an endpoint for the provider should fail")
+	}
+}
+
+func TestStaticResolver(t *testing.T) {
+	resolver := NewStaticResolver(map[string]Metadata{
+		"https://vimeo.com/76979871": {Title: "Known"},
+	})
+
+	metadata, err := resolver.Resolve(Embed{SourceURL: "https://vimeo.com/76979871"})
+	if err != nil || metadata.Title != "Known" {
+		t.Errorf("Resolve() = %+v, %v, expected the known title", metadata, err)
+	}
+
+	metadata, err = resolver.Resolve(Embed{SourceURL: "https://vimeo.com/1"})
+	if err != nil || metadata != (Metadata{}) {
+		t.Errorf("Resolve() of an unknown video = %+v, %v, expected empty metadata", metadata, err)
+	}
+}
@@ -0,0 +1,136 @@
+package milestone
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"rocketship/campaign"
+	"rocketship/follow"
+	"rocketship/mailer"
+	"rocketship/user"
+)
+
+type Service interface {
+	CheckCampaign(campaignID int) error
+}
+
+type service struct {
+	campaignService    campaign.Service
+	campaignRepository campaign.Repository
+	followService      follow.Service
+	userRepository     user.Repository
+	mailerService      mailer.Service
+}
+
+func NewService(campaignService campaign.Service, campaignRepository campaign.Repository, followService follow.Service, userRepository user.Repository, mailerService mailer.Service) *service {
+	return &service{campaignService, campaignRepository, followService, userRepository, mailerService}
+}
+
+// CheckCampaign records milestones the campaign crossed since the last check and tells the owner and followers about them
+func (s *service) CheckCampaign(campaignID int) error {
+	//A recorded milestone is never detected again, so everything the mails need is loaded before it is recorded
+	campaignByID, err := s.campaignRepository.FindCampaignByID(campaignID)
+	if err != nil {
+		return err
+	}
+
+	recipients, err := s.findRecipients(campaignByID)
+	if err != nil {
+		return err
+	}
+
+	milestoneEventList, err := s.campaignService.RecordMilestones(campaignID)
+	if err != nil {
+		return err
+	}
+
+	milestoneEventList = notableMilestones(milestoneEventList)
+	if len(milestoneEventList) == 0 {
+		return nil
+	}
+
+	//A popular campaign has many followers, so the payment does not wait for every mail
+	go s.notify(campaignByID, milestoneEventList, recipients)
+
+	return nil
+}
+
+func (s *service) findRecipients(campaignByID campaign.Campaign) ([]user.User, error) {
+	campaignFollowerIDs, err := s.followService.FindFollowerIDs(follow.KindCampaign, campaignByID.ID)
+	if err != nil {
+		return []user.User{}, err
+	}
+
+	creatorFollowerIDs, err := s.followService.FindFollowerIDs(follow.KindCreator, campaignByID.UserID)
+	if err != nil {
+		return []user.User{}, err
+	}
+
+	//The owner comes first and nobody gets the same mail twice
+	userIDs := []int{campaignByID.UserID}
+	seen := map[int]bool{campaignByID.UserID: true}
+	for _, userID := range append(campaignFollowerIDs, creatorFollowerIDs...) {
+		if !seen[userID] {
+			seen[userID] = true
+			userIDs = append(userIDs, userID)
+		}
+	}
+
+	return s.userRepository.FindUserByIDs(userIDs)
+}
+
+func (s *service) notify(campaignByID campaign.Campaign, milestoneEventList []campaign.MilestoneEvent, recipients []user.User) {
+	for _, milestoneEvent := range milestoneEventList {
+		for _, recipient := range recipients {
+			err := s.mailerService.Send(milestoneMail(campaignByID, milestoneEvent, recipient))
+			if err != nil {
+				log.Println("Failed to send milestone notification:", err)
+			}
+		}
+	}
+}
+
+// notableMilestones keeps only the highest share of the goal when a single payment crossed several at once
+func notableMilestones(milestoneEventList []campaign.MilestoneEvent) []campaign.MilestoneEvent {
+	notable := []campaign.MilestoneEvent{}
+	var highestGoal *campaign.MilestoneEvent
+
+	for _, milestoneEvent := range milestoneEventList {
+		if milestoneEvent.Kind != campaign.MilestoneGoal {
+			notable = append(notable, milestoneEvent)
+			continue
+		}
+
+		if highestGoal == nil || milestoneEvent.Percent > highestGoal.Percent {
+			goalEvent := milestoneEvent
+			highestGoal = &goalEvent
+		}
+	}
+
+	if highestGoal != nil {
+		notable = append([]campaign.MilestoneEvent{*highestGoal}, notable...)
+	}
+
+	return notable
+}
+
+func milestoneMail(campaignByID campaign.Campaign, milestoneEvent campaign.MilestoneEvent, recipient user.User) mailer.Mail {
+	message := campaign.MilestoneMessage(campaignByID.Name, milestoneEvent)
+	if recipient.ID == campaignByID.UserID {
+		message = campaign.MilestoneMessage("Your campaign "+campaignByID.Name, milestoneEvent)
+	}
+
+	body := fmt.Sprintf(
+		"<p>Hi %s,</p><p>%s.</p>",
+		html.EscapeString(recipient.Name),
+		html.EscapeString(message),
+	)
+
+	mail := mailer.Mail{
+		To:       recipient.Email,
+		Subject:  message,
+		HTMLBody: body,
+	}
+
+	return mail
+}
@@ -0,0 +1,87 @@
+package money
+
+import (
+	"strconv"
+	"strings"
+)
+
+const DefaultCurrency = "IDR"
+
+// Amounts are always stored in minor units, so IDR 15000 is 15000 and USD 12.50 is 1250
+type Money struct {
+	Amount   int
+	Currency string
+}
+
+var minorUnits = map[string]int{
+	"IDR": 0,
+	"USD": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"SGD": 2,
+	"MYR": 2,
+	"AUD": 2,
+	"JPY": 0,
+}
+
+func New(amount int, currency string) Money {
+	return Money{Amount: amount, Currency: Normalize(currency)}
+}
+
+// Records created before currencies existed have no currency and are IDR
+func Normalize(currency string) string {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return DefaultCurrency
+	}
+
+	return currency
+}
+
+func IsSupported(currency string) bool {
+	_, ok := minorUnits[Normalize(currency)]
+	return ok
+}
+
+func MinorUnits(currency string) int {
+	return minorUnits[Normalize(currency)]
+}
+
+func Format(money Money) string {
+	units := MinorUnits(money.Currency)
+
+	digits := strconv.Itoa(money.Amount)
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign = "-"
+		digits = digits[1:]
+	}
+
+	fraction := ""
+	if units > 0 {
+		for len(digits) <= units {
+			digits = "0" + digits
+		}
+		fraction = "," + digits[len(digits)-units:]
+		digits = digits[:len(digits)-units]
+	}
+
+	var grouped strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			grouped.WriteByte('.')
+		}
+		grouped.WriteRune(digit)
+	}
+
+	return Normalize(money.Currency) + " " + sign + grouped.String() + fraction
+}
+
+// Share returns the part of value that belongs to part out of whole, used to split refunds across currencies
+func Share(value int, part int, whole int) int {
+	if whole == 0 {
+		return 0
+	}
+
+	return int(int64(value) * int64(part) / int64(whole))
+}
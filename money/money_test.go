@@ -0,0 +1,62 @@
+package money
+
+import "testing"
+
+func TestShare(t *testing.T) {
+	testCases := []struct {
+		value    int
+		part     int
+		whole    int
+		expected int
+	}{
+		{100000, 1, 3, 33333},
+		{100000, 2, 3, 66666},
+		{100000, 3, 3, 100000},
+		{615, 50000, 100000, 307},
+		{-100, 1, 3, -33},
+		{100000, 1, 0, 0},
+		{2000000000, 1500000000, 2000000000, 1500000000},
+	}
+
+	for _, testCase := range testCases {
+		share := Share(testCase.value, testCase.part, testCase.whole)
+		if share != testCase.expected {
+			t.Errorf("Share(%d, %d, %d) = %d, expected %d", testCase.value, testCase.part, testCase.whole, share, testCase.expected)
+		}
+	}
+}
+
+// Refunds take the difference of cumulative shares, so the parts always add up to the whole value
+func TestShareSplitsAddUp(t *testing.T) {
+	value := 100001
+	whole := 7
+
+	total := 0
+	for part := 1; part <= whole; part++ {
+		total = total + Share(value, part, whole) - Share(value, part-1, whole)
+	}
+
+	if total != value {
+		t.Errorf("split shares add up to %d, expected %d", total, value)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	testCases := []struct {
+		money    Money
+		expected string
+	}{
+		{New(1500000, "IDR"), "IDR 1.500.000"},
+		{New(1250, "usd"), "USD 12,50"},
+		{New(5, "USD"), "USD 0,05"},
+		{New(-123456, "USD"), "USD -1.234,56"},
+		{New(980, ""), "IDR 980"},
+	}
+
+	for _, testCase := range testCases {
+		formatted := Format(testCase.money)
+		if formatted != testCase.expected {
+			t.Errorf("Format(%+v) = %q, expected %q", testCase.money, formatted, testCase.expected)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+type RateSource interface {
+	//Rate returns how many units of to one unit of from is worth
+	Rate(from string, to string) (*big.Rat, error)
+}
+
+type staticRateSource struct {
+	rates map[string]*big.Rat
+}
+
+type rateFile struct {
+	Base  string            `json:"base"`
+	Rates map[string]string `json:"rates"`
+}
+
+// The file lists the value of one base unit in every other currency, e.g. {"base": "USD", "rates": {"IDR": "16250"}}
+func NewStaticRateSource(path string) (*staticRateSource, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file rateFile
+	err = json.Unmarshal(content, &file)
+	if err != nil {
+		return nil, err
+	}
+
+	if file.Base == "" {
+		return nil, errors.New("exchange rate file has no base currency")
+	}
+
+	rates := map[string]*big.Rat{Normalize(file.Base): big.NewRat(1, 1)}
+	for currency, value := range file.Rates {
+		rate, ok := new(big.Rat).SetString(value)
+		if !ok || rate.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid exchange rate %q for %s", value, currency)
+		}
+
+		rates[Normalize(currency)] = rate
+	}
+
+	return &staticRateSource{rates}, nil
+}
+
+func (source *staticRateSource) Rate(from string, to string) (*big.Rat, error) {
+	fromRate, ok := source.rates[Normalize(from)]
+	if !ok {
+		return nil, fmt.Errorf("no exchange rate for %s", Normalize(from))
+	}
+
+	toRate, ok := source.rates[Normalize(to)]
+	if !ok {
+		return nil, fmt.Errorf("no exchange rate for %s", Normalize(to))
+	}
+
+	return new(big.Rat).Quo(toRate, fromRate), nil
+}
+
+func Convert(money Money, to string, rates RateSource) (Money, error) {
+	from := Normalize(money.Currency)
+	to = Normalize(to)
+
+	if !IsSupported(from) || !IsSupported(to) {
+		return Money{}, fmt.Errorf("currency %s to %s is not supported", from, to)
+	}
+
+	if from == to {
+		return New(money.Amount, to), nil
+	}
+
+	rate, err := rates.Rate(from, to)
+	if err != nil {
+		return Money{}, err
+	}
+
+	//Both sides are in minor units, so the difference in decimals has to be applied as well
+	value := new(big.Rat).Mul(big.NewRat(int64(money.Amount), 1), rate)
+	value.Mul(value, new(big.Rat).SetInt(pow10(MinorUnits(to))))
+	value.Quo(value, new(big.Rat).SetInt(pow10(MinorUnits(from))))
+
+	return New(int(round(value)), to), nil
+}
+
+func pow10(exponent int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil)
+}
+
+// Halves are rounded away from zero
+func round(value *big.Rat) int64 {
+	numerator := new(big.Int).Abs(value.Num())
+	denominator := value.Denom()
+
+	quotient, remainder := new(big.Int).QuoRem(numerator, denominator, new(big.Int))
+	if new(big.Int).Mul(remainder, big.NewInt(2)).Cmp(denominator) >= 0 {
+		quotient.Add(quotient, big.NewInt(1))
+	}
+
+	if value.Sign() < 0 {
+		quotient.Neg(quotient)
+	}
+
+	return quotient.Int64()
+}
@@ -0,0 +1,80 @@
+package money
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	rates := &staticRateSource{rates: map[string]*big.Rat{
+		"USD": big.NewRat(1, 1),
+		"IDR": big.NewRat(16250, 1),
+		"EUR": big.NewRat(1, 2),
+		"JPY": big.NewRat(303, 2),
+	}}
+
+	testCases := []struct {
+		name      string
+		money     Money
+		to        string
+		expected  Money
+		expectErr bool
+	}{
+		{"to more decimals", New(1250, "USD"), "IDR", New(203125, "IDR"), false},
+		{"to fewer decimals", New(100000, "IDR"), "usd", New(615, "USD"), false},
+		{"half rounds up", New(1, "USD"), "EUR", New(1, "EUR"), false},
+		{"negative half rounds down", New(-1, "USD"), "EUR", New(-1, "EUR"), false},
+		{"above half rounds up", New(1, "USD"), "JPY", New(2, "JPY"), false},
+		{"same currency", New(15000, ""), "IDR", New(15000, "IDR"), false},
+		{"unsupported currency", New(100, "XAU"), "IDR", Money{}, true},
+		{"missing rate", New(100, "GBP"), "IDR", Money{}, true},
+	}
+
+	for _, testCase := range testCases {
+		converted, err := Convert(testCase.money, testCase.to, rates)
+		if (err != nil) != testCase.expectErr {
+			t.Errorf("%s: Convert() returned %v, expected an error: %t", testCase.name, err, testCase.expectErr)
+			continue
+		}
+
+		if converted != testCase.expected {
+			t.Errorf("%s: Convert() = %+v, expected %+v", testCase.name, converted, testCase.expected)
+		}
+	}
+}
+
+func TestNewStaticRateSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+
+	err := os.WriteFile(path, []byte(`{"base": "usd", "rates": {"IDR": "16250", "EUR": "0.92"}}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rates, err := NewStaticRateSource(path)
+	if err != nil {
+		t.Fatalf("NewStaticRateSource() returned %v", err)
+	}
+
+	rate, err := rates.Rate("EUR", "IDR")
+	if err != nil {
+		t.Fatalf("Rate() returned %v", err)
+	}
+
+	expected := new(big.Rat).Quo(big.NewRat(16250, 1), big.NewRat(92, 100))
+	if rate.Cmp(expected) != 0 {
+		t.Errorf("Rate(EUR, IDR) = %s, expected %s", rate, expected)
+	}
+
+	err = os.WriteFile(path, []byte(`{"base": "USD", "rates": {"IDR": "-1"}}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = NewStaticRateSource(path)
+	if err == nil {
+		t.Errorf("NewStaticRateSource() accepted a negative rate")
+	}
+}
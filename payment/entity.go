@@ -1,6 +1,26 @@
 package payment
 
+// Currency is the only currency the payment provider charges in
+const Currency = "IDR"
+
 type Transaction struct {
-	ID     int
+	ID       int
+	Code     string
+	Amount   int
+	SaveCard bool
+}
+
+type TransactionStatus struct {
+	OrderID           string
+	StatusCode        string
+	TransactionStatus string
+	PaymentType       string
+	FraudStatus       string
+	SavedTokenID      string
+}
+
+type Refund struct {
+	Key    string
 	Amount int
+	Reason string
 }
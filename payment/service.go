@@ -1,10 +1,10 @@
 package payment
 
 import (
+	"errors"
 	"os"
 	"rocketship/campaign"
 	"rocketship/user"
-	"strconv"
 
 	"github.com/veritrans/go-midtrans"
 )
@@ -15,18 +15,28 @@ type service struct {
 
 type Service interface {
 	GetPaymentURL(transaction Transaction, user user.User) (string, error)
+	GetTransactionStatus(orderID string) (TransactionStatus, error)
+	RefundTransaction(orderID string, refund Refund) error
+	ExpireTransaction(orderID string) error
+	ChargeSavedCard(transaction Transaction, savedTokenID string) (TransactionStatus, error)
 }
 
 func NewPaymentService(campaignRepository campaign.Repository) *service {
 	return &service{campaignRepository}
 }
 
-func (service *service) GetPaymentURL(transaction Transaction, user user.User) (string, error) {
+func newMidtransClient() midtrans.Client {
 	midclient := midtrans.NewClient()
 	midclient.ServerKey = os.Getenv("SERVER_KEY")
 	midclient.ClientKey = os.Getenv("CLIENT_KEY")
 	midclient.APIEnvType = midtrans.Sandbox
 
+	return midclient
+}
+
+func (service *service) GetPaymentURL(transaction Transaction, user user.User) (string, error) {
+	midclient := newMidtransClient()
+
 	snapGateway := midtrans.SnapGateway{
 		Client: midclient,
 	}
@@ -37,11 +47,19 @@ func (service *service) GetPaymentURL(transaction Transaction, user user.User) (
 			FName: user.Name,
 		},
 		TransactionDetails: midtrans.TransactionDetails{
-			OrderID:  strconv.Itoa(transaction.ID),
+			OrderID:  transaction.Code,
 			GrossAmt: int64(transaction.Amount),
 		},
 	}
 
+	//Saved cards let recurring pledges be charged without the backer
+	if transaction.SaveCard {
+		snapReq.CreditCard = &midtrans.CreditCardDetail{
+			Secure:   true,
+			SaveCard: true,
+		}
+	}
+
 	snapTokenResponse, err := snapGateway.GetToken(snapReq)
 	if err != nil {
 		return "", err
@@ -49,3 +67,107 @@ func (service *service) GetPaymentURL(transaction Transaction, user user.User) (
 
 	return snapTokenResponse.RedirectURL, nil
 }
+
+func (service *service) GetTransactionStatus(orderID string) (TransactionStatus, error) {
+	midclient := newMidtransClient()
+
+	coreGateway := midtrans.CoreGateway{
+		Client: midclient,
+	}
+
+	statusResponse, err := coreGateway.Status(orderID)
+	if err != nil {
+		return TransactionStatus{}, err
+	}
+
+	transactionStatus := TransactionStatus{
+		OrderID:           orderID,
+		StatusCode:        statusResponse.StatusCode,
+		TransactionStatus: statusResponse.TransactionStatus,
+		PaymentType:       statusResponse.PaymentType,
+		FraudStatus:       statusResponse.FraudStatus,
+		SavedTokenID:      statusResponse.SavedCardToken,
+	}
+
+	return transactionStatus, nil
+}
+
+func (service *service) RefundTransaction(orderID string, refund Refund) error {
+	midclient := newMidtransClient()
+
+	coreGateway := midtrans.CoreGateway{
+		Client: midclient,
+	}
+
+	refundReq := &midtrans.RefundReq{
+		RefundKey: refund.Key,
+		Amount:    int64(refund.Amount),
+		Reason:    refund.Reason,
+	}
+
+	refundResponse, err := coreGateway.Refund(orderID, refundReq)
+	if err != nil {
+		return err
+	}
+
+	if refundResponse.StatusCode != "200" {
+		return errors.New(refundResponse.StatusMessage)
+	}
+
+	return nil
+}
+
+// ExpireTransaction closes a pending order so it can no longer be paid, orders the provider never saw need nothing
+func (service *service) ExpireTransaction(orderID string) error {
+	midclient := newMidtransClient()
+
+	coreGateway := midtrans.CoreGateway{
+		Client: midclient,
+	}
+
+	expireResponse, err := coreGateway.Expire(orderID)
+	if err != nil {
+		return err
+	}
+
+	if expireResponse.StatusCode != "407" && expireResponse.StatusCode != "404" {
+		return errors.New(expireResponse.StatusMessage)
+	}
+
+	return nil
+}
+
+func (service *service) ChargeSavedCard(transaction Transaction, savedTokenID string) (TransactionStatus, error) {
+	midclient := newMidtransClient()
+
+	coreGateway := midtrans.CoreGateway{
+		Client: midclient,
+	}
+
+	chargeReq := &midtrans.ChargeReq{
+		PaymentType: midtrans.SourceCreditCard,
+		TransactionDetails: midtrans.TransactionDetails{
+			OrderID:  transaction.Code,
+			GrossAmt: int64(transaction.Amount),
+		},
+		CreditCard: &midtrans.CreditCardDetail{
+			TokenID: savedTokenID,
+		},
+	}
+
+	chargeResponse, err := coreGateway.Charge(chargeReq)
+	if err != nil {
+		return TransactionStatus{}, err
+	}
+
+	transactionStatus := TransactionStatus{
+		OrderID:           transaction.Code,
+		StatusCode:        chargeResponse.StatusCode,
+		TransactionStatus: chargeResponse.TransactionStatus,
+		PaymentType:       chargeResponse.PaymentType,
+		FraudStatus:       chargeResponse.FraudStatus,
+		SavedTokenID:      chargeResponse.SavedCardToken,
+	}
+
+	return transactionStatus, nil
+}
@@ -0,0 +1,43 @@
+package payout
+
+import (
+	"rocketship/campaign"
+	"time"
+)
+
+type BankAccount struct {
+	ID            int
+	UserID        int
+	BankName      string
+	AccountNumber string
+	AccountName   string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+type Payout struct {
+	ID            int
+	CampaignID    int
+	UserID        int
+	Amount        int
+	Status        string
+	BankName      string
+	AccountNumber string
+	AccountName   string
+	Note          string
+	ReviewedBy    int
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	Campaign      campaign.Campaign
+}
+
+type Balance struct {
+	CampaignID int
+	Raised     int
+	Refunded   int
+	Fees       int
+	Net        int
+	Pending    int
+	PaidOut    int
+	Available  int
+}
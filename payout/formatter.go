@@ -0,0 +1,86 @@
+package payout
+
+import "time"
+
+type BankAccountFormatter struct {
+	BankName      string `json:"bank_name"`
+	AccountNumber string `json:"account_number"`
+	AccountName   string `json:"account_name"`
+}
+
+type PayoutFormatter struct {
+	ID            int       `json:"id"`
+	CampaignID    int       `json:"campaign_id"`
+	Amount        int       `json:"amount"`
+	Status        string    `json:"status"`
+	BankName      string    `json:"bank_name"`
+	AccountNumber string    `json:"account_number"`
+	AccountName   string    `json:"account_name"`
+	Note          string    `json:"note"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type BalanceFormatter struct {
+	CampaignID int `json:"campaign_id"`
+	Raised     int `json:"raised"`
+	Refunded   int `json:"refunded"`
+	Fees       int `json:"fees"`
+	Net        int `json:"net"`
+	Pending    int `json:"pending"`
+	PaidOut    int `json:"paid_out"`
+	Available  int `json:"available"`
+}
+
+func FormatBankAccount(bankAccount BankAccount) BankAccountFormatter {
+	formatter := BankAccountFormatter{
+		BankName:      bankAccount.BankName,
+		AccountNumber: bankAccount.AccountNumber,
+		AccountName:   bankAccount.AccountName,
+	}
+
+	return formatter
+}
+
+func FormatPayout(payout Payout) PayoutFormatter {
+	formatter := PayoutFormatter{
+		ID:            payout.ID,
+		CampaignID:    payout.CampaignID,
+		Amount:        payout.Amount,
+		Status:        payout.Status,
+		BankName:      payout.BankName,
+		AccountNumber: payout.AccountNumber,
+		AccountName:   payout.AccountName,
+		Note:          payout.Note,
+		CreatedAt:     payout.CreatedAt,
+		UpdatedAt:     payout.UpdatedAt,
+	}
+
+	return formatter
+}
+
+func FormatPayoutList(payoutList []Payout) []PayoutFormatter {
+	formatterList := []PayoutFormatter{}
+
+	for _, payout := range payoutList {
+		formatter := FormatPayout(payout)
+		formatterList = append(formatterList, formatter)
+	}
+
+	return formatterList
+}
+
+func FormatBalance(balance Balance) BalanceFormatter {
+	formatter := BalanceFormatter{
+		CampaignID: balance.CampaignID,
+		Raised:     balance.Raised,
+		Refunded:   balance.Refunded,
+		Fees:       balance.Fees,
+		Net:        balance.Net,
+		Pending:    balance.Pending,
+		PaidOut:    balance.PaidOut,
+		Available:  balance.Available,
+	}
+
+	return formatter
+}
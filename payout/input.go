@@ -0,0 +1,30 @@
+package payout
+
+import "rocketship/user"
+
+type SaveBankAccountInput struct {
+	BankName      string `json:"bank_name" binding:"required"`
+	AccountNumber string `json:"account_number" binding:"required,numeric"`
+	AccountName   string `json:"account_name" binding:"required"`
+	User          user.User
+}
+
+type CampaignPayoutInput struct {
+	ID   int `uri:"id" binding:"required"`
+	User user.User
+}
+
+type RequestPayoutInput struct {
+	Amount int `json:"amount" binding:"required,gt=0"`
+	User   user.User
+}
+
+type PayoutDetailInput struct {
+	ID   int `uri:"id" binding:"required"`
+	User user.User
+}
+
+type ReviewPayoutInput struct {
+	Note string `json:"note"`
+	User user.User
+}
@@ -0,0 +1,159 @@
+package payout
+
+import (
+	"rocketship/campaign"
+	"rocketship/ledger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository interface {
+	FindBankAccountByUserID(userID int) (BankAccount, error)
+	SaveBankAccount(bankAccount BankAccount) (BankAccount, error)
+	FindPayoutByID(ID int) (Payout, error)
+	FindPayoutByCampaignID(campaignID int) ([]Payout, error)
+	FindPayoutByStatus(status string) ([]Payout, error)
+	SumPendingRefundAmount(campaignID int) (int, error)
+	CreatePayout(campaignID int, request func() (Payout, error)) (Payout, error)
+	UpdatePayoutStatus(ID int, apply func(payout Payout) (Payout, []ledger.JournalEntry, error)) (Payout, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{db}
+}
+
+func (repo *repository) FindBankAccountByUserID(userID int) (BankAccount, error) {
+	var bankAccount BankAccount
+
+	err := repo.db.Where("user_id = ?", userID).Find(&bankAccount).Error
+	if err != nil {
+		return bankAccount, err
+	}
+
+	return bankAccount, nil
+}
+
+func (repo *repository) SaveBankAccount(bankAccount BankAccount) (BankAccount, error) {
+	err := repo.db.Save(&bankAccount).Error
+
+	if err != nil {
+		return bankAccount, err
+	}
+
+	return bankAccount, nil
+}
+
+func (repo *repository) FindPayoutByID(ID int) (Payout, error) {
+	var payout Payout
+
+	err := repo.db.Where("id = ?", ID).Find(&payout).Error
+	if err != nil {
+		return payout, err
+	}
+
+	return payout, nil
+}
+
+func (repo *repository) FindPayoutByCampaignID(campaignID int) ([]Payout, error) {
+	var payoutList []Payout
+
+	err := repo.db.Where("campaign_id = ?", campaignID).Order("created_at desc").Find(&payoutList).Error
+	if err != nil {
+		return payoutList, err
+	}
+
+	return payoutList, nil
+}
+
+func (repo *repository) FindPayoutByStatus(status string) ([]Payout, error) {
+	var payoutList []Payout
+
+	query := repo.db.Preload("Campaign")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	err := query.Order("created_at asc").Find(&payoutList).Error
+	if err != nil {
+		return payoutList, err
+	}
+
+	return payoutList, nil
+}
+
+// Refunds live in the transaction package, which depends on payouts, so they are read from their table directly
+func (repo *repository) SumPendingRefundAmount(campaignID int) (int, error) {
+	var amount int
+
+	err := repo.db.Table("refunds").
+		Select("COALESCE(SUM(refunds.campaign_amount), 0)").
+		Joins("JOIN transactions ON transactions.id = refunds.transaction_id").
+		Where("transactions.campaign_id = ? AND refunds.status = ?", campaignID, "pending").
+		Scan(&amount).Error
+	if err != nil {
+		return amount, err
+	}
+
+	return amount, nil
+}
+
+func (repo *repository) CreatePayout(campaignID int, request func() (Payout, error)) (Payout, error) {
+	var payout Payout
+
+	//The campaign row stays locked until the payout is saved, so concurrent requests never spend the same balance twice
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&campaign.Campaign{}, campaignID).Error
+		if err != nil {
+			return err
+		}
+
+		payout, err = request()
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(&payout).Error
+	})
+
+	if err != nil {
+		return payout, err
+	}
+
+	return payout, nil
+}
+
+func (repo *repository) UpdatePayoutStatus(ID int, apply func(payout Payout) (Payout, []ledger.JournalEntry, error)) (Payout, error) {
+	var payout Payout
+
+	//The row stays locked until the status and its journal entry are saved, so two reviews never both pass the status check
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", ID).Find(&payout).Error
+		if err != nil {
+			return err
+		}
+
+		var entryList []ledger.JournalEntry
+		payout, entryList, err = apply(payout)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Save(&payout).Error
+		if err != nil {
+			return err
+		}
+
+		return ledger.CreateEntries(tx, entryList)
+	})
+
+	if err != nil {
+		return payout, err
+	}
+
+	return payout, nil
+}
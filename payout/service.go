@@ -0,0 +1,267 @@
+package payout
+
+import (
+	"errors"
+	"fmt"
+	"rocketship/campaign"
+	"rocketship/ledger"
+	"rocketship/user"
+)
+
+type Service interface {
+	FindBankAccount(userID int) (BankAccount, error)
+	SaveBankAccount(input SaveBankAccountInput) (BankAccount, error)
+	FindBalance(input CampaignPayoutInput) (Balance, error)
+	FindCampaignBalance(campaignID int) (Balance, error)
+	FindPayoutByCampaignID(input CampaignPayoutInput) ([]Payout, error)
+	RequestPayout(campaignInput CampaignPayoutInput, input RequestPayoutInput) (Payout, error)
+	FindPayoutByStatus(status string, currentUser user.User) ([]Payout, error)
+	ApprovePayout(payoutID PayoutDetailInput, input ReviewPayoutInput) (Payout, error)
+	RejectPayout(payoutID PayoutDetailInput, input ReviewPayoutInput) (Payout, error)
+	CompletePayout(payoutID PayoutDetailInput, input ReviewPayoutInput) (Payout, error)
+	BackfillLedger() error
+}
+
+type service struct {
+	repository    Repository
+	campaign      campaign.Repository
+	ledgerService ledger.Service
+}
+
+func NewService(repository Repository, campaignRepository campaign.Repository, ledgerService ledger.Service) *service {
+	return &service{repository, campaignRepository, ledgerService}
+}
+
+func (service *service) FindBankAccount(userID int) (BankAccount, error) {
+	bankAccount, err := service.repository.FindBankAccountByUserID(userID)
+	if err != nil {
+		return bankAccount, err
+	}
+
+	if bankAccount.ID == 0 {
+		return bankAccount, errors.New("no bank account has been saved yet")
+	}
+
+	return bankAccount, nil
+}
+
+func (service *service) SaveBankAccount(input SaveBankAccountInput) (BankAccount, error) {
+	bankAccount, err := service.repository.FindBankAccountByUserID(input.User.ID)
+	if err != nil {
+		return bankAccount, err
+	}
+
+	bankAccount.UserID = input.User.ID
+	bankAccount.BankName = input.BankName
+	bankAccount.AccountNumber = input.AccountNumber
+	bankAccount.AccountName = input.AccountName
+
+	savedBankAccount, err := service.repository.SaveBankAccount(bankAccount)
+	if err != nil {
+		return savedBankAccount, err
+	}
+
+	return savedBankAccount, nil
+}
+
+func (service *service) FindBalance(input CampaignPayoutInput) (Balance, error) {
+	err := service.checkCampaignOwner(input)
+	if err != nil {
+		return Balance{}, err
+	}
+
+	return service.computeBalance(input.ID)
+}
+
+// FindCampaignBalance skips the owner check, it is meant for other services guarding the balance
+func (service *service) FindCampaignBalance(campaignID int) (Balance, error) {
+	return service.computeBalance(campaignID)
+}
+
+func (service *service) FindPayoutByCampaignID(input CampaignPayoutInput) ([]Payout, error) {
+	err := service.checkCampaignOwner(input)
+	if err != nil {
+		return []Payout{}, err
+	}
+
+	payoutList, err := service.repository.FindPayoutByCampaignID(input.ID)
+	if err != nil {
+		return payoutList, err
+	}
+
+	return payoutList, nil
+}
+
+func (service *service) RequestPayout(campaignInput CampaignPayoutInput, input RequestPayoutInput) (Payout, error) {
+	err := service.checkCampaignOwner(campaignInput)
+	if err != nil {
+		return Payout{}, err
+	}
+
+	bankAccount, err := service.FindBankAccount(input.User.ID)
+	if err != nil {
+		return Payout{}, err
+	}
+
+	//The balance is checked while the campaign is locked, refunds take the same lock
+	newPayout, err := service.repository.CreatePayout(campaignInput.ID, func() (Payout, error) {
+		balance, err := service.computeBalance(campaignInput.ID)
+		if err != nil {
+			return Payout{}, err
+		}
+
+		if input.Amount > balance.Available {
+			return Payout{}, fmt.Errorf("payout amount must not exceed the available balance of %d", balance.Available)
+		}
+
+		//Bank details are copied so later changes to the account do not alter requested payouts
+		payout := Payout{
+			CampaignID:    campaignInput.ID,
+			UserID:        input.User.ID,
+			Amount:        input.Amount,
+			Status:        "requested",
+			BankName:      bankAccount.BankName,
+			AccountNumber: bankAccount.AccountNumber,
+			AccountName:   bankAccount.AccountName,
+		}
+
+		return payout, nil
+	})
+	if err != nil {
+		return newPayout, err
+	}
+
+	return newPayout, nil
+}
+
+func (service *service) FindPayoutByStatus(status string, currentUser user.User) ([]Payout, error) {
+	if currentUser.Role != "admin" {
+		return []Payout{}, errors.New("could not find payouts due to lack of credentials")
+	}
+
+	payoutList, err := service.repository.FindPayoutByStatus(status)
+	if err != nil {
+		return payoutList, err
+	}
+
+	return payoutList, nil
+}
+
+func (service *service) ApprovePayout(payoutID PayoutDetailInput, input ReviewPayoutInput) (Payout, error) {
+	return service.reviewPayout(payoutID, input, "requested", "approved")
+}
+
+func (service *service) RejectPayout(payoutID PayoutDetailInput, input ReviewPayoutInput) (Payout, error) {
+	return service.reviewPayout(payoutID, input, "requested", "rejected")
+}
+
+func (service *service) CompletePayout(payoutID PayoutDetailInput, input ReviewPayoutInput) (Payout, error) {
+	return service.reviewPayout(payoutID, input, "approved", "paid")
+}
+
+func (service *service) BackfillLedger() error {
+	payoutList, err := service.repository.FindPayoutByStatus("paid")
+	if err != nil {
+		return err
+	}
+
+	for _, payout := range payoutList {
+		err := service.ledgerService.RecordPayout(payout.CampaignID, payout.ID, payout.Amount)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (service *service) reviewPayout(payoutID PayoutDetailInput, input ReviewPayoutInput, fromStatus string, toStatus string) (Payout, error) {
+	if input.User.Role != "admin" {
+		return Payout{}, errors.New("could not review this payout due to lack of credentials")
+	}
+
+	//The status is checked on the locked row, the one an admin looked at may already be outdated
+	updatedPayout, err := service.repository.UpdatePayoutStatus(payoutID.ID, func(payout Payout) (Payout, []ledger.JournalEntry, error) {
+		if payout.ID == 0 {
+			return payout, nil, errors.New("no payout found with this ID")
+		}
+
+		if payout.Status != fromStatus {
+			return payout, nil, fmt.Errorf("only %s payouts can be %s", fromStatus, toStatus)
+		}
+
+		payout.Status = toStatus
+		payout.ReviewedBy = input.User.ID
+		if input.Note != "" {
+			payout.Note = input.Note
+		}
+
+		//A paid payout leaves the campaign account together with its status change
+		if toStatus != "paid" {
+			return payout, nil, nil
+		}
+
+		entryList, err := service.ledgerService.PayoutEntries(payout.CampaignID, payout.ID, payout.Amount)
+		if err != nil {
+			return payout, nil, err
+		}
+
+		return payout, entryList, nil
+	})
+	if err != nil {
+		return updatedPayout, err
+	}
+
+	return updatedPayout, nil
+}
+
+func (service *service) computeBalance(campaignID int) (Balance, error) {
+	totals, err := service.ledgerService.FindCampaignTotals(campaignID)
+	if err != nil {
+		return Balance{}, err
+	}
+
+	payoutList, err := service.repository.FindPayoutByCampaignID(campaignID)
+	if err != nil {
+		return Balance{}, err
+	}
+
+	//A refund waiting on the provider is only in the ledger once it went through, but the money is already spoken for
+	pendingRefundAmount, err := service.repository.SumPendingRefundAmount(campaignID)
+	if err != nil {
+		return Balance{}, err
+	}
+
+	//Fees are not returned on refunds, so they always count against the balance
+	balance := Balance{
+		CampaignID: campaignID,
+		Raised:     totals.Payments + totals.Matches,
+		Refunded:   totals.Refunds + pendingRefundAmount,
+		Fees:       totals.Fees,
+		PaidOut:    totals.Payouts,
+	}
+
+	for _, payout := range payoutList {
+		if payout.Status == "requested" || payout.Status == "approved" {
+			balance.Pending = balance.Pending + payout.Amount
+		}
+	}
+
+	balance.Net = balance.Raised - balance.Refunded - balance.Fees
+	balance.Available = balance.Net - balance.Pending - balance.PaidOut
+
+	return balance, nil
+}
+
+func (service *service) checkCampaignOwner(input CampaignPayoutInput) error {
+	campaign, err := service.campaign.FindCampaignByID(input.ID)
+	if err != nil {
+		return err
+	}
+
+	if campaign.ID == 0 || campaign.UserID != input.User.ID {
+		return errors.New("could not access this campaign's payouts due to lack of credentials")
+	}
+
+	return nil
+}
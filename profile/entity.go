@@ -0,0 +1,15 @@
+package profile
+
+import (
+	"rocketship/campaign"
+	"rocketship/money"
+	"rocketship/user"
+)
+
+// Profile is assembled from users, campaigns and transactions, it has no table of its own
+type Profile struct {
+	User            user.User
+	Campaigns       []campaign.Campaign
+	TotalRaised     []money.Money
+	BackedCampaigns []campaign.Campaign
+}
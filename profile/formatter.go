@@ -0,0 +1,56 @@
+package profile
+
+import (
+	"rocketship/campaign"
+	"rocketship/imaging"
+	"rocketship/storage"
+	"rocketship/user"
+	"time"
+)
+
+type ProfileFormatter struct {
+	ID              int                          `json:"id"`
+	Name            string                       `json:"name"`
+	Avatar          string                       `json:"avatar"`
+	AvatarVariants  map[string]string            `json:"avatar_variants"`
+	Bio             string                       `json:"bio"`
+	Location        string                       `json:"location"`
+	Links           []string                     `json:"links"`
+	JoinedAt        time.Time                    `json:"joined_at"`
+	CampaignCount   int                          `json:"campaign_count"`
+	Campaigns       []campaign.CampaignFormatter `json:"campaigns"`
+	TotalRaised     []AmountFormatter            `json:"total_raised"`
+	BackedCampaigns []campaign.CampaignFormatter `json:"backed_campaigns"`
+}
+
+type AmountFormatter struct {
+	Currency string `json:"currency"`
+	Amount   int    `json:"amount"`
+}
+
+func FormatProfile(profile Profile, storageService storage.Service) ProfileFormatter {
+	formatter := ProfileFormatter{
+		ID:             profile.User.ID,
+		Name:           profile.User.Name,
+		Avatar:         storage.URL(storageService, profile.User.AvatarFileName),
+		AvatarVariants: imaging.VariantURLs(profile.User.AvatarFileName, profile.User.AvatarVariantStatus, storageService),
+		Bio:            profile.User.Bio,
+		Location:       profile.User.Location,
+		Links:          user.SplitLinks(profile.User.Links),
+		JoinedAt:       profile.User.CreatedAt,
+		CampaignCount:  len(profile.Campaigns),
+		Campaigns:      campaign.FormatCampaigns(profile.Campaigns, storageService),
+		TotalRaised:    []AmountFormatter{},
+	}
+
+	for _, amount := range profile.TotalRaised {
+		formatter.TotalRaised = append(formatter.TotalRaised, AmountFormatter{Currency: amount.Currency, Amount: amount.Amount})
+	}
+
+	//Backed campaigns are left out entirely unless the user opted in
+	if profile.User.ShowBackedCampaigns {
+		formatter.BackedCampaigns = campaign.FormatCampaigns(profile.BackedCampaigns, storageService)
+	}
+
+	return formatter
+}
@@ -0,0 +1,5 @@
+package profile
+
+type ProfileInput struct {
+	ID int `uri:"id" binding:"required"`
+}
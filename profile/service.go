@@ -0,0 +1,76 @@
+package profile
+
+import (
+	"rocketship/campaign"
+	"rocketship/money"
+	"rocketship/transaction"
+	"rocketship/user"
+	"sort"
+)
+
+type Service interface {
+	FindProfile(input ProfileInput) (Profile, error)
+}
+
+type service struct {
+	userService           user.Service
+	campaignRepository    campaign.Repository
+	transactionRepository transaction.Repository
+}
+
+func NewService(userService user.Service, campaignRepository campaign.Repository, transactionRepository transaction.Repository) *service {
+	return &service{userService, campaignRepository, transactionRepository}
+}
+
+func (s *service) FindProfile(input ProfileInput) (Profile, error) {
+	profile := Profile{
+		Campaigns:       []campaign.Campaign{},
+		TotalRaised:     []money.Money{},
+		BackedCampaigns: []campaign.Campaign{},
+	}
+
+	creator, err := s.userService.FindUserByID(input.ID)
+	if err != nil {
+		return profile, err
+	}
+	profile.User = creator
+
+	profile.Campaigns, err = s.campaignRepository.FindCampaignByUserID(creator.ID)
+	if err != nil {
+		return profile, err
+	}
+
+	//Campaigns raise in their own currency, so totals are kept apart instead of converted
+	totals := map[string]int{}
+	for _, createdCampaign := range profile.Campaigns {
+		totals[money.Normalize(createdCampaign.Currency)] += createdCampaign.CurrentAmount
+	}
+	for currency, amount := range totals {
+		profile.TotalRaised = append(profile.TotalRaised, money.New(amount, currency))
+	}
+	sort.Slice(profile.TotalRaised, func(i, j int) bool {
+		return profile.TotalRaised[i].Currency < profile.TotalRaised[j].Currency
+	})
+
+	if !creator.ShowBackedCampaigns {
+		return profile, nil
+	}
+
+	transactions, err := s.transactionRepository.FindTransactionByUserID(creator.ID)
+	if err != nil {
+		return profile, err
+	}
+
+	//Anonymous donations stay anonymous on the profile too
+	backed := map[int]bool{}
+	for _, paidTransaction := range transactions {
+		if paidTransaction.Status != "paid" || paidTransaction.IsAnonymous || backed[paidTransaction.CampaignID] {
+			continue
+		}
+
+		backed[paidTransaction.CampaignID] = true
+		profile.BackedCampaigns = append(profile.BackedCampaigns, paidTransaction.Campaign)
+	}
+
+	return profile, nil
+}
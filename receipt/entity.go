@@ -0,0 +1,39 @@
+package receipt
+
+import (
+	"os"
+	"time"
+)
+
+type Receipt struct {
+	Code         string
+	CampaignName string
+	BackerName   string
+	BackerEmail  string
+	Amount       int
+	Currency     string
+	PaidAt       time.Time
+	Organisation Organisation
+}
+
+type Organisation struct {
+	Name    string
+	Address string
+	Email   string
+	TaxID   string
+}
+
+func NewOrganisationFromEnv() Organisation {
+	organisation := Organisation{
+		Name:    os.Getenv("ORGANISATION_NAME"),
+		Address: os.Getenv("ORGANISATION_ADDRESS"),
+		Email:   os.Getenv("ORGANISATION_EMAIL"),
+		TaxID:   os.Getenv("ORGANISATION_TAX_ID"),
+	}
+
+	if organisation.Name == "" {
+		organisation.Name = "Rocketship"
+	}
+
+	return organisation
+}
@@ -0,0 +1,11 @@
+package receipt
+
+import "rocketship/money"
+
+func formatAmount(receipt Receipt) string {
+	return money.Format(money.New(receipt.Amount, receipt.Currency))
+}
+
+func formatDate(receipt Receipt) string {
+	return receipt.PaidAt.Format("2 January 2006, 15:04 MST")
+}
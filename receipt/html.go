@@ -0,0 +1,47 @@
+package receipt
+
+import (
+	"bytes"
+	"html/template"
+)
+
+var htmlTemplate = template.Must(template.New("receipt").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif; color: #222;">
+	<h2>{{.Organisation.Name}}</h2>
+	{{if .Organisation.Address}}<p>{{.Organisation.Address}}</p>{{end}}
+	{{if .Organisation.TaxID}}<p>Tax ID: {{.Organisation.TaxID}}</p>{{end}}
+	<h3>Donation receipt</h3>
+	<p>Hi {{.BackerName}}, thank you for your support!</p>
+	<table cellpadding="6">
+		<tr><td>Receipt code</td><td><b>{{.Code}}</b></td></tr>
+		<tr><td>Campaign</td><td>{{.CampaignName}}</td></tr>
+		<tr><td>Amount</td><td>{{.FormattedAmount}}</td></tr>
+		<tr><td>Date</td><td>{{.FormattedDate}}</td></tr>
+	</table>
+	{{if .Organisation.Email}}<p>Questions? Contact us at {{.Organisation.Email}}</p>{{end}}
+</body>
+</html>
+`))
+
+type templateData struct {
+	Receipt
+	FormattedAmount string
+	FormattedDate   string
+}
+
+func RenderHTML(receipt Receipt) (string, error) {
+	data := templateData{
+		Receipt:         receipt,
+		FormattedAmount: formatAmount(receipt),
+		FormattedDate:   formatDate(receipt),
+	}
+
+	var body bytes.Buffer
+	err := htmlTemplate.Execute(&body, data)
+	if err != nil {
+		return "", err
+	}
+
+	return body.String(), nil
+}
@@ -0,0 +1,80 @@
+package receipt
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// RenderPDF writes a single-page PDF by hand so receipts need neither a PDF library nor network access
+func RenderPDF(receipt Receipt) ([]byte, error) {
+	lines := []string{
+		"Receipt code: " + receipt.Code,
+		"Campaign: " + receipt.CampaignName,
+		"Backer: " + receipt.BackerName,
+		"Amount: " + formatAmount(receipt),
+		"Date: " + formatDate(receipt),
+	}
+
+	var content bytes.Buffer
+	content.WriteString("BT\n")
+	fmt.Fprintf(&content, "/F1 18 Tf\n50 780 Td\n(%s) Tj\n", escapePDFText(receipt.Organisation.Name))
+	content.WriteString("/F1 10 Tf\n")
+	for _, detail := range []string{receipt.Organisation.Address, receipt.Organisation.TaxID} {
+		if detail != "" {
+			fmt.Fprintf(&content, "0 -16 Td\n(%s) Tj\n", escapePDFText(detail))
+		}
+	}
+	content.WriteString("/F1 14 Tf\n0 -36 Td\n(Donation receipt) Tj\n/F1 11 Tf\n")
+	for _, line := range lines {
+		fmt.Fprintf(&content, "0 -20 Td\n(%s) Tj\n", escapePDFText(line))
+	}
+	content.WriteString("0 -36 Td\n(Thank you for your support!) Tj\nET\n")
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] /Resources << /Font << /F1 4 0 R >> >> /Contents 5 0 R >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+	}
+
+	var document bytes.Buffer
+	document.WriteString("%PDF-1.4\n")
+
+	offsets := []int{}
+	for i, object := range objects {
+		offsets = append(offsets, document.Len())
+		fmt.Fprintf(&document, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xrefOffset := document.Len()
+	fmt.Fprintf(&document, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&document, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&document, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xrefOffset)
+
+	return document.Bytes(), nil
+}
+
+// The built-in Helvetica font only covers Latin-1, anything else is replaced
+func escapePDFText(text string) string {
+	var escaped strings.Builder
+
+	for _, character := range text {
+		switch {
+		case character == '\\' || character == '(' || character == ')':
+			escaped.WriteRune('\\')
+			escaped.WriteRune(character)
+		case character < 32:
+			escaped.WriteByte(' ')
+		case character < 256:
+			escaped.WriteByte(byte(character))
+		default:
+			escaped.WriteByte('?')
+		}
+	}
+
+	return escaped.String()
+}
@@ -0,0 +1,132 @@
+package receipt
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testReceipt = Receipt{
+	Code:         "TRX-0042",
+	CampaignName: "Clean water (phase 2)",
+	BackerName:   "<script>alert(1)</script>",
+	BackerEmail:  "backer@example.com",
+	Amount:       150000,
+	Currency:     "IDR",
+	PaidAt:       time.Date(2024, 3, 9, 14, 30, 0, 0, time.UTC),
+	Organisation: Organisation{
+		Name:    "Rocketship",
+		Address: "Jl. Sudirman 1, Jakarta",
+		Email:   "hello@example.com",
+		TaxID:   "01.234.567.8-901.000",
+	},
+}
+
+func TestRenderHTML(t *testing.T) {
+	body, err := RenderHTML(testReceipt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"<b>TRX-0042</b>",
+		"Clean water (phase 2)",
+		"IDR 150.000",
+		"9 March 2024, 14:30 UTC",
+		"Tax ID: 01.234.567.8-901.000",
+		"hello@example.com",
+		"&lt;script&gt;alert(1)&lt;/script&gt;",
+	}
+	for _, text := range expected {
+		if !strings.Contains(body, text) {
+			t.Errorf("RenderHTML() is missing %q", text)
+		}
+	}
+
+	if strings.Contains(body, "<script>") {
+		t.Error("RenderHTML() did not escape the backer name")
+	}
+}
+
+func TestRenderPDF(t *testing.T) {
+	document, err := RenderPDF(testReceipt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.HasPrefix(document, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(document, []byte("%%EOF\n")) {
+		t.Fatal("RenderPDF() is not framed as a PDF document")
+	}
+
+	expected := []string{
+		"(Receipt code: TRX-0042) Tj",
+		"(Campaign: Clean water \\(phase 2\\)) Tj",
+		"(Amount: IDR 150.000) Tj",
+		"(Date: 9 March 2024, 14:30 UTC) Tj",
+	}
+	for _, text := range expected {
+		if !bytes.Contains(document, []byte(text)) {
+			t.Errorf("RenderPDF() is missing %q", text)
+		}
+	}
+
+	//Every xref entry and startxref have to point at the exact byte offset of what they reference
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(document)
+	if startxref == nil {
+		t.Fatal("RenderPDF() has no startxref")
+	}
+
+	xrefOffset, _ := strconv.Atoi(string(startxref[1]))
+	if !bytes.HasPrefix(document[xrefOffset:], []byte("xref\n")) {
+		t.Fatalf("startxref %d does not point at the xref table", xrefOffset)
+	}
+
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(document[xrefOffset:], -1)
+	if len(entries) != 5 {
+		t.Fatalf("RenderPDF() has %d objects in the xref table, expected 5", len(entries))
+	}
+
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		object := fmt.Sprintf("%d 0 obj\n", i+1)
+		if !bytes.HasPrefix(document[offset:], []byte(object)) {
+			t.Errorf("xref entry %d points at %q, expected %q", i+1, document[offset:offset+len(object)], object)
+		}
+	}
+
+	//The declared stream length has to match the content between stream and endstream
+	stream := regexp.MustCompile(`(?s)<< /Length (\d+) >>\nstream\n(.*)endstream`).FindSubmatch(document)
+	if stream == nil {
+		t.Fatal("RenderPDF() has no content stream")
+	}
+
+	length, _ := strconv.Atoi(string(stream[1]))
+	if length != len(stream[2]) {
+		t.Errorf("stream /Length = %d, expected %d", length, len(stream[2]))
+	}
+}
+
+func TestEscapePDFText(t *testing.T) {
+	testCases := []struct {
+		text     string
+		expected string
+	}{
+		{"plain", "plain"},
+		{"a (b) c", "a \\(b\\) c"},
+		{"back\\slash", "back\\\\slash"},
+		{"line\nbreak", "line break"},
+		{"Zoë", "Zo\xeb"},
+		{"日本", "??"},
+	}
+
+	for _, testCase := range testCases {
+		escaped := escapePDFText(testCase.text)
+		if escaped != testCase.expected {
+			t.Errorf("escapePDFText(%q) = %q, expected %q", testCase.text, escaped, testCase.expected)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type Writer interface {
+	WriteRow(cells []string) error
+	Flush() error
+	Close() error
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+func NewCSVWriter(output io.Writer) *csvWriter {
+	return &csvWriter{csv.NewWriter(output)}
+}
+
+func (writer *csvWriter) WriteRow(cells []string) error {
+	return writer.writer.Write(cells)
+}
+
+func (writer *csvWriter) Flush() error {
+	writer.writer.Flush()
+
+	return writer.writer.Error()
+}
+
+func (writer *csvWriter) Close() error {
+	return writer.Flush()
+}
+
+// xlsxWriter streams a single-sheet workbook, so rows never have to be held in memory
+type xlsxWriter struct {
+	archive *zip.Writer
+	sheet   io.Writer
+	row     int
+}
+
+const contentTypesXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`
+
+const rootRelsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+
+const workbookXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+const workbookRelsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`
+
+func NewXLSXWriter(output io.Writer) (*xlsxWriter, error) {
+	archive := zip.NewWriter(output)
+
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", contentTypesXML},
+		{"_rels/.rels", rootRelsXML},
+		{"xl/workbook.xml", workbookXML},
+		{"xl/_rels/workbook.xml.rels", workbookRelsXML},
+	}
+
+	for _, file := range files {
+		fileWriter, err := archive.Create(file.name)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = io.WriteString(fileWriter, file.content)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	//The sheet has to be the last file, because zip entries are written one after another
+	sheet, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = io.WriteString(sheet, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`+"\n"+`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	if err != nil {
+		return nil, err
+	}
+
+	return &xlsxWriter{archive, sheet, 0}, nil
+}
+
+func (writer *xlsxWriter) WriteRow(cells []string) error {
+	writer.row = writer.row + 1
+
+	_, err := fmt.Fprintf(writer.sheet, `<row r="%d">`, writer.row)
+	if err != nil {
+		return err
+	}
+
+	for column, cell := range cells {
+		reference := columnName(column) + strconv.Itoa(writer.row)
+
+		//Whole numbers are written as numbers so they can be summed in the sheet
+		if _, err := strconv.ParseInt(cell, 10, 64); err == nil {
+			_, err = fmt.Fprintf(writer.sheet, `<c r="%s"><v>%s</v></c>`, reference, cell)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		_, err = fmt.Fprintf(writer.sheet, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, reference)
+		if err != nil {
+			return err
+		}
+
+		err = xml.EscapeText(writer.sheet, []byte(cell))
+		if err != nil {
+			return err
+		}
+
+		_, err = io.WriteString(writer.sheet, `</t></is></c>`)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = io.WriteString(writer.sheet, `</row>`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (writer *xlsxWriter) Flush() error {
+	return writer.archive.Flush()
+}
+
+func (writer *xlsxWriter) Close() error {
+	_, err := io.WriteString(writer.sheet, `</sheetData></worksheet>`)
+	if err != nil {
+		return err
+	}
+
+	return writer.archive.Close()
+}
+
+// EscapeFormula keeps user-entered text from being run as a formula when the export is opened in a spreadsheet app
+func EscapeFormula(value string) string {
+	if value != "" && strings.ContainsAny(value[:1], "=+-@\t\r") {
+		return "'" + value
+	}
+
+	return value
+}
+
+// columnName turns a zero-based column index into its spreadsheet letters, e.g. 0 is A and 27 is AB
+func columnName(column int) string {
+	name := ""
+
+	for column >= 0 {
+		name = string(rune('A'+column%26)) + name
+		column = column/26 - 1
+	}
+
+	return name
+}
@@ -0,0 +1,27 @@
+package spreadsheet
+
+import "testing"
+
+func TestEscapeFormula(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected string
+	}{
+		{"", ""},
+		{"Jane Doe", "Jane Doe"},
+		{"Gold tier", "Gold tier"},
+		{"=HYPERLINK(\"http://example.com\")", "'=HYPERLINK(\"http://example.com\")"},
+		{"+1234", "'+1234"},
+		{"-5", "'-5"},
+		{"@SUM(A1:A2)", "'@SUM(A1:A2)"},
+		{"\t=1+1", "'\t=1+1"},
+		{"a=b", "a=b"},
+	}
+
+	for _, testCase := range testCases {
+		actual := EscapeFormula(testCase.value)
+		if actual != testCase.expected {
+			t.Errorf("EscapeFormula(%q) = %q, expected %q", testCase.value, actual, testCase.expected)
+		}
+	}
+}
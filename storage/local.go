@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+type localService struct {
+	dir     string
+	baseURL string
+}
+
+// NewLocalService keeps files on disk, which is only suitable for a single instance with a persistent filesystem
+func NewLocalService(dir string, baseURL string) *localService {
+	return &localService{dir, strings.TrimRight(baseURL, "/")}
+}
+
+func (service *localService) Put(key string, content io.Reader, size int64, contentType string) error {
+	filePath, err := service.filePath(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(filePath), 0755)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(file, content)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+func (service *localService) Get(key string) (io.ReadCloser, error) {
+	filePath, err := service.filePath(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.Open(filePath)
+}
+
+func (service *localService) Delete(key string) error {
+	filePath, err := service.filePath(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(filePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+func (service *localService) URL(key string) (string, error) {
+	_, err := service.filePath(key)
+	if err != nil {
+		return "", err
+	}
+
+	return service.baseURL + "/" + key, nil
+}
+
+func (service *localService) Key(rawURL string) (string, bool) {
+	//Files are served by this app, so only the path of an absolute URL matters
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return "", false
+	}
+
+	key := strings.TrimPrefix(parsedURL.Path, service.baseURL+"/")
+	if key == parsedURL.Path {
+		return "", false
+	}
+
+	_, err = service.filePath(key)
+	if err != nil {
+		return "", false
+	}
+
+	return key, true
+}
+
+func (service *localService) filePath(key string) (string, error) {
+	cleanKey := path.Clean("/" + key)[1:]
+	if cleanKey == "" || cleanKey != key {
+		return "", errors.New("invalid storage key")
+	}
+
+	return filepath.Join(service.dir, filepath.FromSlash(cleanKey)), nil
+}
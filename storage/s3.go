@@ -0,0 +1,269 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	//PublicURL is used for buckets that can be read anonymously, otherwise URLs are signed
+	PublicURL string
+	URLExpiry time.Duration
+}
+
+type s3Service struct {
+	config   S3Config
+	endpoint *url.URL
+	client   *http.Client
+}
+
+// NewS3Service talks to any S3-compatible store with path-style requests, so MinIO works as well as AWS
+func NewS3Service(config S3Config) (*s3Service, error) {
+	endpoint, err := url.Parse(strings.TrimRight(config.Endpoint, "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	if endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", config.Endpoint)
+	}
+
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+
+	if config.URLExpiry == 0 {
+		config.URLExpiry = time.Hour
+	}
+
+	config.PublicURL = strings.TrimRight(config.PublicURL, "/")
+
+	return &s3Service{config, endpoint, &http.Client{Timeout: 30 * time.Second}}, nil
+}
+
+func (service *s3Service) Put(key string, content io.Reader, size int64, contentType string) error {
+	body, err := io.ReadAll(content)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest(http.MethodPut, service.objectURL(key), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	request.ContentLength = int64(len(body))
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+
+	_, err = service.do(request, body)
+	return err
+}
+
+func (service *s3Service) Delete(key string) error {
+	request, err := http.NewRequest(http.MethodDelete, service.objectURL(key), nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = service.do(request, nil)
+	return err
+}
+
+func (service *s3Service) Get(key string) (io.ReadCloser, error) {
+	request, err := http.NewRequest(http.MethodGet, service.objectURL(key), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := service.do(request, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
+func (service *s3Service) URL(key string) (string, error) {
+	if service.config.PublicURL != "" {
+		return service.config.PublicURL + "/" + encodePath(key), nil
+	}
+
+	now := time.Now().UTC()
+	objectURL, err := url.Parse(service.objectURL(key))
+	if err != nil {
+		return "", err
+	}
+
+	query := map[string]string{
+		"X-Amz-Algorithm":     "AWS4-HMAC-SHA256",
+		"X-Amz-Credential":    service.config.AccessKey + "/" + service.scope(now),
+		"X-Amz-Date":          now.Format("20060102T150405Z"),
+		"X-Amz-Expires":       fmt.Sprint(int(service.config.URLExpiry.Seconds())),
+		"X-Amz-SignedHeaders": "host",
+	}
+
+	canonicalQuery := canonicalQueryString(query)
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		objectURL.EscapedPath(),
+		canonicalQuery,
+		"host:" + objectURL.Host + "\n",
+		"host",
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+
+	signature := service.sign(now, canonicalRequest)
+
+	return objectURL.String() + "?" + canonicalQuery + "&X-Amz-Signature=" + signature, nil
+}
+
+func (service *s3Service) Key(rawURL string) (string, bool) {
+	//Presigned URLs carry their signature in the query
+	rawURL = strings.SplitN(rawURL, "?", 2)[0]
+
+	prefix := service.objectURL("")
+	if service.config.PublicURL != "" && strings.HasPrefix(rawURL, service.config.PublicURL+"/") {
+		prefix = service.config.PublicURL + "/"
+	}
+
+	if !strings.HasPrefix(rawURL, prefix) {
+		return "", false
+	}
+
+	key, err := url.PathUnescape(strings.TrimPrefix(rawURL, prefix))
+	if err != nil || key == "" {
+		return "", false
+	}
+
+	return key, true
+}
+
+func (service *s3Service) objectURL(key string) string {
+	return service.endpoint.String() + "/" + encodePath(service.config.Bucket) + "/" + encodePath(key)
+}
+
+func (service *s3Service) do(request *http.Request, body []byte) ([]byte, error) {
+	now := time.Now().UTC()
+	payloadHash := sha256Hex(body)
+
+	request.Header.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "host:" + request.URL.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + now.Format("20060102T150405Z") + "\n"
+
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		request.URL.EscapedPath(),
+		"",
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	request.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		service.config.AccessKey,
+		service.scope(now),
+		signedHeaders,
+		service.sign(now, canonicalRequest),
+	))
+
+	response, err := service.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return nil, fmt.Errorf("storage request failed with %s: %s", response.Status, strings.TrimSpace(string(message)))
+	}
+
+	return io.ReadAll(response.Body)
+}
+
+func (service *s3Service) scope(now time.Time) string {
+	return now.Format("20060102") + "/" + service.config.Region + "/s3/aws4_request"
+}
+
+// sign follows AWS signature version 4
+func (service *s3Service) sign(now time.Time, canonicalRequest string) string {
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		now.Format("20060102T150405Z"),
+		service.scope(now),
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+service.config.SecretKey), now.Format("20060102"))
+	key = hmacSHA256(key, service.config.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func canonicalQueryString(query map[string]string) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, encode(key, false)+"="+encode(query[key], false))
+	}
+
+	return strings.Join(pairs, "&")
+}
+
+func encodePath(path string) string {
+	return encode(path, true)
+}
+
+// encode escapes everything except unreserved characters, as the signature requires
+func encode(value string, keepSlash bool) string {
+	var encoded strings.Builder
+
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-' || c == '_' || c == '.' || c == '~' || (keepSlash && c == '/') {
+			encoded.WriteByte(c)
+		} else {
+			fmt.Fprintf(&encoded, "%%%02X", c)
+		}
+	}
+
+	return encoded.String()
+}
@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"io"
+	"log"
+	"strings"
+)
+
+// Keys are relative names such as "avatars/12-photo.jpg", never paths on disk or full URLs
+type Service interface {
+	Put(key string, content io.Reader, size int64, contentType string) error
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+	URL(key string) (string, error)
+	Key(url string) (string, bool)
+}
+
+// URL turns a stored key into something a client can download, a failure only leaves the URL empty
+func URL(service Service, key string) string {
+	if key == "" {
+		return ""
+	}
+
+	if strings.HasPrefix(key, "http://") || strings.HasPrefix(key, "https://") {
+		return key
+	}
+
+	url, err := service.URL(key)
+	if err != nil {
+		log.Println("Failed to resolve storage URL:", err)
+		return ""
+	}
+
+	return url
+}
+
+// KeyFromURL finds the key behind a URL handed out by URL, it also accepts a bare key
+func KeyFromURL(service Service, url string) (string, bool) {
+	if !strings.Contains(url, "://") && !strings.HasPrefix(url, "/") {
+		return url, url != ""
+	}
+
+	return service.Key(url)
+}
@@ -0,0 +1,33 @@
+package subscription
+
+import (
+	"rocketship/campaign"
+	"rocketship/user"
+	"time"
+)
+
+type Subscription struct {
+	ID             int
+	UserID         int
+	CampaignID     int
+	Amount         int
+	Status         string
+	SavedTokenID   string
+	FailedAttempts int
+	NextChargeAt   time.Time
+	//PendingTransactionID is the charge still waiting for the backer or the provider to settle it
+	PendingTransactionID int
+	//ClaimedUntil keeps other scheduler instances away while a charge is in progress
+	ClaimedUntil *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	User         user.User
+	Campaign     campaign.Campaign
+}
+
+type Stats struct {
+	ActiveSubscriptions  int
+	PastDueSubscriptions int
+	PausedSubscriptions  int
+	MRR                  int
+}
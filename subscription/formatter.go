@@ -0,0 +1,56 @@
+package subscription
+
+import "time"
+
+type SubscriptionFormatter struct {
+	ID           int       `json:"id"`
+	CampaignID   int       `json:"campaign_id"`
+	CampaignName string    `json:"campaign_name"`
+	Amount       int       `json:"amount"`
+	Status       string    `json:"status"`
+	NextChargeAt time.Time `json:"next_charge_at"`
+	PaymentURL   string    `json:"payment_url"`
+}
+
+type StatsFormatter struct {
+	ActiveSubscriptions  int `json:"active_subscriptions"`
+	PastDueSubscriptions int `json:"past_due_subscriptions"`
+	PausedSubscriptions  int `json:"paused_subscriptions"`
+	MRR                  int `json:"mrr"`
+}
+
+func FormatSubscription(subscription Subscription, paymentURL string) SubscriptionFormatter {
+	formatter := SubscriptionFormatter{
+		ID:           subscription.ID,
+		CampaignID:   subscription.CampaignID,
+		CampaignName: subscription.Campaign.Name,
+		Amount:       subscription.Amount,
+		Status:       subscription.Status,
+		NextChargeAt: subscription.NextChargeAt,
+		PaymentURL:   paymentURL,
+	}
+
+	return formatter
+}
+
+func FormatSubscriptionList(subscriptionList []Subscription) []SubscriptionFormatter {
+	formatterList := []SubscriptionFormatter{}
+
+	for _, subscription := range subscriptionList {
+		formatter := FormatSubscription(subscription, "")
+		formatterList = append(formatterList, formatter)
+	}
+
+	return formatterList
+}
+
+func FormatStats(stats Stats) StatsFormatter {
+	formatter := StatsFormatter{
+		ActiveSubscriptions:  stats.ActiveSubscriptions,
+		PastDueSubscriptions: stats.PastDueSubscriptions,
+		PausedSubscriptions:  stats.PausedSubscriptions,
+		MRR:                  stats.MRR,
+	}
+
+	return formatter
+}
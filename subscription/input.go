@@ -0,0 +1,14 @@
+package subscription
+
+import "rocketship/user"
+
+type CreateSubscriptionInput struct {
+	CampaignID int `json:"campaign_id" binding:"required"`
+	Amount     int `json:"amount" binding:"required,gt=0"`
+	User       user.User
+}
+
+type SubscriptionDetailInput struct {
+	ID   int `uri:"id" binding:"required"`
+	User user.User
+}
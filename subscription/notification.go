@@ -0,0 +1,61 @@
+package subscription
+
+import (
+	"fmt"
+	"html"
+	"rocketship/mailer"
+	"rocketship/money"
+)
+
+func paymentLinkMail(subscription Subscription, paymentURL string) mailer.Mail {
+	body := fmt.Sprintf(
+		"<p>Hi %s,</p><p>Your monthly pledge of %s to <b>%s</b> is due.</p><p><a href=\"%s\">Complete your payment</a></p>",
+		html.EscapeString(subscription.User.Name),
+		money.Format(money.New(subscription.Amount, subscription.Campaign.Currency)),
+		html.EscapeString(subscription.Campaign.Name),
+		html.EscapeString(paymentURL),
+	)
+
+	mail := mailer.Mail{
+		To:       subscription.User.Email,
+		Subject:  "Your monthly pledge to " + subscription.Campaign.Name + " is due",
+		HTMLBody: body,
+	}
+
+	return mail
+}
+
+func dunningMail(subscription Subscription) mailer.Mail {
+	body := fmt.Sprintf(
+		"<p>Hi %s,</p><p>We could not charge your monthly pledge of %s to <b>%s</b>.</p><p>We will try again on %s. Please make sure your card can be charged.</p>",
+		html.EscapeString(subscription.User.Name),
+		money.Format(money.New(subscription.Amount, subscription.Campaign.Currency)),
+		html.EscapeString(subscription.Campaign.Name),
+		subscription.NextChargeAt.Format("2 January 2006"),
+	)
+
+	mail := mailer.Mail{
+		To:       subscription.User.Email,
+		Subject:  "We could not charge your pledge to " + subscription.Campaign.Name,
+		HTMLBody: body,
+	}
+
+	return mail
+}
+
+func cancelledMail(subscription Subscription) mailer.Mail {
+	body := fmt.Sprintf(
+		"<p>Hi %s,</p><p>After several failed attempts, your monthly pledge of %s to <b>%s</b> has been cancelled.</p><p>You can start a new pledge at any time.</p>",
+		html.EscapeString(subscription.User.Name),
+		money.Format(money.New(subscription.Amount, subscription.Campaign.Currency)),
+		html.EscapeString(subscription.Campaign.Name),
+	)
+
+	mail := mailer.Mail{
+		To:       subscription.User.Email,
+		Subject:  "Your monthly pledge to " + subscription.Campaign.Name + " has been cancelled",
+		HTMLBody: body,
+	}
+
+	return mail
+}
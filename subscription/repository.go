@@ -0,0 +1,159 @@
+package subscription
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository interface {
+	FindSubscriptionByID(ID int) (Subscription, error)
+	FindSubscriptionByUserID(userID int) ([]Subscription, error)
+	FindSubscriptionByCampaignID(campaignID int) ([]Subscription, error)
+	ClaimDueSubscription(dueAt time.Time, claimUntil time.Time) ([]Subscription, error)
+	CreateSubscription(subscription Subscription) (Subscription, error)
+	UpdateSubscription(subscription Subscription) (Subscription, error)
+	UpdateScheduledCharge(subscription Subscription, claimedStatus string) (bool, error)
+	DeleteSubscription(subscription Subscription) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{db}
+}
+
+func (repo *repository) FindSubscriptionByID(ID int) (Subscription, error) {
+	var subscription Subscription
+
+	err := repo.db.Preload("User").Where("id = ?", ID).Find(&subscription).Error
+	if err != nil {
+		return subscription, err
+	}
+
+	return subscription, nil
+}
+
+func (repo *repository) FindSubscriptionByUserID(userID int) ([]Subscription, error) {
+	var subscriptionList []Subscription
+
+	err := repo.db.Preload("Campaign").Where("user_id = ?", userID).Order("created_at desc").Find(&subscriptionList).Error
+	if err != nil {
+		return subscriptionList, err
+	}
+
+	return subscriptionList, nil
+}
+
+func (repo *repository) FindSubscriptionByCampaignID(campaignID int) ([]Subscription, error) {
+	var subscriptionList []Subscription
+
+	err := repo.db.Where("campaign_id = ?", campaignID).Find(&subscriptionList).Error
+	if err != nil {
+		return subscriptionList, err
+	}
+
+	return subscriptionList, nil
+}
+
+func (repo *repository) ClaimDueSubscription(dueAt time.Time, claimUntil time.Time) ([]Subscription, error) {
+	var subscriptionList []Subscription
+	var IDs []int
+
+	//Rows locked by another instance are skipped rather than waited on, and the lease keeps them away once the lock is released
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&Subscription{}).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status IN ?", []string{"incomplete", "active", "past_due"}).
+			Where("next_charge_at <= ? OR pending_transaction_id <> 0", dueAt).
+			Where("claimed_until IS NULL OR claimed_until <= ?", dueAt).
+			Pluck("id", &IDs).Error
+		if err != nil {
+			return err
+		}
+
+		if len(IDs) == 0 {
+			return nil
+		}
+
+		return tx.Model(&Subscription{}).Where("id IN ?", IDs).Update("claimed_until", claimUntil).Error
+	})
+	if err != nil || len(IDs) == 0 {
+		return subscriptionList, err
+	}
+
+	err = repo.db.Preload("User").Preload("Campaign").Where("id IN ?", IDs).Find(&subscriptionList).Error
+	if err != nil {
+		return subscriptionList, err
+	}
+
+	return subscriptionList, nil
+}
+
+func (repo *repository) CreateSubscription(subscription Subscription) (Subscription, error) {
+	err := repo.db.Create(&subscription).Error
+
+	if err != nil {
+		return subscription, err
+	}
+
+	return subscription, nil
+}
+
+func (repo *repository) UpdateSubscription(subscription Subscription) (Subscription, error) {
+	err := repo.db.Save(&subscription).Error
+
+	if err != nil {
+		return subscription, err
+	}
+
+	return subscription, nil
+}
+
+// UpdateScheduledCharge writes back what the scheduler changed. The status only moves on while it is still the one the
+// subscription was claimed with, so a backer pausing or cancelling in the meantime is kept
+func (repo *repository) UpdateScheduledCharge(subscription Subscription, claimedStatus string) (bool, error) {
+	var statusUpdated bool
+
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&Subscription{}).Where("id = ?", subscription.ID).Updates(map[string]interface{}{
+			"saved_token_id":         subscription.SavedTokenID,
+			"next_charge_at":         subscription.NextChargeAt,
+			"pending_transaction_id": subscription.PendingTransactionID,
+			"claimed_until":          subscription.ClaimedUntil,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		result := tx.Model(&Subscription{}).Where("id = ? AND status = ?", subscription.ID, claimedStatus).Updates(map[string]interface{}{
+			"status":          subscription.Status,
+			"failed_attempts": subscription.FailedAttempts,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		statusUpdated = result.RowsAffected > 0
+
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return statusUpdated, nil
+}
+
+func (repo *repository) DeleteSubscription(subscription Subscription) error {
+	err := repo.db.Delete(&subscription).Error
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package subscription
+
+import (
+	"log"
+	"time"
+)
+
+type scheduler struct {
+	service  Service
+	interval time.Duration
+}
+
+func NewScheduler(service Service, interval time.Duration) *scheduler {
+	return &scheduler{service, interval}
+}
+
+func (scheduler *scheduler) Start() {
+	go func() {
+		ticker := time.NewTicker(scheduler.interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			err := scheduler.service.ChargeDueSubscriptions()
+			if err != nil {
+				log.Println("Failed to charge due subscriptions:", err)
+			}
+		}
+	}()
+}
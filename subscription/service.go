@@ -0,0 +1,380 @@
+package subscription
+
+import (
+	"errors"
+	"log"
+	"rocketship/campaign"
+	"rocketship/mailer"
+	"rocketship/transaction"
+	"rocketship/user"
+	"time"
+)
+
+// Failed charges are retried after these delays before the subscription is cancelled
+var retryDelays = []time.Duration{24 * time.Hour, 3 * 24 * time.Hour, 5 * 24 * time.Hour}
+
+// A claimed subscription is left alone by other scheduler instances for this long
+const claimLease = 30 * time.Minute
+
+type Service interface {
+	CreateSubscription(input CreateSubscriptionInput) (Subscription, transaction.Transaction, error)
+	FindSubscriptionByUserID(userID int) ([]Subscription, error)
+	PauseSubscription(input SubscriptionDetailInput) (Subscription, error)
+	ResumeSubscription(input SubscriptionDetailInput) (Subscription, error)
+	CancelSubscription(input SubscriptionDetailInput) (Subscription, error)
+	FindCampaignStats(campaignID int, currentUser user.User) (Stats, error)
+	ChargeDueSubscriptions() error
+}
+
+type service struct {
+	repository            Repository
+	campaign              campaign.Repository
+	transactionRepository transaction.Repository
+	transactionService    transaction.Service
+	mailer                mailer.Service
+}
+
+func NewService(repository Repository, campaignRepository campaign.Repository, transactionRepository transaction.Repository, transactionService transaction.Service, mailerService mailer.Service) *service {
+	return &service{repository, campaignRepository, transactionRepository, transactionService, mailerService}
+}
+
+func (service *service) CreateSubscription(input CreateSubscriptionInput) (Subscription, transaction.Transaction, error) {
+	subscription := Subscription{
+		UserID:       input.User.ID,
+		CampaignID:   input.CampaignID,
+		Amount:       input.Amount,
+		Status:       "incomplete",
+		NextChargeAt: time.Now().AddDate(0, 1, 0),
+	}
+
+	newSubscription, err := service.repository.CreateSubscription(subscription)
+	if err != nil {
+		return newSubscription, transaction.Transaction{}, err
+	}
+
+	//The first pledge is paid through the payment page, which also saves the card for later charges
+	transactionInput := transaction.CreateTransactionInput{
+		Amount:         input.Amount,
+		CampaignID:     input.CampaignID,
+		SubscriptionID: newSubscription.ID,
+		User:           input.User,
+	}
+
+	firstTransaction, err := service.transactionService.CreateTransaction(transactionInput)
+	if err != nil {
+		deleteErr := service.repository.DeleteSubscription(newSubscription)
+		if deleteErr != nil {
+			return newSubscription, firstTransaction, deleteErr
+		}
+
+		return Subscription{}, firstTransaction, err
+	}
+
+	//The subscription only becomes active once the scheduler sees this pledge paid
+	newSubscription.PendingTransactionID = firstTransaction.ID
+
+	newSubscription, err = service.repository.UpdateSubscription(newSubscription)
+	if err != nil {
+		return newSubscription, firstTransaction, err
+	}
+
+	return newSubscription, firstTransaction, nil
+}
+
+func (service *service) FindSubscriptionByUserID(userID int) ([]Subscription, error) {
+	subscriptionList, err := service.repository.FindSubscriptionByUserID(userID)
+	if err != nil {
+		return subscriptionList, err
+	}
+
+	return subscriptionList, nil
+}
+
+func (service *service) PauseSubscription(input SubscriptionDetailInput) (Subscription, error) {
+	subscription, err := service.findOwnSubscription(input)
+	if err != nil {
+		return subscription, err
+	}
+
+	if subscription.Status != "active" && subscription.Status != "past_due" {
+		return subscription, errors.New("only active subscriptions can be paused")
+	}
+
+	subscription.Status = "paused"
+
+	updatedSubscription, err := service.repository.UpdateSubscription(subscription)
+	if err != nil {
+		return updatedSubscription, err
+	}
+
+	return updatedSubscription, nil
+}
+
+func (service *service) ResumeSubscription(input SubscriptionDetailInput) (Subscription, error) {
+	subscription, err := service.findOwnSubscription(input)
+	if err != nil {
+		return subscription, err
+	}
+
+	if subscription.Status != "paused" {
+		return subscription, errors.New("only paused subscriptions can be resumed")
+	}
+
+	subscription.Status = "active"
+	subscription.FailedAttempts = 0
+
+	//Months skipped while paused are not charged retroactively
+	if subscription.NextChargeAt.Before(time.Now()) {
+		subscription.NextChargeAt = time.Now()
+	}
+
+	updatedSubscription, err := service.repository.UpdateSubscription(subscription)
+	if err != nil {
+		return updatedSubscription, err
+	}
+
+	return updatedSubscription, nil
+}
+
+func (service *service) CancelSubscription(input SubscriptionDetailInput) (Subscription, error) {
+	subscription, err := service.findOwnSubscription(input)
+	if err != nil {
+		return subscription, err
+	}
+
+	if subscription.Status == "cancelled" {
+		return subscription, errors.New("this subscription is already cancelled")
+	}
+
+	subscription.Status = "cancelled"
+
+	updatedSubscription, err := service.repository.UpdateSubscription(subscription)
+	if err != nil {
+		return updatedSubscription, err
+	}
+
+	return updatedSubscription, nil
+}
+
+func (service *service) FindCampaignStats(campaignID int, currentUser user.User) (Stats, error) {
+	campaign, err := service.campaign.FindCampaignByID(campaignID)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	if campaign.UserID != currentUser.ID {
+		return Stats{}, errors.New("could not find subscription stats due to lack of credentials")
+	}
+
+	subscriptionList, err := service.repository.FindSubscriptionByCampaignID(campaignID)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	stats := Stats{}
+	for _, subscription := range subscriptionList {
+		switch subscription.Status {
+		case "active":
+			stats.ActiveSubscriptions = stats.ActiveSubscriptions + 1
+			stats.MRR = stats.MRR + subscription.Amount
+		case "past_due":
+			stats.PastDueSubscriptions = stats.PastDueSubscriptions + 1
+			stats.MRR = stats.MRR + subscription.Amount
+		case "paused":
+			stats.PausedSubscriptions = stats.PausedSubscriptions + 1
+		}
+	}
+
+	return stats, nil
+}
+
+func (service *service) ChargeDueSubscriptions() error {
+	now := time.Now()
+
+	subscriptionList, err := service.repository.ClaimDueSubscription(now, now.Add(claimLease))
+	if err != nil {
+		return err
+	}
+
+	//One failing subscription should not hold back the others
+	for _, subscription := range subscriptionList {
+		var err error
+
+		if subscription.PendingTransactionID != 0 {
+			err = service.settleCharge(subscription)
+		} else if subscription.Status != "incomplete" {
+			err = service.chargeSubscription(subscription)
+		}
+
+		if err != nil {
+			log.Printf("Failed to charge subscription %d: %s", subscription.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// settleCharge follows up on a charge that was still pending when it was made
+func (service *service) settleCharge(subscription Subscription) error {
+	pendingTransaction, err := service.transactionRepository.FindTransactionByID(subscription.PendingTransactionID)
+	if err != nil {
+		return err
+	}
+
+	switch pendingTransaction.Status {
+	case "pending":
+		//Checked again once the claim runs out
+		return nil
+	case "paid", "refunded":
+		return service.markCharged(subscription)
+	}
+
+	subscription.PendingTransactionID = 0
+
+	//A first pledge that was never paid leaves nothing to retry
+	if subscription.Status == "incomplete" {
+		subscription.Status = "cancelled"
+		subscription.ClaimedUntil = nil
+
+		_, err := service.repository.UpdateScheduledCharge(subscription, "incomplete")
+		return err
+	}
+
+	//The month was not paid, so it is charged again on the retry schedule
+	return service.handleFailedCharge(subscription, errors.New("subscription charge was not paid"))
+}
+
+func (service *service) markCharged(subscription Subscription) error {
+	claimedStatus := subscription.Status
+
+	subscription.Status = "active"
+	subscription.FailedAttempts = 0
+	subscription.PendingTransactionID = 0
+	subscription.ClaimedUntil = nil
+
+	_, err := service.repository.UpdateScheduledCharge(subscription, claimedStatus)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (service *service) chargeSubscription(subscription Subscription) error {
+	if subscription.SavedTokenID == "" {
+		savedTokenID, err := service.findSavedTokenID(subscription)
+		if err != nil {
+			return err
+		}
+
+		subscription.SavedTokenID = savedTokenID
+	}
+
+	transactionInput := transaction.CreateTransactionInput{
+		Amount:         subscription.Amount,
+		CampaignID:     subscription.CampaignID,
+		SubscriptionID: subscription.ID,
+		User:           subscription.User,
+	}
+
+	var newTransaction transaction.Transaction
+	var chargeErr error
+	if subscription.SavedTokenID != "" {
+		newTransaction, chargeErr = service.transactionService.ChargeSavedCard(transactionInput, subscription.SavedTokenID)
+	} else {
+		//Without a saved card the backer gets a payment link for this month instead
+		newTransaction, chargeErr = service.transactionService.CreateTransaction(transactionInput)
+		if chargeErr == nil {
+			err := service.mailer.Send(paymentLinkMail(subscription, newTransaction.PaymentURL))
+			if err != nil {
+				log.Println("Failed to send subscription payment link:", err)
+			}
+		}
+	}
+
+	if chargeErr != nil {
+		return service.handleFailedCharge(subscription, chargeErr)
+	}
+
+	subscription.NextChargeAt = subscription.NextChargeAt.AddDate(0, 1, 0)
+	if subscription.NextChargeAt.Before(time.Now()) {
+		subscription.NextChargeAt = time.Now().AddDate(0, 1, 0)
+	}
+
+	if newTransaction.Status == "paid" {
+		return service.markCharged(subscription)
+	}
+
+	//Pending card charges and payment links only count once they are paid
+	subscription.PendingTransactionID = newTransaction.ID
+	subscription.ClaimedUntil = nil
+
+	_, err := service.repository.UpdateScheduledCharge(subscription, subscription.Status)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (service *service) handleFailedCharge(subscription Subscription, chargeErr error) error {
+	claimedStatus := subscription.Status
+
+	subscription.FailedAttempts = subscription.FailedAttempts + 1
+
+	var mail mailer.Mail
+	if subscription.FailedAttempts > len(retryDelays) {
+		subscription.Status = "cancelled"
+		mail = cancelledMail(subscription)
+	} else {
+		subscription.Status = "past_due"
+		subscription.NextChargeAt = time.Now().Add(retryDelays[subscription.FailedAttempts-1])
+		mail = dunningMail(subscription)
+	}
+	subscription.ClaimedUntil = nil
+
+	statusUpdated, err := service.repository.UpdateScheduledCharge(subscription, claimedStatus)
+	if err != nil {
+		return err
+	}
+
+	//A backer who paused or cancelled in the meantime is not told about the retry schedule
+	if !statusUpdated {
+		return chargeErr
+	}
+
+	err = service.mailer.Send(mail)
+	if err != nil {
+		log.Println("Failed to send subscription dunning notice:", err)
+	}
+
+	return chargeErr
+}
+
+func (service *service) findSavedTokenID(subscription Subscription) (string, error) {
+	transactionList, err := service.transactionRepository.FindTransactionBySubscriptionID(subscription.ID)
+	if err != nil {
+		return "", err
+	}
+
+	for _, subscriptionTransaction := range transactionList {
+		if subscriptionTransaction.SavedTokenID != "" {
+			return subscriptionTransaction.SavedTokenID, nil
+		}
+	}
+
+	return "", nil
+}
+
+func (service *service) findOwnSubscription(input SubscriptionDetailInput) (Subscription, error) {
+	subscription, err := service.repository.FindSubscriptionByID(input.ID)
+	if err != nil {
+		return subscription, err
+	}
+
+	if subscription.ID == 0 || subscription.UserID != input.User.ID {
+		return Subscription{}, errors.New("no subscription found with this ID")
+	}
+
+	return subscription, nil
+}
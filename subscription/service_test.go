@@ -0,0 +1,77 @@
+package subscription
+
+import (
+	"errors"
+	"rocketship/mailer"
+	"testing"
+)
+
+// fakeRepository keeps the subscription the scheduler writes back, the status only moves while it is the claimed one
+type fakeRepository struct {
+	Repository
+	status string
+	saved  Subscription
+}
+
+func (repo *fakeRepository) UpdateScheduledCharge(subscription Subscription, claimedStatus string) (bool, error) {
+	repo.saved = subscription
+
+	if repo.status != claimedStatus {
+		return false, nil
+	}
+
+	repo.status = subscription.Status
+
+	return true, nil
+}
+
+type fakeMailer struct {
+	sent []mailer.Mail
+}
+
+func (fakeMailer *fakeMailer) Send(mail mailer.Mail) error {
+	fakeMailer.sent = append(fakeMailer.sent, mail)
+	return nil
+}
+
+func TestHandleFailedChargeFollowsTheRetrySchedule(t *testing.T) {
+	testCases := []struct {
+		name           string
+		claimedStatus  string
+		currentStatus  string
+		failedAttempts int
+		expectedStatus string
+		expectMail     bool
+	}{
+		{"first failure", "active", "active", 0, "past_due", true},
+		{"last retry", "past_due", "past_due", len(retryDelays), "cancelled", true},
+		{"paused meanwhile", "active", "paused", 0, "paused", false},
+		{"cancelled meanwhile", "past_due", "cancelled", 1, "cancelled", false},
+	}
+
+	for _, testCase := range testCases {
+		repository := &fakeRepository{status: testCase.currentStatus}
+		mailService := &fakeMailer{}
+		service := NewService(repository, nil, nil, nil, mailService)
+
+		subscription := Subscription{ID: 3, Status: testCase.claimedStatus, FailedAttempts: testCase.failedAttempts}
+		chargeErr := errors.New("card declined")
+
+		err := service.handleFailedCharge(subscription, chargeErr)
+		if err != chargeErr {
+			t.Errorf("%s: handleFailedCharge() returned %v, expected %v", testCase.name, err, chargeErr)
+		}
+
+		if repository.status != testCase.expectedStatus {
+			t.Errorf("%s: status = %q, expected %q", testCase.name, repository.status, testCase.expectedStatus)
+		}
+
+		if repository.saved.FailedAttempts != testCase.failedAttempts+1 || repository.saved.ClaimedUntil != nil {
+			t.Errorf("%s: saved %+v, expected the attempt counted and the claim released", testCase.name, repository.saved)
+		}
+
+		if (len(mailService.sent) > 0) != testCase.expectMail {
+			t.Errorf("%s: sent %d mails, expected a mail: %t", testCase.name, len(mailService.sent), testCase.expectMail)
+		}
+	}
+}
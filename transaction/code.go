@@ -0,0 +1,65 @@
+package transaction
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Ambiguous characters such as 0/O and 1/I are left out so codes can be read over the phone
+const codeAlphabet = "23456789ABCDEFGHJKLMNPQRSTUVWXYZ"
+const codeLength = 8
+
+// codeAttempts bounds the retries after a code collision
+const codeAttempts = 5
+
+func generateCode() (string, error) {
+	code := make([]byte, codeLength)
+
+	for i := range code {
+		index, err := rand.Int(rand.Reader, big.NewInt(int64(len(codeAlphabet))))
+		if err != nil {
+			return "", err
+		}
+
+		code[i] = codeAlphabet[index.Int64()]
+	}
+
+	return fmt.Sprintf("RS-%d-%s", time.Now().Year(), code), nil
+}
+
+// Transactions created before codes existed were sent to the provider with their ID,
+// and every resumed payment needs a fresh order ID because the provider never reuses one
+func providerOrderID(transaction Transaction) string {
+	if transaction.Code == "" {
+		return strconv.Itoa(transaction.ID)
+	}
+
+	if transaction.PaymentAttempt > 0 {
+		return fmt.Sprintf("%s-%d", transaction.Code, transaction.PaymentAttempt)
+	}
+
+	return transaction.Code
+}
+
+// splitOrderID undoes providerOrderID, generated codes have three parts and older codes are a bare ID
+func splitOrderID(orderID string) (string, int) {
+	parts := strings.Split(orderID, "-")
+
+	codeParts := 3
+	if _, err := strconv.Atoi(parts[0]); err == nil {
+		codeParts = 1
+	}
+
+	if len(parts) == codeParts+1 {
+		attempt, err := strconv.Atoi(parts[codeParts])
+		if err == nil {
+			return strings.Join(parts[:codeParts], "-"), attempt
+		}
+	}
+
+	return orderID, 0
+}
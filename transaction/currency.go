@@ -0,0 +1,109 @@
+package transaction
+
+import (
+	"errors"
+	"rocketship/campaign"
+	"rocketship/money"
+	"rocketship/payment"
+)
+
+type donationAmounts struct {
+	Amount         money.Money
+	CampaignAmount int
+	ChargeAmount   int
+	VoucherID      int
+	VoucherAmount  int
+}
+
+// A donation is counted in the campaign currency and charged in the provider currency, both rates are fixed when it is created
+func (service *service) convertDonation(campaign campaign.Campaign, input CreateTransactionInput) (donationAmounts, error) {
+	currency := input.Currency
+	if currency == "" {
+		currency = campaign.Currency
+	}
+
+	if !money.IsSupported(currency) {
+		return donationAmounts{}, errors.New("donation currency is not supported")
+	}
+
+	amount := money.New(input.Amount, currency)
+
+	campaignAmount, err := money.Convert(amount, campaign.Currency, service.rateSource)
+	if err != nil {
+		return donationAmounts{}, err
+	}
+
+	chargeAmount, err := money.Convert(amount, payment.Currency, service.rateSource)
+	if err != nil {
+		return donationAmounts{}, err
+	}
+
+	if campaignAmount.Amount <= 0 || chargeAmount.Amount <= 0 {
+		return donationAmounts{}, errors.New("donation amount is too small to be charged")
+	}
+
+	amounts := donationAmounts{
+		Amount:         amount,
+		CampaignAmount: campaignAmount.Amount,
+		ChargeAmount:   chargeAmount.Amount,
+	}
+
+	return amounts, nil
+}
+
+// A voucher covers part of the pledge in the donation currency, only the rest is charged by the provider
+func (service *service) applyVoucher(campaign campaign.Campaign, code string, amounts donationAmounts) (donationAmounts, error) {
+	voucher, covered, err := service.voucherService.QuoteVoucher(code, campaign.ID, amounts.Amount.Currency, amounts.Amount.Amount)
+	if err != nil {
+		return amounts, err
+	}
+
+	remainder, err := money.Convert(money.New(amounts.Amount.Amount-covered, amounts.Amount.Currency), payment.Currency, service.rateSource)
+	if err != nil {
+		return amounts, err
+	}
+
+	if covered < amounts.Amount.Amount && remainder.Amount <= 0 {
+		return amounts, errors.New("remaining amount is too small to be charged")
+	}
+
+	amounts.VoucherID = voucher.ID
+	amounts.VoucherAmount = covered
+	amounts.ChargeAmount = remainder.Amount
+
+	return amounts, nil
+}
+
+// Transactions made before currencies existed were IDR only, so their amount is used as is
+func campaignAmount(transaction Transaction) int {
+	if transaction.Currency == "" {
+		return transaction.Amount
+	}
+
+	return transaction.CampaignAmount
+}
+
+func chargeAmount(transaction Transaction) int {
+	if transaction.Currency == "" {
+		return transaction.Amount
+	}
+
+	return transaction.ChargeAmount
+}
+
+func refundCampaignAmount(transaction Transaction, refund Refund) int {
+	if transaction.Currency == "" {
+		return refund.Amount
+	}
+
+	return refund.CampaignAmount
+}
+
+// paidAmount is the part of the pledge that went through the provider, only that part can be refunded
+func paidAmount(transaction Transaction) int {
+	return transaction.Amount - transaction.VoucherAmount
+}
+
+func voucherCampaignAmount(transaction Transaction) int {
+	return money.Share(campaignAmount(transaction), transaction.VoucherAmount, transaction.Amount)
+}
@@ -7,15 +7,71 @@ import (
 )
 
 type Transaction struct {
-	ID         int
-	CampaignID int
-	UserID     int
-	Amount     int
-	Status     string
-	Code       string
-	PaymentURL string
-	CreatedAt  time.Time
-	UpdatedAt  time.Time
-	User       user.User
-	Campaign   campaign.Campaign
+	ID              int
+	CampaignID      int
+	UserID          int
+	SubscriptionID  int
+	Amount          int
+	Currency        string
+	CampaignAmount  int
+	ChargeAmount    int
+	VoucherID       int
+	VoucherAmount   int
+	PlatformFee     int
+	ProviderFee     int
+	FeeRuleID       int
+	RewardTier      string
+	Status          string
+	Code            string `gorm:"uniqueIndex;size:32"`
+	PaymentURL      string
+	PaymentType     string
+	PaymentAttempt  int
+	SavedTokenID    string
+	IsAnonymous     bool
+	IsAmountHidden  bool
+	Message         string
+	IsMessageHidden bool
+	IsEmailShared   bool
+	//PaidAt is when the payment came in, SettledAt when the work that follows it was done.
+	//The reconciler finishes paid transactions that have no SettledAt yet
+	PaidAt          *time.Time
+	SettledAt       *time.Time
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	User            user.User
+	Campaign        campaign.Campaign
+	StatusHistories []StatusHistory
+}
+
+type StatusHistory struct {
+	ID            int
+	TransactionID int
+	Status        string
+	Note          string
+	CreatedAt     time.Time
+}
+
+type Discrepancy struct {
+	ID             int
+	TransactionID  int
+	LocalStatus    string
+	ProviderStatus string
+	Amount         int
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	Transaction    Transaction
+}
+
+type Refund struct {
+	ID             int
+	TransactionID  int
+	UserID         int
+	Amount         int
+	CampaignAmount int
+	ChargeAmount   int
+	Reason         string
+	RefundKey      string
+	Status         string `gorm:"size:20;default:completed"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
 }
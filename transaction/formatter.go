@@ -1,27 +1,49 @@
 package transaction
 
-import "time"
+import (
+	"rocketship/money"
+	"rocketship/spreadsheet"
+	"rocketship/storage"
+	"strconv"
+	"time"
+)
 
 type TransactionFormatter struct {
-	ID         int    `json:"id"`
-	Amount     int    `json:"amount"`
-	UserID     int    `json:"user_id"`
-	CampaignID int    `json:"campaign_id"`
-	Status     string `json:"status"`
-	Code       string `json:"code"`
-	PaymentURL string `json:"payment_url"`
+	ID            int    `json:"id"`
+	Amount        int    `json:"amount"`
+	Currency      string `json:"currency"`
+	VoucherAmount int    `json:"voucher_amount"`
+	UserID        int    `json:"user_id"`
+	CampaignID    int    `json:"campaign_id"`
+	Status        string `json:"status"`
+	Code          string `json:"code"`
+	PaymentURL    string `json:"payment_url"`
 }
 
 type CampaignTransactionFormatter struct {
-	ID        int       `json:"id"`
-	Name      string    `json:"name"`
-	Amount    int       `json:"amount"`
-	CreatedAt time.Time `json:"created_at"`
+	ID              int       `json:"id"`
+	Name            string    `json:"name"`
+	Amount          int       `json:"amount"`
+	Currency        string    `json:"currency"`
+	IsAnonymous     bool      `json:"is_anonymous"`
+	Message         string    `json:"message"`
+	IsMessageHidden bool      `json:"is_message_hidden"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type SupporterFormatter struct {
+	Name           string    `json:"name"`
+	Message        string    `json:"message"`
+	Amount         int       `json:"amount"`
+	Currency       string    `json:"currency"`
+	IsAmountHidden bool      `json:"is_amount_hidden"`
+	CreatedAt      time.Time `json:"created_at"`
 }
 
 type UserTransactionFormatter struct {
 	ID        int                   `json:"id"`
 	Amount    int                   `json:"amount"`
+	Currency  string                `json:"currency"`
 	Status    string                `json:"status"`
 	CreatedAt time.Time             `json:"created_at"`
 	Campaign  CampaignInfoFormatter `json:"campaign"`
@@ -34,13 +56,15 @@ type CampaignInfoFormatter struct {
 
 func FormatTransaction(transaction Transaction) TransactionFormatter {
 	formatter := TransactionFormatter{
-		ID:         transaction.ID,
-		CampaignID: transaction.CampaignID,
-		UserID:     transaction.UserID,
-		Amount:     transaction.Amount,
-		Status:     transaction.Status,
-		Code:       transaction.Code,
-		PaymentURL: transaction.PaymentURL,
+		ID:            transaction.ID,
+		CampaignID:    transaction.CampaignID,
+		UserID:        transaction.UserID,
+		Amount:        transaction.Amount,
+		Currency:      money.Normalize(transaction.Currency),
+		VoucherAmount: transaction.VoucherAmount,
+		Status:        transaction.Status,
+		Code:          transaction.Code,
+		PaymentURL:    transaction.PaymentURL,
 	}
 
 	return formatter
@@ -48,10 +72,14 @@ func FormatTransaction(transaction Transaction) TransactionFormatter {
 
 func FormatCampaignTransaction(transaction Transaction) CampaignTransactionFormatter {
 	formatter := CampaignTransactionFormatter{
-		ID:        transaction.ID,
-		Name:      transaction.User.Name,
-		Amount:    transaction.Amount,
-		CreatedAt: transaction.CreatedAt,
+		ID:              transaction.ID,
+		Name:            backerName(transaction),
+		Amount:          transaction.Amount,
+		Currency:        money.Normalize(transaction.Currency),
+		IsAnonymous:     transaction.IsAnonymous,
+		Message:         transaction.Message,
+		IsMessageHidden: transaction.IsMessageHidden,
+		CreatedAt:       transaction.CreatedAt,
 	}
 
 	return formatter
@@ -68,10 +96,93 @@ func FormatCampaignTransactionList(transactionList []Transaction) []CampaignTran
 	return formatterList
 }
 
-func FormatUserTransaction(transaction Transaction) UserTransactionFormatter {
+var ExportHeader = []string{
+	"id",
+	"code",
+	"status",
+	"amount",
+	"currency",
+	"campaign_amount",
+	"platform_fee",
+	"provider_fee",
+	"reward_tier",
+	"backer_name",
+	"backer_email",
+	"payment_type",
+	"created_at",
+	"updated_at",
+}
+
+func FormatExportRow(transaction Transaction) []string {
+	//E-mail addresses are only shared with the owner when the backer agreed to it
+	email := ""
+	if transaction.IsEmailShared && !transaction.IsAnonymous {
+		email = transaction.User.Email
+	}
+
+	row := []string{
+		strconv.Itoa(transaction.ID),
+		transaction.Code,
+		transaction.Status,
+		strconv.Itoa(transaction.Amount),
+		money.Normalize(transaction.Currency),
+		strconv.Itoa(campaignAmount(transaction)),
+		strconv.Itoa(transaction.PlatformFee),
+		strconv.Itoa(transaction.ProviderFee),
+		spreadsheet.EscapeFormula(transaction.RewardTier),
+		spreadsheet.EscapeFormula(backerName(transaction)),
+		spreadsheet.EscapeFormula(email),
+		transaction.PaymentType,
+		transaction.CreatedAt.Format(time.RFC3339),
+		transaction.UpdatedAt.Format(time.RFC3339),
+	}
+
+	return row
+}
+
+func FormatSupporter(transaction Transaction) SupporterFormatter {
+	formatter := SupporterFormatter{
+		Name:           backerName(transaction),
+		IsAmountHidden: transaction.IsAmountHidden,
+		CreatedAt:      transaction.CreatedAt,
+	}
+
+	if !transaction.IsAmountHidden {
+		formatter.Amount = transaction.Amount
+		formatter.Currency = money.Normalize(transaction.Currency)
+	}
+
+	if !transaction.IsMessageHidden {
+		formatter.Message = transaction.Message
+	}
+
+	return formatter
+}
+
+func FormatSupporterList(transactionList []Transaction) []SupporterFormatter {
+	formatterList := []SupporterFormatter{}
+
+	for _, transaction := range transactionList {
+		formatter := FormatSupporter(transaction)
+		formatterList = append(formatterList, formatter)
+	}
+
+	return formatterList
+}
+
+func backerName(transaction Transaction) string {
+	if transaction.IsAnonymous {
+		return "Anonymous"
+	}
+
+	return transaction.User.Name
+}
+
+func FormatUserTransaction(transaction Transaction, storageService storage.Service) UserTransactionFormatter {
 	formatter := UserTransactionFormatter{
 		ID:        transaction.ID,
 		Amount:    transaction.Amount,
+		Currency:  money.Normalize(transaction.Currency),
 		Status:    transaction.Status,
 		CreatedAt: transaction.CreatedAt,
 	}
@@ -80,7 +191,7 @@ func FormatUserTransaction(transaction Transaction) UserTransactionFormatter {
 		Name: transaction.Campaign.Name,
 	}
 	if len(transaction.Campaign.CampaignImages) > 0 {
-		campaignFormatter.ImageURL = transaction.Campaign.CampaignImages[0].FileName
+		campaignFormatter.ImageURL = storage.URL(storageService, transaction.Campaign.CampaignImages[0].FileName)
 	}
 
 	formatter.Campaign = campaignFormatter
@@ -88,13 +199,141 @@ func FormatUserTransaction(transaction Transaction) UserTransactionFormatter {
 	return formatter
 }
 
-func FormatUserTransactionList(transactionList []Transaction) []UserTransactionFormatter {
+func FormatUserTransactionList(transactionList []Transaction, storageService storage.Service) []UserTransactionFormatter {
 	var formatterList []UserTransactionFormatter
 
 	for _, transaction := range transactionList {
-		formatter := FormatUserTransaction(transaction)
+		formatter := FormatUserTransaction(transaction, storageService)
 		formatterList = append(formatterList, formatter)
 	}
 
 	return formatterList
 }
+
+type DiscrepancyFormatter struct {
+	ID             int       `json:"id"`
+	TransactionID  int       `json:"transaction_id"`
+	CampaignID     int       `json:"campaign_id"`
+	Amount         int       `json:"amount"`
+	LocalStatus    string    `json:"local_status"`
+	ProviderStatus string    `json:"provider_status"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func FormatDiscrepancy(discrepancy Discrepancy) DiscrepancyFormatter {
+	formatter := DiscrepancyFormatter{
+		ID:             discrepancy.ID,
+		TransactionID:  discrepancy.TransactionID,
+		CampaignID:     discrepancy.Transaction.CampaignID,
+		Amount:         discrepancy.Amount,
+		LocalStatus:    discrepancy.LocalStatus,
+		ProviderStatus: discrepancy.ProviderStatus,
+		CreatedAt:      discrepancy.CreatedAt,
+	}
+
+	return formatter
+}
+
+func FormatDiscrepancyList(discrepancyList []Discrepancy) []DiscrepancyFormatter {
+	var formatterList []DiscrepancyFormatter
+
+	for _, discrepancy := range discrepancyList {
+		formatter := FormatDiscrepancy(discrepancy)
+		formatterList = append(formatterList, formatter)
+	}
+
+	return formatterList
+}
+
+type RefundFormatter struct {
+	ID            int       `json:"id"`
+	TransactionID int       `json:"transaction_id"`
+	Amount        int       `json:"amount"`
+	Reason        string    `json:"reason"`
+	Status        string    `json:"status"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func FormatRefund(refund Refund) RefundFormatter {
+	formatter := RefundFormatter{
+		ID:            refund.ID,
+		TransactionID: refund.TransactionID,
+		Amount:        refund.Amount,
+		Reason:        refund.Reason,
+		Status:        refund.Status,
+		CreatedAt:     refund.CreatedAt,
+	}
+
+	return formatter
+}
+
+type TransactionDetailFormatter struct {
+	ID            int                      `json:"id"`
+	Code          string                   `json:"code"`
+	Amount        int                      `json:"amount"`
+	Currency      string                   `json:"currency"`
+	VoucherAmount int                      `json:"voucher_amount"`
+	Status        string                   `json:"status"`
+	PaymentType   string                   `json:"payment_type"`
+	PaymentURL    string                   `json:"payment_url"`
+	CreatedAt     time.Time                `json:"created_at"`
+	Campaign      CampaignSummaryFormatter `json:"campaign"`
+	StatusHistory []StatusHistoryFormatter `json:"status_history"`
+}
+
+type CampaignSummaryFormatter struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Slug     string `json:"slug"`
+	ImageURL string `json:"image_url"`
+}
+
+type StatusHistoryFormatter struct {
+	Status    string    `json:"status"`
+	Note      string    `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func FormatTransactionDetail(transaction Transaction, storageService storage.Service) TransactionDetailFormatter {
+	formatter := TransactionDetailFormatter{
+		ID:            transaction.ID,
+		Code:          transaction.Code,
+		Amount:        transaction.Amount,
+		Currency:      money.Normalize(transaction.Currency),
+		VoucherAmount: transaction.VoucherAmount,
+		Status:        transaction.Status,
+		PaymentType:   transaction.PaymentType,
+		CreatedAt:     transaction.CreatedAt,
+	}
+
+	//Payment URL is only useful while the transaction can still be paid
+	if transaction.Status == "pending" {
+		formatter.PaymentURL = transaction.PaymentURL
+	}
+
+	//Campaign
+	campaignFormatter := CampaignSummaryFormatter{
+		ID:   transaction.Campaign.ID,
+		Name: transaction.Campaign.Name,
+		Slug: transaction.Campaign.Slug,
+	}
+	if len(transaction.Campaign.CampaignImages) > 0 {
+		campaignFormatter.ImageURL = storage.URL(storageService, transaction.Campaign.CampaignImages[0].FileName)
+	}
+	formatter.Campaign = campaignFormatter
+
+	//StatusHistory
+	statusHistory := []StatusHistoryFormatter{}
+	for _, history := range transaction.StatusHistories {
+		historyFormatter := StatusHistoryFormatter{
+			Status:    history.Status,
+			Note:      history.Note,
+			CreatedAt: history.CreatedAt,
+		}
+
+		statusHistory = append(statusHistory, historyFormatter)
+	}
+	formatter.StatusHistory = statusHistory
+
+	return formatter
+}
@@ -1,16 +1,44 @@
 package transaction
 
-import "rocketship/user"
+import (
+	"rocketship/user"
+	"time"
+)
 
 type FindTransactionByIDInput struct {
 	ID   int `uri:"id" binding:"required"`
 	User user.User
 }
 
+type FindTransactionDetailInput struct {
+	Reference string `uri:"id" binding:"required"`
+	User      user.User
+}
+
+type ExportTransactionInput struct {
+	From   time.Time `form:"from" time_format:"2006-01-02"`
+	To     time.Time `form:"to" time_format:"2006-01-02"`
+	Format string    `form:"format"`
+}
+
 type CreateTransactionInput struct {
-	Amount     int `json:"amount" binding:"required"`
-	CampaignID int `json:"campaign_id" binding:"required"`
-	User       user.User
+	Amount      int    `json:"amount" binding:"required,gt=0"`
+	Currency    string `json:"currency"`
+	CampaignID  int    `json:"campaign_id" binding:"required"`
+	IsAnonymous bool   `json:"is_anonymous"`
+	HideAmount  bool   `json:"hide_amount"`
+	Message     string `json:"message" binding:"max=280"`
+	RewardTier  string `json:"reward_tier"`
+	ShareEmail  bool   `json:"share_email"`
+	VoucherCode string `json:"voucher_code"`
+	//SubscriptionID is only set internally for pledges created by a subscription
+	SubscriptionID int `json:"-"`
+	User           user.User
+}
+
+type ModerateMessageInput struct {
+	IsHidden bool `json:"is_hidden"`
+	User     user.User
 }
 
 type TransactionNotificationInput struct {
@@ -18,4 +46,11 @@ type TransactionNotificationInput struct {
 	OrderID           string `json:"order_id"`
 	PaymentType       string `json:"payment_type"`
 	FraudStatus       string `json:"fraud_status"`
+	SavedTokenID      string `json:"saved_token_id"`
+}
+
+type CreateRefundInput struct {
+	Amount int    `json:"amount"`
+	Reason string `json:"reason" binding:"required"`
+	User   user.User
 }
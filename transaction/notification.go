@@ -0,0 +1,55 @@
+package transaction
+
+import (
+	"fmt"
+	"html"
+	"rocketship/mailer"
+	"rocketship/money"
+	"rocketship/receipt"
+)
+
+func refundMail(transaction Transaction, refund Refund, campaignName string) mailer.Mail {
+	body := fmt.Sprintf(
+		"<p>Hi %s,</p><p>Your donation of %s to <b>%s</b> has been refunded by %s.</p><p>Reason: %s</p>",
+		html.EscapeString(transaction.User.Name),
+		money.Format(money.New(transaction.Amount, transaction.Currency)),
+		html.EscapeString(campaignName),
+		money.Format(money.New(refund.Amount, transaction.Currency)),
+		html.EscapeString(refund.Reason),
+	)
+
+	mail := mailer.Mail{
+		To:       transaction.User.Email,
+		Subject:  "Your donation to " + campaignName + " has been refunded",
+		HTMLBody: body,
+	}
+
+	return mail
+}
+
+func receiptMail(transactionReceipt receipt.Receipt) (mailer.Mail, error) {
+	body, err := receipt.RenderHTML(transactionReceipt)
+	if err != nil {
+		return mailer.Mail{}, err
+	}
+
+	pdf, err := receipt.RenderPDF(transactionReceipt)
+	if err != nil {
+		return mailer.Mail{}, err
+	}
+
+	mail := mailer.Mail{
+		To:       transactionReceipt.BackerEmail,
+		Subject:  "Your receipt for " + transactionReceipt.CampaignName,
+		HTMLBody: body,
+		Attachments: []mailer.Attachment{
+			{
+				FileName:    transactionReceipt.Code + ".pdf",
+				ContentType: "application/pdf",
+				Content:     pdf,
+			},
+		},
+	}
+
+	return mail, nil
+}
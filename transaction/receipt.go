@@ -0,0 +1,32 @@
+package transaction
+
+import (
+	"rocketship/receipt"
+	"time"
+)
+
+func buildReceipt(transaction Transaction, campaignName string) receipt.Receipt {
+	//The moment the transaction became paid is the receipt date
+	paidAt := transaction.UpdatedAt
+	for _, history := range transaction.StatusHistories {
+		if history.Status == "paid" {
+			paidAt = history.CreatedAt
+		}
+	}
+	if paidAt.IsZero() {
+		paidAt = time.Now()
+	}
+
+	transactionReceipt := receipt.Receipt{
+		Code:         transaction.Code,
+		CampaignName: campaignName,
+		BackerName:   transaction.User.Name,
+		BackerEmail:  transaction.User.Email,
+		Amount:       transaction.Amount,
+		Currency:     transaction.Currency,
+		PaidAt:       paidAt,
+		Organisation: receipt.NewOrganisationFromEnv(),
+	}
+
+	return transactionReceipt
+}
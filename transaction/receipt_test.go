@@ -0,0 +1,31 @@
+package transaction
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuildReceiptUsesPaidDate(t *testing.T) {
+	paidAt := time.Date(2024, 3, 9, 14, 30, 0, 0, time.UTC)
+
+	transaction := Transaction{
+		Code:      "TRX-0042",
+		Amount:    150000,
+		Currency:  "IDR",
+		UpdatedAt: paidAt.Add(48 * time.Hour),
+		StatusHistories: []StatusHistory{
+			{Status: "pending", CreatedAt: paidAt.Add(-time.Hour)},
+			{Status: "paid", CreatedAt: paidAt},
+			{Status: "refunded", CreatedAt: paidAt.Add(48 * time.Hour)},
+		},
+	}
+
+	transactionReceipt := buildReceipt(transaction, "Clean water")
+	if !transactionReceipt.PaidAt.Equal(paidAt) {
+		t.Errorf("buildReceipt().PaidAt = %s, expected %s", transactionReceipt.PaidAt, paidAt)
+	}
+
+	if transactionReceipt.Code != "TRX-0042" || transactionReceipt.CampaignName != "Clean water" || transactionReceipt.Amount != 150000 {
+		t.Errorf("buildReceipt() = %+v, expected the transaction details", transactionReceipt)
+	}
+}
@@ -0,0 +1,36 @@
+package transaction
+
+import (
+	"log"
+	"time"
+)
+
+type reconciler struct {
+	service     Service
+	interval    time.Duration
+	pendingFor  time.Duration
+	expireAfter time.Duration
+}
+
+func NewReconciler(service Service, interval time.Duration, pendingFor time.Duration, expireAfter time.Duration) *reconciler {
+	return &reconciler{service, interval, pendingFor, expireAfter}
+}
+
+func (reconciler *reconciler) Start() {
+	go func() {
+		ticker := time.NewTicker(reconciler.interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			err := reconciler.service.ReconcilePendingTransactions(reconciler.pendingFor, reconciler.expireAfter)
+			if err != nil {
+				log.Println("Failed to reconcile pending transactions:", err)
+			}
+
+			err = reconciler.service.SettlePaidTransactions(reconciler.pendingFor)
+			if err != nil {
+				log.Println("Failed to settle paid transactions:", err)
+			}
+		}
+	}()
+}
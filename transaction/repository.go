@@ -1,13 +1,37 @@
 package transaction
 
-import "gorm.io/gorm"
+import (
+	"rocketship/campaign"
+	"rocketship/ledger"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
 
 type Repository interface {
 	FindTransactionByCampaignID(campaignID int) ([]Transaction, error)
 	FindTransactionByUserID(userID int) ([]Transaction, error)
+	FindPaidTransactionByCampaignID(campaignID int) ([]Transaction, error)
+	FindTransactionByCampaignIDInBatches(campaignID int, from time.Time, to time.Time, handleBatch func(transactionList []Transaction) error) error
+	FindTransactionBySubscriptionID(subscriptionID int) ([]Transaction, error)
 	FindTransactionByID(ID int) (Transaction, error)
+	FindTransactionByCode(code string) (Transaction, error)
+	FindTransactionDetailByID(ID int) (Transaction, error)
+	FindTransactionByStatus(statuses []string) ([]Transaction, error)
+	SaveStatusHistory(statusHistory StatusHistory) (StatusHistory, error)
 	SaveTransaction(transaction Transaction) (Transaction, error)
 	UpdateTransaction(transaction Transaction) (Transaction, error)
+	UpdatePaymentStatus(ID int, apply func(transaction Transaction) (Transaction, []ledger.JournalEntry, error)) (Transaction, error)
+	FindPendingTransactionBefore(createdBefore time.Time) ([]Transaction, error)
+	FindUnsettledTransactionBefore(paidBefore time.Time) ([]Transaction, error)
+	MarkSettled(transaction Transaction) (bool, error)
+	SaveDiscrepancy(discrepancy Discrepancy) (Discrepancy, error)
+	FindAllDiscrepancy() ([]Discrepancy, error)
+	FindRefundByTransactionID(transactionID int) ([]Refund, error)
+	SaveRefund(campaignID int, request func() (Refund, error)) (Refund, error)
+	UpdateRefund(refund Refund) (Refund, error)
+	CompleteRefund(refund Refund, entryList []ledger.JournalEntry) (Refund, error)
 }
 
 type repository struct {
@@ -40,11 +64,80 @@ func (repo *repository) FindTransactionByUserID(userID int) ([]Transaction, erro
 	return transactionList, nil
 }
 
+func (repo *repository) FindPaidTransactionByCampaignID(campaignID int) ([]Transaction, error) {
+	var transactionList []Transaction
+
+	err := repo.db.Preload("User").Where("campaign_id = ? AND status = ?", campaignID, "paid").Order("created_at desc").Find(&transactionList).Error
+	if err != nil {
+		return transactionList, err
+	}
+
+	return transactionList, nil
+}
+
+// FindTransactionByCampaignIDInBatches hands transactions over in small batches so large campaigns are never loaded at once
+func (repo *repository) FindTransactionByCampaignIDInBatches(campaignID int, from time.Time, to time.Time, handleBatch func(transactionList []Transaction) error) error {
+	query := repo.db.Preload("User").Where("campaign_id = ?", campaignID)
+
+	if !from.IsZero() {
+		query = query.Where("created_at >= ?", from)
+	}
+
+	if !to.IsZero() {
+		query = query.Where("created_at < ?", to)
+	}
+
+	var transactionList []Transaction
+	err := query.FindInBatches(&transactionList, 500, func(tx *gorm.DB, batch int) error {
+		return handleBatch(transactionList)
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repo *repository) FindTransactionBySubscriptionID(subscriptionID int) ([]Transaction, error) {
+	var transactionList []Transaction
+
+	err := repo.db.Where("subscription_id = ?", subscriptionID).Order("created_at desc").Find(&transactionList).Error
+	if err != nil {
+		return transactionList, err
+	}
+
+	return transactionList, nil
+}
+
 func (r *repository) FindTransactionByID(ID int) (Transaction, error) {
 	var transaction Transaction
 
-	err := r.db.Where("id = ?", ID).Find(&transaction).Error
+	err := r.db.Preload("User").Where("id = ?", ID).Find(&transaction).Error
+
+	if err != nil {
+		return transaction, err
+	}
+
+	return transaction, nil
+}
+
+func (repo *repository) FindTransactionByCode(code string) (Transaction, error) {
+	var transaction Transaction
+
+	err := repo.db.Preload("User").Where("code = ?", code).Find(&transaction).Error
+	if err != nil {
+		return transaction, err
+	}
+
+	return transaction, nil
+}
+
+func (repo *repository) FindTransactionDetailByID(ID int) (Transaction, error) {
+	var transaction Transaction
 
+	err := repo.db.Preload("User").Preload("Campaign.CampaignImages", "campaign_images.is_primary = 1").Preload("StatusHistories", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at asc")
+	}).Where("id = ?", ID).Find(&transaction).Error
 	if err != nil {
 		return transaction, err
 	}
@@ -52,6 +145,16 @@ func (r *repository) FindTransactionByID(ID int) (Transaction, error) {
 	return transaction, nil
 }
 
+func (repo *repository) SaveStatusHistory(statusHistory StatusHistory) (StatusHistory, error) {
+	err := repo.db.Create(&statusHistory).Error
+
+	if err != nil {
+		return statusHistory, err
+	}
+
+	return statusHistory, nil
+}
+
 func (repo *repository) SaveTransaction(transaction Transaction) (Transaction, error) {
 	err := repo.db.Create(&transaction).Error
 
@@ -62,8 +165,9 @@ func (repo *repository) SaveTransaction(transaction Transaction) (Transaction, e
 	return transaction, nil
 }
 
+// UpdateTransaction leaves SettledAt to MarkSettled, a copy loaded before the payment was settled must not clear it
 func (repo *repository) UpdateTransaction(transaction Transaction) (Transaction, error) {
-	err := repo.db.Save(&transaction).Error
+	err := repo.db.Omit("settled_at").Save(&transaction).Error
 
 	if err != nil {
 		return transaction, err
@@ -71,3 +175,178 @@ func (repo *repository) UpdateTransaction(transaction Transaction) (Transaction,
 
 	return transaction, nil
 }
+
+func (repo *repository) UpdatePaymentStatus(ID int, apply func(transaction Transaction) (Transaction, []ledger.JournalEntry, error)) (Transaction, error) {
+	var transaction Transaction
+
+	//The row stays locked until the status and its journal entries are saved, so a notification
+	//and the reconciler never both move the same transaction, and a payment is never half-posted
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&transaction, ID).Error
+		if err != nil {
+			return err
+		}
+
+		var entryList []ledger.JournalEntry
+		transaction, entryList, err = apply(transaction)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Save(&transaction).Error
+		if err != nil {
+			return err
+		}
+
+		return ledger.CreateEntries(tx, entryList)
+	})
+
+	if err != nil {
+		return transaction, err
+	}
+
+	return transaction, nil
+}
+
+func (repo *repository) FindPendingTransactionBefore(createdBefore time.Time) ([]Transaction, error) {
+	var transactionList []Transaction
+
+	err := repo.db.Preload("User").Where("status = ? AND created_at < ?", "pending", createdBefore).Order("created_at asc").Find(&transactionList).Error
+	if err != nil {
+		return transactionList, err
+	}
+
+	return transactionList, nil
+}
+
+func (repo *repository) FindUnsettledTransactionBefore(paidBefore time.Time) ([]Transaction, error) {
+	var transactionList []Transaction
+
+	err := repo.db.Preload("User").Where("status = ? AND settled_at IS NULL AND paid_at < ?", "paid", paidBefore).Order("paid_at asc").Find(&transactionList).Error
+	if err != nil {
+		return transactionList, err
+	}
+
+	return transactionList, nil
+}
+
+// MarkSettled counts the backer together with marking the transaction, so a payment that is settled again is counted once.
+// It reports whether this call was the one that settled it
+func (repo *repository) MarkSettled(transaction Transaction) (bool, error) {
+	var settled bool
+
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Transaction{}).Where("id = ? AND settled_at IS NULL", transaction.ID).UpdateColumn("settled_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		settled = true
+
+		return tx.Model(&campaign.Campaign{}).Where("id = ?", transaction.CampaignID).Update("funder_amount", gorm.Expr("funder_amount + 1")).Error
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return settled, nil
+}
+
+func (repo *repository) SaveDiscrepancy(discrepancy Discrepancy) (Discrepancy, error) {
+	err := repo.db.Create(&discrepancy).Error
+
+	if err != nil {
+		return discrepancy, err
+	}
+
+	return discrepancy, nil
+}
+
+func (repo *repository) FindAllDiscrepancy() ([]Discrepancy, error) {
+	var discrepancyList []Discrepancy
+
+	err := repo.db.Preload("Transaction").Order("created_at desc").Find(&discrepancyList).Error
+	if err != nil {
+		return discrepancyList, err
+	}
+
+	return discrepancyList, nil
+}
+
+func (repo *repository) FindRefundByTransactionID(transactionID int) ([]Refund, error) {
+	var refundList []Refund
+
+	err := repo.db.Where("transaction_id = ?", transactionID).Order("created_at asc").Find(&refundList).Error
+	if err != nil {
+		return refundList, err
+	}
+
+	return refundList, nil
+}
+
+func (repo *repository) SaveRefund(campaignID int, request func() (Refund, error)) (Refund, error) {
+	var refund Refund
+
+	//Payout requests lock the same campaign row, so a refund and a payout never spend the same balance
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&campaign.Campaign{}, campaignID).Error
+		if err != nil {
+			return err
+		}
+
+		refund, err = request()
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(&refund).Error
+	})
+
+	if err != nil {
+		return refund, err
+	}
+
+	return refund, nil
+}
+
+func (repo *repository) UpdateRefund(refund Refund) (Refund, error) {
+	err := repo.db.Save(&refund).Error
+
+	if err != nil {
+		return refund, err
+	}
+
+	return refund, nil
+}
+
+func (repo *repository) CompleteRefund(refund Refund, entryList []ledger.JournalEntry) (Refund, error) {
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Save(&refund).Error
+		if err != nil {
+			return err
+		}
+
+		return ledger.CreateEntries(tx, entryList)
+	})
+
+	if err != nil {
+		return refund, err
+	}
+
+	return refund, nil
+}
+
+func (repo *repository) FindTransactionByStatus(statuses []string) ([]Transaction, error) {
+	var transactionList []Transaction
+
+	err := repo.db.Where("status IN ?", statuses).Order("id asc").Find(&transactionList).Error
+	if err != nil {
+		return transactionList, err
+	}
+
+	return transactionList, nil
+}
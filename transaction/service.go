@@ -2,35 +2,76 @@ package transaction
 
 import (
 	"errors"
+	"fmt"
+	"log"
 	"rocketship/campaign"
+	"rocketship/fee"
+	"rocketship/helper"
+	"rocketship/ledger"
+	"rocketship/mailer"
+	"rocketship/matching"
+	"rocketship/milestone"
+	"rocketship/money"
 	"rocketship/payment"
+	"rocketship/payout"
+	"rocketship/receipt"
+	"rocketship/user"
+	"rocketship/voucher"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type Service interface {
 	FindTransactionByCampaignID(campaignID FindTransactionByIDInput) ([]Transaction, error)
 	FindTransactionByUserID(userID int) ([]Transaction, error)
+	ExportTransactionByCampaignID(campaignID FindTransactionByIDInput, input ExportTransactionInput, write func(transactionList []Transaction) error) error
+	FindSupporters(campaignID int) ([]Transaction, error)
+	FindTransactionDetail(input FindTransactionDetailInput) (Transaction, error)
 	CreateTransaction(input CreateTransactionInput) (Transaction, error)
+	ChargeSavedCard(input CreateTransactionInput, savedTokenID string) (Transaction, error)
+	ResumePayment(input FindTransactionDetailInput) (Transaction, error)
+	FindReceipt(input FindTransactionDetailInput) (receipt.Receipt, error)
+	ModerateMessage(transactionID FindTransactionByIDInput, input ModerateMessageInput) (Transaction, error)
 	ProcessPayment(input TransactionNotificationInput) error
+	ReconcilePendingTransactions(pendingFor time.Duration, expireAfter time.Duration) error
+	SettlePaidTransactions(paidFor time.Duration) error
+	FindDiscrepancies(currentUser user.User) ([]Discrepancy, error)
+	CreateRefund(transactionID FindTransactionByIDInput, input CreateRefundInput) (Refund, error)
+	BackfillLedger() error
 }
 
 type service struct {
-	repository     Repository
-	campaign       campaign.Repository
-	paymentService payment.Service
+	repository       Repository
+	campaign         campaign.Repository
+	paymentService   payment.Service
+	feeService       fee.Service
+	ledgerService    ledger.Service
+	matchingService  matching.Service
+	voucherService   voucher.Service
+	milestoneService milestone.Service
+	payoutService    payout.Service
+	rateSource       money.RateSource
+	mailer           mailer.Service
 }
 
-func NewService(repository Repository, campaignRepository campaign.Repository, paymentService payment.Service) *service {
-	return &service{repository, campaignRepository, paymentService}
+func NewService(repository Repository, campaignRepository campaign.Repository, paymentService payment.Service, feeService fee.Service, ledgerService ledger.Service, matchingService matching.Service, voucherService voucher.Service, milestoneService milestone.Service, payoutService payout.Service, rateSource money.RateSource, mailerService mailer.Service) *service {
+	return &service{repository, campaignRepository, paymentService, feeService, ledgerService, matchingService, voucherService, milestoneService, payoutService, rateSource, mailerService}
 }
 
 func (service *service) FindTransactionByCampaignID(input FindTransactionByIDInput) ([]Transaction, error) {
-	campaign, err := service.campaign.FindCampaignByID(input.ID)
+	campaignByID, err := service.campaign.FindCampaignByID(input.ID)
 	if err != nil {
 		return []Transaction{}, err
 	}
 
-	if campaign.UserID != input.User.ID {
+	//Finance viewers of the campaign team see the same list as the owner
+	allowed, err := campaign.CanAccess(service.campaign, campaignByID, input.User.ID, campaign.PermissionViewFinance)
+	if err != nil {
+		return []Transaction{}, err
+	}
+
+	if !allowed {
 		return []Transaction{}, errors.New("could not find transactions due to lack of credentials")
 	}
 
@@ -42,6 +83,35 @@ func (service *service) FindTransactionByCampaignID(input FindTransactionByIDInp
 	return transactionList, nil
 }
 
+func (service *service) ExportTransactionByCampaignID(campaignID FindTransactionByIDInput, input ExportTransactionInput, write func(transactionList []Transaction) error) error {
+	campaignByID, err := service.campaign.FindCampaignByID(campaignID.ID)
+	if err != nil {
+		return err
+	}
+
+	allowed, err := campaign.CanAccess(service.campaign, campaignByID, campaignID.User.ID, campaign.PermissionViewFinance)
+	if err != nil {
+		return err
+	}
+
+	if !allowed {
+		return errors.New("could not export transactions due to lack of credentials")
+	}
+
+	//The end date is inclusive, so everything before the following midnight is exported
+	to := input.To
+	if !to.IsZero() {
+		to = to.AddDate(0, 0, 1)
+	}
+
+	err = service.repository.FindTransactionByCampaignIDInBatches(campaignID.ID, input.From, to, write)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (service *service) FindTransactionByUserID(userID int) ([]Transaction, error) {
 	transactionList, err := service.repository.FindTransactionByUserID(userID)
 	if err != nil {
@@ -51,73 +121,933 @@ func (service *service) FindTransactionByUserID(userID int) ([]Transaction, erro
 	return transactionList, nil
 }
 
+func (service *service) FindSupporters(campaignID int) ([]Transaction, error) {
+	transactionList, err := service.repository.FindPaidTransactionByCampaignID(campaignID)
+	if err != nil {
+		return transactionList, err
+	}
+
+	return transactionList, nil
+}
+
+func (service *service) FindTransactionDetail(input FindTransactionDetailInput) (Transaction, error) {
+	transaction, err := service.findTransactionByReference(input.Reference)
+	if err != nil {
+		return transaction, err
+	}
+
+	transactionDetail, err := service.repository.FindTransactionDetailByID(transaction.ID)
+	if err != nil {
+		return transactionDetail, err
+	}
+
+	if transactionDetail.UserID != input.User.ID && transactionDetail.Campaign.UserID != input.User.ID {
+		return Transaction{}, errors.New("could not find this transaction due to lack of credentials")
+	}
+
+	return transactionDetail, nil
+}
+
 func (service *service) CreateTransaction(input CreateTransactionInput) (Transaction, error) {
+	campaign, err := service.campaign.FindCampaignByID(input.CampaignID)
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	amounts, err := service.convertDonation(campaign, input)
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	err = validateDonation(campaign, input, amounts.CampaignAmount)
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	if input.VoucherCode != "" {
+		amounts, err = service.applyVoucher(campaign, input.VoucherCode, amounts)
+		if err != nil {
+			return Transaction{}, err
+		}
+	}
+
 	transaction := Transaction{
-		CampaignID: input.CampaignID,
-		Amount:     input.Amount,
-		UserID:     input.User.ID,
-		Status:     "pending",
+		CampaignID:     input.CampaignID,
+		Amount:         amounts.Amount.Amount,
+		Currency:       amounts.Amount.Currency,
+		CampaignAmount: amounts.CampaignAmount,
+		ChargeAmount:   amounts.ChargeAmount,
+		VoucherID:      amounts.VoucherID,
+		VoucherAmount:  amounts.VoucherAmount,
+		UserID:         input.User.ID,
+		SubscriptionID: input.SubscriptionID,
+		Status:         "pending",
+		IsAnonymous:    input.IsAnonymous,
+		IsAmountHidden: input.HideAmount,
+		Message:        strings.TrimSpace(input.Message),
+		RewardTier:     strings.TrimSpace(input.RewardTier),
+		IsEmailShared:  input.ShareEmail,
 	}
 
-	newTransaction, err := service.repository.SaveTransaction(transaction)
+	getPaymentURL := func(transaction Transaction) (string, error) {
+		if transaction.VoucherID != 0 {
+			err := service.voucherService.RedeemVoucher(transaction.VoucherID, transaction.ID, transaction.CampaignID, transaction.Currency, transaction.VoucherAmount)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		//A pledge fully covered by a voucher never goes to the provider
+		if chargeAmount(transaction) == 0 {
+			return "", nil
+		}
+
+		paymentTransaction := payment.Transaction{
+			ID:       transaction.ID,
+			Code:     providerOrderID(transaction),
+			Amount:   chargeAmount(transaction),
+			SaveCard: transaction.SubscriptionID != 0,
+		}
+
+		return service.paymentService.GetPaymentURL(paymentTransaction, input.User)
+	}
+
+	newTransaction, err := service.saveWithUniqueCode(transaction, service.repository.SaveTransaction)
 	if err != nil {
 		return newTransaction, err
 	}
 
-	paymentTransaction := payment.Transaction{
-		ID:     newTransaction.ID,
-		Amount: newTransaction.Amount,
+	err = service.recordStatusHistory(newTransaction, "transaction created")
+	if err != nil {
+		return newTransaction, err
 	}
 
-	paymentURL, err := service.paymentService.GetPaymentURL(paymentTransaction, input.User)
+	//The provider is called once the transaction is saved, no database transaction is held open while it answers
+	paymentURL, err := getPaymentURL(newTransaction)
 	if err != nil {
-		return newTransaction, err
+		return service.abandonTransaction(newTransaction, err)
 	}
 
 	newTransaction.PaymentURL = paymentURL
+
 	newTransaction, err = service.repository.UpdateTransaction(newTransaction)
 	if err != nil {
 		return newTransaction, err
 	}
 
+	//The receipt goes to the backer as soon as the voucher pays for the pledge
+	newTransaction.User = input.User
+
+	if chargeAmount(newTransaction) == 0 {
+		voucherInput := TransactionNotificationInput{
+			TransactionStatus: "settlement",
+			PaymentType:       "voucher",
+		}
+
+		return service.applyPaymentStatus(newTransaction, voucherInput)
+	}
+
 	return newTransaction, nil
 }
 
-func (service *service) ProcessPayment(input TransactionNotificationInput) error {
-	transactionID, _ := strconv.Atoi(input.OrderID)
+func (service *service) ChargeSavedCard(input CreateTransactionInput, savedTokenID string) (Transaction, error) {
+	campaign, err := service.campaign.FindCampaignByID(input.CampaignID)
+	if err != nil {
+		return Transaction{}, err
+	}
 
-	transaction, err := service.repository.FindTransactionByID(transactionID)
+	amounts, err := service.convertDonation(campaign, input)
 	if err != nil {
-		return err
+		return Transaction{}, err
 	}
 
-	if input.PaymentType == "credit_card" && input.TransactionStatus == "capture" && input.FraudStatus == "accept" {
-		transaction.Status = "paid"
-	} else if input.TransactionStatus == "settlement" {
-		transaction.Status = "paid"
-	} else if input.TransactionStatus == "deny" || input.TransactionStatus == "expire" || input.TransactionStatus == "cancel" {
+	err = validateDonation(campaign, input, amounts.CampaignAmount)
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	transaction := Transaction{
+		CampaignID:     input.CampaignID,
+		Amount:         amounts.Amount.Amount,
+		Currency:       amounts.Amount.Currency,
+		CampaignAmount: amounts.CampaignAmount,
+		ChargeAmount:   amounts.ChargeAmount,
+		UserID:         input.User.ID,
+		SubscriptionID: input.SubscriptionID,
+		Status:         "pending",
+		IsAnonymous:    input.IsAnonymous,
+		IsAmountHidden: input.HideAmount,
+		Message:        strings.TrimSpace(input.Message),
+		RewardTier:     strings.TrimSpace(input.RewardTier),
+		IsEmailShared:  input.ShareEmail,
+		SavedTokenID:   savedTokenID,
+	}
+
+	newTransaction, err := service.saveWithUniqueCode(transaction, service.repository.SaveTransaction)
+	if err != nil {
+		return newTransaction, err
+	}
+
+	err = service.recordStatusHistory(newTransaction, "recurring charge created")
+	if err != nil {
+		return newTransaction, err
+	}
+
+	paymentTransaction := payment.Transaction{
+		ID:     newTransaction.ID,
+		Code:   providerOrderID(newTransaction),
+		Amount: chargeAmount(newTransaction),
+	}
+
+	chargeStatus, err := service.paymentService.ChargeSavedCard(paymentTransaction, savedTokenID)
+	if err != nil {
+		return service.declineCharge(newTransaction, err.Error())
+	}
+
+	chargeInput := TransactionNotificationInput{
+		TransactionStatus: chargeStatus.TransactionStatus,
+		OrderID:           chargeStatus.OrderID,
+		PaymentType:       chargeStatus.PaymentType,
+		FraudStatus:       chargeStatus.FraudStatus,
+		SavedTokenID:      chargeStatus.SavedTokenID,
+	}
+
+	newTransaction.User = input.User
+
+	chargedTransaction, err := service.applyPaymentStatus(newTransaction, chargeInput)
+	if err != nil {
+		return chargedTransaction, err
+	}
+
+	//A pending charge is settled later through the notification or the reconciler
+	if chargedTransaction.Status == "paid" || chargeStatus.TransactionStatus == "pending" {
+		return chargedTransaction, nil
+	}
+
+	return service.declineCharge(chargedTransaction, chargeStatus.TransactionStatus)
+}
+
+// abandonTransaction cancels a pledge that never got a payment link and gives its voucher balance back
+func (service *service) abandonTransaction(transaction Transaction, reason error) (Transaction, error) {
+	transaction.Status = "cancelled"
+
+	updatedTransaction, err := service.repository.UpdateTransaction(transaction)
+	if err != nil {
+		log.Println("Failed to cancel transaction:", err)
+		return transaction, reason
+	}
+
+	err = service.recordStatusHistory(updatedTransaction, "payment link could not be created")
+	if err != nil {
+		log.Println("Failed to record status history:", err)
+	}
+
+	err = service.releaseVoucher(updatedTransaction)
+	if err != nil {
+		log.Println("Failed to release voucher:", err)
+	}
+
+	return updatedTransaction, reason
+}
+
+func (service *service) declineCharge(transaction Transaction, reason string) (Transaction, error) {
+	if transaction.Status == "pending" {
 		transaction.Status = "cancelled"
+
+		updatedTransaction, err := service.repository.UpdateTransaction(transaction)
+		if err != nil {
+			return updatedTransaction, err
+		}
+
+		err = service.recordStatusHistory(updatedTransaction, reason)
+		if err != nil {
+			return updatedTransaction, err
+		}
+
+		transaction = updatedTransaction
+	}
+
+	return transaction, errors.New("recurring charge was declined")
+}
+
+func (service *service) ResumePayment(input FindTransactionDetailInput) (Transaction, error) {
+	transaction, err := service.findTransactionByReference(input.Reference)
+	if err != nil {
+		return transaction, err
+	}
+
+	if transaction.UserID != input.User.ID {
+		return Transaction{}, errors.New("could not resume this payment due to lack of credentials")
+	}
+
+	if transaction.Status != "pending" {
+		return Transaction{}, errors.New("only pending transactions can be resumed")
+	}
+
+	//The previous link must not be payable next to the new one, an order that was paid meanwhile can't be expired
+	err = service.paymentService.ExpireTransaction(providerOrderID(transaction))
+	if err != nil {
+		return Transaction{}, fmt.Errorf("could not close the previous payment link: %w", err)
+	}
+
+	transaction.PaymentAttempt = transaction.PaymentAttempt + 1
+
+	paymentTransaction := payment.Transaction{
+		ID:     transaction.ID,
+		Code:   providerOrderID(transaction),
+		Amount: chargeAmount(transaction),
+	}
+
+	paymentURL, err := service.paymentService.GetPaymentURL(paymentTransaction, input.User)
+	if err != nil {
+		return Transaction{}, err
 	}
 
+	transaction.PaymentURL = paymentURL
 	updatedTransaction, err := service.repository.UpdateTransaction(transaction)
+	if err != nil {
+		return updatedTransaction, err
+	}
+
+	err = service.recordStatusHistory(updatedTransaction, "payment link regenerated")
+	if err != nil {
+		return updatedTransaction, err
+	}
+
+	return updatedTransaction, nil
+}
+
+func (service *service) FindReceipt(input FindTransactionDetailInput) (receipt.Receipt, error) {
+	transaction, err := service.FindTransactionDetail(input)
+	if err != nil {
+		return receipt.Receipt{}, err
+	}
+
+	if transaction.Status != "paid" {
+		return receipt.Receipt{}, errors.New("receipts are only available for paid transactions")
+	}
+
+	return buildReceipt(transaction, transaction.Campaign.Name), nil
+}
+
+func (service *service) ModerateMessage(transactionID FindTransactionByIDInput, input ModerateMessageInput) (Transaction, error) {
+	transaction, err := service.repository.FindTransactionByID(transactionID.ID)
+	if err != nil {
+		return transaction, err
+	}
+
+	if transaction.ID == 0 {
+		return transaction, errors.New("no transaction found with this ID")
+	}
+
+	campaign, err := service.campaign.FindCampaignByID(transaction.CampaignID)
+	if err != nil {
+		return transaction, err
+	}
+
+	if campaign.UserID != input.User.ID {
+		return Transaction{}, errors.New("could not moderate this message due to lack of credentials")
+	}
+
+	transaction.IsMessageHidden = input.IsHidden
+
+	updatedTransaction, err := service.repository.UpdateTransaction(transaction)
+	if err != nil {
+		return updatedTransaction, err
+	}
+
+	return updatedTransaction, nil
+}
+
+func (service *service) ProcessPayment(input TransactionNotificationInput) error {
+	code, attempt := splitOrderID(input.OrderID)
+
+	transaction, err := service.repository.FindTransactionByCode(code)
 	if err != nil {
 		return err
 	}
 
-	campaign, err := service.campaign.FindCampaignByID(updatedTransaction.CampaignID)
+	if transaction.ID == 0 || attempt > transaction.PaymentAttempt {
+		return errors.New("no transaction found with this order ID")
+	}
+
+	//Earlier payment links are expired when a payment is resumed, but a backer may have paid one just before,
+	//anything else about an earlier link is outdated
+	if attempt != transaction.PaymentAttempt && notificationStatus(input) != "paid" {
+		return nil
+	}
+
+	_, err = service.applyPaymentStatus(transaction, input)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (service *service) ReconcilePendingTransactions(pendingFor time.Duration, expireAfter time.Duration) error {
+	transactionList, err := service.repository.FindPendingTransactionBefore(time.Now().Add(-pendingFor))
+	if err != nil {
+		return err
+	}
+
+	//One order the provider can't answer for should not hold up the rest of the batch
+	for _, transaction := range transactionList {
+		err := service.reconcileTransaction(transaction, expireAfter)
+		if err != nil {
+			log.Printf("Failed to reconcile transaction %d: %v", transaction.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// SettlePaidTransactions retries the work after payments that failed halfway, the live path gets paidFor to finish first
+func (service *service) SettlePaidTransactions(paidFor time.Duration) error {
+	transactionList, err := service.repository.FindUnsettledTransactionBefore(time.Now().Add(-paidFor))
+	if err != nil {
+		return err
+	}
+
+	for _, transaction := range transactionList {
+		_, err := service.settlePayment(transaction)
+		if err != nil {
+			log.Printf("Failed to settle transaction %d: %v", transaction.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (service *service) reconcileTransaction(transaction Transaction, expireAfter time.Duration) error {
+	providerStatus, err := service.paymentService.GetTransactionStatus(providerOrderID(transaction))
+	if err != nil {
+		return err
+	}
+
+	//The provider has never seen this order, e.g. the backer never opened the payment page,
+	//or the backer opened it and never paid. A resumed payment gets a fresh link, so the age counts from the last change
+	if providerStatus.StatusCode == "404" || providerStatus.TransactionStatus == "pending" {
+		if time.Since(transaction.UpdatedAt) <= expireAfter {
+			return nil
+		}
+
+		if providerStatus.TransactionStatus == "pending" {
+			err := service.paymentService.ExpireTransaction(providerOrderID(transaction))
+			if err != nil {
+				return err
+			}
+		}
+
+		transaction.Status = "cancelled"
+
+		updatedTransaction, err := service.repository.UpdateTransaction(transaction)
+		if err != nil {
+			return err
+		}
+
+		err = service.recordStatusHistory(updatedTransaction, "expired without payment")
+		if err != nil {
+			return err
+		}
+
+		return service.releaseVoucher(updatedTransaction)
+	}
+
+	input := TransactionNotificationInput{
+		TransactionStatus: providerStatus.TransactionStatus,
+		OrderID:           providerStatus.OrderID,
+		PaymentType:       providerStatus.PaymentType,
+		FraudStatus:       providerStatus.FraudStatus,
+		SavedTokenID:      providerStatus.SavedTokenID,
+	}
+
+	updatedTransaction, err := service.applyPaymentStatus(transaction, input)
 	if err != nil {
 		return err
 	}
 
 	if updatedTransaction.Status == "paid" {
-		campaign.FunderAmount = campaign.FunderAmount + 1
-		campaign.CurrentAmount = campaign.CurrentAmount + updatedTransaction.Amount
+		discrepancy := Discrepancy{
+			TransactionID:  transaction.ID,
+			LocalStatus:    transaction.Status,
+			ProviderStatus: providerStatus.TransactionStatus,
+			Amount:         transaction.Amount,
+		}
+
+		_, err := service.repository.SaveDiscrepancy(discrepancy)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (service *service) FindDiscrepancies(currentUser user.User) ([]Discrepancy, error) {
+	if currentUser.Role != "admin" {
+		return []Discrepancy{}, errors.New("could not find discrepancies due to lack of credentials")
+	}
+
+	discrepancyList, err := service.repository.FindAllDiscrepancy()
+	if err != nil {
+		return discrepancyList, err
+	}
+
+	return discrepancyList, nil
+}
+
+func (service *service) CreateRefund(transactionID FindTransactionByIDInput, input CreateRefundInput) (Refund, error) {
+	transaction, err := service.repository.FindTransactionByID(transactionID.ID)
+	if err != nil {
+		return Refund{}, err
+	}
+
+	if transaction.ID == 0 {
+		return Refund{}, errors.New("no transaction found with this ID")
+	}
+
+	campaign, err := service.campaign.FindCampaignByID(transaction.CampaignID)
+	if err != nil {
+		return Refund{}, err
+	}
+
+	if campaign.UserID != input.User.ID && input.User.Role != "admin" {
+		return Refund{}, errors.New("could not refund this transaction due to lack of credentials")
+	}
+
+	if transaction.Status != "paid" {
+		return Refund{}, errors.New("only paid transactions can be refunded")
+	}
+
+	//The refund is saved before the provider is called, so a crash in between leaves a record with the provider's key
+	var refundableAmount int
+	newRefund, err := service.repository.SaveRefund(campaign.ID, func() (Refund, error) {
+		refund, amount, err := service.buildRefund(transaction, input)
+		refundableAmount = amount
+		return refund, err
+	})
+	if err != nil {
+		return newRefund, err
+	}
+
+	paymentRefund := payment.Refund{
+		Key:    newRefund.RefundKey,
+		Amount: newRefund.ChargeAmount,
+		Reason: newRefund.Reason,
+	}
+
+	if paymentRefund.Amount > 0 {
+		err = service.paymentService.RefundTransaction(providerOrderID(transaction), paymentRefund)
+		if err != nil {
+			newRefund.Status = "failed"
+
+			_, updateErr := service.repository.UpdateRefund(newRefund)
+			if updateErr != nil {
+				return newRefund, updateErr
+			}
+
+			return newRefund, err
+		}
+	}
+
+	//The refund leaves the campaign account together with its status change, so a completed refund is never left unposted
+	newRefund.Status = "completed"
+
+	entryList, err := service.ledgerService.RefundEntries(campaign.ID, newRefund.ID, refundCampaignAmount(transaction, newRefund))
+	if err != nil {
+		return newRefund, err
+	}
+
+	newRefund, err = service.repository.CompleteRefund(newRefund, entryList)
+	if err != nil {
+		return newRefund, err
+	}
+
+	if newRefund.Amount == refundableAmount {
+		transaction.Status = "refunded"
+
+		updatedTransaction, err := service.repository.UpdateTransaction(transaction)
+		if err != nil {
+			return newRefund, err
+		}
+
+		err = service.recordStatusHistory(updatedTransaction, newRefund.Reason)
+		if err != nil {
+			return newRefund, err
+		}
+
+		//Only the counter is changed, the campaign loaded above may already be outdated
+		err = service.campaign.UpdateFunderAmount(campaign.ID, -1)
+		if err != nil {
+			return newRefund, err
+		}
+	}
+
+	err = service.reverseMatches(transaction, newRefund)
+	if err != nil {
+		return newRefund, err
+	}
+
+	err = service.ledgerService.ProjectCampaign(campaign.ID)
+	if err != nil {
+		return newRefund, err
+	}
+
+	//The refund already went through, so a failed e-mail should not fail the request
+	err = service.mailer.Send(refundMail(transaction, newRefund, campaign.Name))
+	if err != nil {
+		log.Println("Failed to send refund notification:", err)
+	}
+
+	return newRefund, nil
+}
+
+// A refunded donation no longer earns its matched share, the sponsors get it back in proportion
+func (service *service) reverseMatches(transaction Transaction, refund Refund) error {
+	refundList, err := service.repository.FindRefundByTransactionID(transaction.ID)
+	if err != nil {
+		return err
+	}
+
+	refundedAmount := 0
+	for _, completedRefund := range refundList {
+		if completedRefund.Status == "completed" {
+			refundedAmount = refundedAmount + completedRefund.Amount
+		}
+	}
+
+	return service.matchingService.ReverseTransaction(transaction.ID, refund.ID, refundedAmount, paidAmount(transaction))
+}
+
+// buildRefund runs while the campaign is locked, so earlier refunds and the balance are final
+func (service *service) buildRefund(transaction Transaction, input CreateRefundInput) (Refund, int, error) {
+	refundList, err := service.repository.FindRefundByTransactionID(transaction.ID)
+	if err != nil {
+		return Refund{}, 0, err
+	}
+
+	//Pending refunds may still go through, so their amount is held back as well
+	refundedAmount := 0
+	for _, refund := range refundList {
+		if refund.Status != "failed" {
+			refundedAmount = refundedAmount + refund.Amount
+		}
+	}
+	refundableAmount := paidAmount(transaction) - refundedAmount
+	if refundableAmount <= 0 {
+		return Refund{}, 0, errors.New("nothing left to refund on this transaction")
+	}
+
+	//A refund without an amount refunds everything that is left
+	amount := input.Amount
+	if amount == 0 {
+		amount = refundableAmount
+	}
+
+	if amount < 0 || amount > refundableAmount {
+		return Refund{}, 0, fmt.Errorf("refund amount must be between 1 and %d", refundableAmount)
+	}
+
+	//Converted amounts are split cumulatively, so all refunds together add up to the original amounts
+	refund := Refund{
+		TransactionID:  transaction.ID,
+		UserID:         input.User.ID,
+		Amount:         amount,
+		CampaignAmount: money.Share(campaignAmount(transaction)-voucherCampaignAmount(transaction), refundedAmount+amount, paidAmount(transaction)) - money.Share(campaignAmount(transaction)-voucherCampaignAmount(transaction), refundedAmount, paidAmount(transaction)),
+		ChargeAmount:   money.Share(chargeAmount(transaction), refundedAmount+amount, paidAmount(transaction)) - money.Share(chargeAmount(transaction), refundedAmount, paidAmount(transaction)),
+		Reason:         input.Reason,
+		RefundKey:      fmt.Sprintf("%d-%d", transaction.ID, time.Now().UnixNano()),
+		Status:         "pending",
+	}
+
+	//Money already paid out to the creator cannot be sent back to the backer. The available balance has this
+	//transaction's fees taken off while the backer gets them back, so they are counted in again
+	balance, err := service.payoutService.FindCampaignBalance(transaction.CampaignID)
+	if err != nil {
+		return Refund{}, 0, err
+	}
+
+	available := balance.Available + transaction.PlatformFee + transaction.ProviderFee
+	if refund.CampaignAmount > available {
+		return Refund{}, 0, fmt.Errorf("refund exceeds the campaign's available balance of %d", available)
+	}
+
+	return refund, refundableAmount, nil
+}
+
+// Transactions settled before the ledger existed are posted once, later runs skip them
+func (service *service) BackfillLedger() error {
+	transactionList, err := service.repository.FindTransactionByStatus([]string{"paid", "refunded"})
+	if err != nil {
+		return err
+	}
+
+	for _, transaction := range transactionList {
+		err := service.ledgerService.RecordPayment(transaction.CampaignID, transaction.ID, campaignAmount(transaction), voucherCampaignAmount(transaction), transaction.PlatformFee, transaction.ProviderFee)
+		if err != nil {
+			return err
+		}
 
-		_, err := service.campaign.UpdateCampaign(campaign)
+		refundList, err := service.repository.FindRefundByTransactionID(transaction.ID)
 		if err != nil {
 			return err
 		}
+
+		for _, refund := range refundList {
+			if refund.Status != "completed" {
+				continue
+			}
+
+			err := service.ledgerService.RecordRefund(transaction.CampaignID, refund.ID, refundCampaignAmount(transaction, refund))
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
 }
+
+func (service *service) findTransactionByReference(reference string) (Transaction, error) {
+	transaction, err := service.repository.FindTransactionByCode(reference)
+	if err != nil {
+		return transaction, err
+	}
+
+	if transaction.ID == 0 {
+		transactionID, _ := strconv.Atoi(reference)
+
+		transaction, err = service.repository.FindTransactionByID(transactionID)
+		if err != nil {
+			return transaction, err
+		}
+	}
+
+	if transaction.ID == 0 {
+		return transaction, errors.New("no transaction found with this ID or code")
+	}
+
+	return transaction, nil
+}
+
+func (service *service) recordStatusHistory(transaction Transaction, note string) error {
+	statusHistory := StatusHistory{
+		TransactionID: transaction.ID,
+		Status:        transaction.Status,
+		Note:          note,
+	}
+
+	_, err := service.repository.SaveStatusHistory(statusHistory)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Codes are random, so the unique index rarely rejects one and a fresh code is simply tried again
+func (service *service) saveWithUniqueCode(transaction Transaction, save func(transaction Transaction) (Transaction, error)) (Transaction, error) {
+	for attempt := 1; ; attempt++ {
+		code, err := generateCode()
+		if err != nil {
+			return transaction, err
+		}
+
+		transaction.Code = code
+
+		savedTransaction, err := save(transaction)
+		if err == nil || !helper.IsDuplicateKey(err) || attempt == codeAttempts {
+			return savedTransaction, err
+		}
+	}
+}
+
+func (service *service) applyPaymentStatus(transaction Transaction, input TransactionNotificationInput) (Transaction, error) {
+	status := notificationStatus(input)
+
+	//The status is read again from the locked row, the one passed in may already be outdated
+	var previousStatus string
+	updatedTransaction, err := service.repository.UpdatePaymentStatus(transaction.ID, func(lockedTransaction Transaction) (Transaction, []ledger.JournalEntry, error) {
+		previousStatus = lockedTransaction.Status
+		return service.nextPaymentStatus(lockedTransaction, input, status)
+	})
+	if err != nil {
+		return transaction, err
+	}
+
+	updatedTransaction.User = transaction.User
+	updatedTransaction.Campaign = transaction.Campaign
+
+	//Paid, cancelled and refunded are final, so late or replayed notifications never reopen a transaction
+	if previousStatus != "pending" {
+		//Money that arrives for a cancelled pledge has to be returned by hand
+		if previousStatus == "cancelled" && status == "paid" {
+			discrepancy := Discrepancy{
+				TransactionID:  updatedTransaction.ID,
+				LocalStatus:    previousStatus,
+				ProviderStatus: input.TransactionStatus,
+				Amount:         updatedTransaction.Amount,
+			}
+
+			_, err := service.repository.SaveDiscrepancy(discrepancy)
+			if err != nil {
+				return updatedTransaction, err
+			}
+		}
+
+		return updatedTransaction, nil
+	}
+
+	if previousStatus != updatedTransaction.Status {
+		err := service.recordStatusHistory(updatedTransaction, input.TransactionStatus)
+		if err != nil {
+			return updatedTransaction, err
+		}
+	}
+
+	if updatedTransaction.Status == "cancelled" {
+		err := service.releaseVoucher(updatedTransaction)
+		if err != nil {
+			return updatedTransaction, err
+		}
+	}
+
+	if updatedTransaction.Status == "paid" {
+		return service.settlePayment(updatedTransaction)
+	}
+
+	return updatedTransaction, nil
+}
+
+// settlePayment does the work that follows a payment. Every step is safe to run again, so a paid transaction that
+// failed halfway is retried by the reconciler until it is settled, and only the call that settles it counts the backer
+// and sends the receipt
+func (service *service) settlePayment(transaction Transaction) (Transaction, error) {
+	campaign, err := service.campaign.FindCampaignByID(transaction.CampaignID)
+	if err != nil {
+		return transaction, err
+	}
+
+	paidAt := transaction.UpdatedAt
+	if transaction.PaidAt != nil {
+		paidAt = *transaction.PaidAt
+	}
+
+	//Sponsors only match what the backer paid, not what a voucher covered
+	_, err = service.matchingService.MatchTransaction(campaign, transaction.ID, campaignAmount(transaction)-voucherCampaignAmount(transaction), paidAt)
+	if err != nil {
+		return transaction, err
+	}
+
+	err = service.ledgerService.ProjectCampaign(campaign.ID)
+	if err != nil {
+		return transaction, err
+	}
+
+	settled, err := service.repository.MarkSettled(transaction)
+	if err != nil || !settled {
+		return transaction, err
+	}
+
+	//The payment is already counted, a milestone missed here is picked up by the next one
+	err = service.milestoneService.CheckCampaign(campaign.ID)
+	if err != nil {
+		log.Println("Failed to record milestones:", err)
+	}
+
+	service.sendReceipt(transaction, campaign.Name)
+
+	return transaction, nil
+}
+
+// nextPaymentStatus moves a pending transaction along, a payment also brings its journal entries to be saved with it
+func (service *service) nextPaymentStatus(transaction Transaction, input TransactionNotificationInput, status string) (Transaction, []ledger.JournalEntry, error) {
+	if transaction.Status != "pending" {
+		return transaction, nil, nil
+	}
+
+	if status != "" {
+		transaction.Status = status
+	}
+
+	if input.PaymentType != "" {
+		transaction.PaymentType = input.PaymentType
+	}
+
+	if input.SavedTokenID != "" {
+		transaction.SavedTokenID = input.SavedTokenID
+	}
+
+	if transaction.Status != "paid" {
+		return transaction, nil, nil
+	}
+
+	paidAt := time.Now()
+	transaction.PaidAt = &paidAt
+
+	//Fees are fixed when the money comes in, so later rule changes leave this transaction alone
+	transactionFee, err := service.feeService.CalculateFee(transaction.CampaignID, campaignAmount(transaction), chargeAmount(transaction), paidAt)
+	if err != nil {
+		return transaction, nil, err
+	}
+
+	//The provider fee is converted back with the rate the charged part was converted with
+	transaction.FeeRuleID = transactionFee.RuleID
+	transaction.PlatformFee = transactionFee.PlatformFee
+	transaction.ProviderFee = money.Share(campaignAmount(transaction)-voucherCampaignAmount(transaction), transactionFee.ProviderFee, chargeAmount(transaction))
+
+	entryList, err := service.ledgerService.PaymentEntries(transaction.CampaignID, transaction.ID, campaignAmount(transaction), voucherCampaignAmount(transaction), transaction.PlatformFee, transaction.ProviderFee)
+	if err != nil {
+		return transaction, nil, err
+	}
+
+	return transaction, entryList, nil
+}
+
+// notificationStatus maps a provider status onto ours, an empty status leaves the transaction as it is
+func notificationStatus(input TransactionNotificationInput) string {
+	if input.PaymentType == "credit_card" && input.TransactionStatus == "capture" && input.FraudStatus == "accept" {
+		return "paid"
+	}
+
+	switch input.TransactionStatus {
+	case "settlement":
+		return "paid"
+	case "deny", "expire", "cancel":
+		return "cancelled"
+	}
+
+	return ""
+}
+
+// An unpaid pledge gives its voucher balance back so the code can be used again
+func (service *service) releaseVoucher(transaction Transaction) error {
+	if transaction.VoucherID == 0 {
+		return nil
+	}
+
+	return service.voucherService.ReleaseVoucher(transaction.ID)
+}
+
+// The payment already went through, so a receipt that fails to send is only logged
+func (service *service) sendReceipt(transaction Transaction, campaignName string) {
+	//The receipt goes to the backer's address, without one there is nobody to send it to
+	if transaction.User.Email == "" {
+		log.Printf("Skipped receipt for transaction %d, the backer has no e-mail address", transaction.ID)
+		return
+	}
+
+	transaction.UpdatedAt = time.Now()
+
+	mail, err := receiptMail(buildReceipt(transaction, campaignName))
+	if err != nil {
+		log.Println("Failed to render receipt:", err)
+		return
+	}
+
+	err = service.mailer.Send(mail)
+	if err != nil {
+		log.Println("Failed to send receipt:", err)
+	}
+}
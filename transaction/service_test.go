@@ -0,0 +1,80 @@
+package transaction
+
+import (
+	"rocketship/payout"
+	"rocketship/user"
+	"testing"
+)
+
+type fakeRepository struct {
+	Repository
+	refunds []Refund
+}
+
+func (repo *fakeRepository) FindRefundByTransactionID(transactionID int) ([]Refund, error) {
+	return repo.refunds, nil
+}
+
+type fakePayoutService struct {
+	payout.Service
+	balance payout.Balance
+}
+
+func (payoutService *fakePayoutService) FindCampaignBalance(campaignID int) (payout.Balance, error) {
+	return payoutService.balance, nil
+}
+
+func TestBuildRefundCountsTheTransactionFeesBack(t *testing.T) {
+	//The campaign's only donation, its fees are already taken off the available balance
+	transaction := Transaction{
+		ID:             4,
+		CampaignID:     7,
+		Amount:         100000,
+		Currency:       "IDR",
+		CampaignAmount: 100000,
+		ChargeAmount:   100000,
+		PlatformFee:    5000,
+		ProviderFee:    4000,
+		Status:         "paid",
+	}
+
+	testCases := []struct {
+		name      string
+		balance   payout.Balance
+		amount    int
+		expectErr bool
+	}{
+		{"full refund", payout.Balance{Raised: 100000, Fees: 9000, Net: 91000, Available: 91000}, 0, false},
+		{"partial refund", payout.Balance{Raised: 100000, Fees: 9000, Net: 91000, Available: 91000}, 40000, false},
+		{"paid out", payout.Balance{Raised: 100000, Fees: 9000, Net: 91000, PaidOut: 60000, Available: 31000}, 0, true},
+	}
+
+	for _, testCase := range testCases {
+		service := &service{
+			repository:    &fakeRepository{},
+			payoutService: &fakePayoutService{balance: testCase.balance},
+		}
+
+		refund, _, err := service.buildRefund(transaction, CreateRefundInput{Amount: testCase.amount, Reason: "duplicate", User: user.User{ID: 1}})
+		if testCase.expectErr {
+			if err == nil {
+				t.Errorf("%s: buildRefund() = %+v, expected an error", testCase.name, refund)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: buildRefund() returned %v", testCase.name, err)
+			continue
+		}
+
+		expected := testCase.amount
+		if expected == 0 {
+			expected = transaction.Amount
+		}
+
+		if refund.Amount != expected || refund.CampaignAmount != expected {
+			t.Errorf("%s: buildRefund() = %+v, expected an amount of %d", testCase.name, refund, expected)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package transaction
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"rocketship/campaign"
+	"rocketship/money"
+	"strings"
+	"time"
+)
+
+// Limits are set in the campaign currency, so they are checked against the converted amount
+func validateDonation(campaignByID campaign.Campaign, input CreateTransactionInput, campaignAmount int) error {
+	if campaignByID.ID == 0 {
+		return errors.New("no campaign found with this ID")
+	}
+
+	if !campaign.IsFundable(campaignByID, time.Now()) {
+		return errors.New("this campaign is no longer accepting donations")
+	}
+
+	if input.Amount <= 0 {
+		return errors.New("donation amount must be positive")
+	}
+
+	if campaignByID.MinDonation != 0 && campaignAmount < campaignByID.MinDonation {
+		return fmt.Errorf("donation amount must be at least %s", money.Format(money.New(campaignByID.MinDonation, campaignByID.Currency)))
+	}
+
+	if campaignByID.MaxDonation != 0 && campaignAmount > campaignByID.MaxDonation {
+		return fmt.Errorf("donation amount must be at most %s", money.Format(money.New(campaignByID.MaxDonation, campaignByID.Currency)))
+	}
+
+	if input.RewardTier != "" && !hasPerk(campaignByID, input.RewardTier) {
+		return errors.New("this campaign does not offer the chosen reward tier")
+	}
+
+	//Self-donations are rejected unless explicitly allowed
+	if campaignByID.UserID == input.User.ID && os.Getenv("ALLOW_SELF_DONATION") != "true" {
+		return errors.New("campaign owners could not back their own campaign")
+	}
+
+	return nil
+}
+
+func hasPerk(campaign campaign.Campaign, rewardTier string) bool {
+	for _, perk := range strings.Split(campaign.Perks, ",") {
+		if strings.EqualFold(strings.TrimSpace(perk), strings.TrimSpace(rewardTier)) {
+			return true
+		}
+	}
+
+	return false
+}
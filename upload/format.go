@@ -0,0 +1,271 @@
+package upload
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+type format struct {
+	contentType string
+	extension   string
+	strip       func(content []byte) ([]byte, error)
+}
+
+var (
+	jpegSignature = []byte{0xFF, 0xD8, 0xFF}
+	pngSignature  = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1A, '\n'}
+)
+
+var (
+	jpegFormat = format{"image/jpeg", ".jpg", stripJPEG}
+	pngFormat  = format{"image/png", ".png", stripPNG}
+	webpFormat = format{"image/webp", ".webp", stripWebP}
+)
+
+// detectFormat trusts the magic bytes only, never the declared content type or extension.
+func detectFormat(content []byte) (format, bool) {
+	switch {
+	case bytes.HasPrefix(content, jpegSignature):
+		return jpegFormat, true
+	case bytes.HasPrefix(content, pngSignature):
+		return pngFormat, true
+	case len(content) >= 12 && string(content[0:4]) == "RIFF" && string(content[8:12]) == "WEBP":
+		return webpFormat, true
+	}
+
+	return format{}, false
+}
+
+// stripJPEG drops APP1 (EXIF/XMP), APP13 (IPTC) and comment segments before the image data.
+// The EXIF orientation is the one tag kept, in a segment of its own, so photos taken sideways still display upright.
+func stripJPEG(content []byte) ([]byte, error) {
+	output := append([]byte{}, content[0:2]...)
+	i := 2
+	orientationKept := false
+
+	for {
+		if i+1 >= len(content) || content[i] != 0xFF {
+			return nil, errors.New("JPEG image is corrupted")
+		}
+
+		marker := content[i+1]
+
+		//Fill bytes may pad between segments
+		if marker == 0xFF {
+			i++
+			continue
+		}
+
+		//Start of scan, everything after it is image data
+		if marker == 0xDA || marker == 0xD9 {
+			output = append(output, content[i:]...)
+			return output, nil
+		}
+
+		//Standalone markers have no length
+		if marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7) {
+			output = append(output, content[i:i+2]...)
+			i = i + 2
+			continue
+		}
+
+		if i+4 > len(content) {
+			return nil, errors.New("JPEG image is corrupted")
+		}
+
+		end := i + 2 + int(binary.BigEndian.Uint16(content[i+2:i+4]))
+		if end > len(content) {
+			return nil, errors.New("JPEG image is corrupted")
+		}
+
+		if marker != 0xE1 && marker != 0xED && marker != 0xFE {
+			output = append(output, content[i:end]...)
+		}
+
+		if marker == 0xE1 && !orientationKept {
+			if orientation := exifOrientation(content[i+4 : end]); orientation > 1 {
+				output = append(output, orientationSegment(orientation)...)
+				orientationKept = true
+			}
+		}
+
+		i = end
+	}
+}
+
+// Orientation reads the EXIF orientation of a JPEG, 1 means the pixels are stored upright.
+func Orientation(content []byte) int {
+	if !bytes.HasPrefix(content, jpegSignature) {
+		return 1
+	}
+
+	i := 2
+	for i+4 <= len(content) && content[i] == 0xFF {
+		marker := content[i+1]
+		if marker == 0xDA || marker == 0xD9 {
+			break
+		}
+
+		end := i + 2 + int(binary.BigEndian.Uint16(content[i+2:i+4]))
+		if end > len(content) {
+			break
+		}
+
+		if marker == 0xE1 {
+			if orientation := exifOrientation(content[i+4 : end]); orientation > 0 {
+				return orientation
+			}
+		}
+
+		i = end
+	}
+
+	return 1
+}
+
+const exifOrientationTag = 0x0112
+
+// exifOrientation finds the orientation tag in the first IFD of an APP1 payload, 0 means there is none
+func exifOrientation(payload []byte) int {
+	if !bytes.HasPrefix(payload, []byte("Exif\x00\x00")) {
+		return 0
+	}
+
+	tiff := payload[6:]
+	if len(tiff) < 8 {
+		return 0
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[0:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	offset := int(order.Uint32(tiff[4:8]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 0
+	}
+
+	count := int(order.Uint16(tiff[offset : offset+2]))
+	for entry := 0; entry < count; entry++ {
+		start := offset + 2 + entry*12
+		if start+12 > len(tiff) {
+			return 0
+		}
+
+		if order.Uint16(tiff[start:start+2]) == exifOrientationTag {
+			orientation := int(order.Uint16(tiff[start+8 : start+10]))
+			if orientation < 1 || orientation > 8 {
+				return 0
+			}
+
+			return orientation
+		}
+	}
+
+	return 0
+}
+
+// orientationSegment is an APP1 segment holding nothing but the orientation tag
+func orientationSegment(orientation int) []byte {
+	tiff := []byte{
+		'M', 'M', 0x00, 0x2A, 0x00, 0x00, 0x00, 0x08,
+		0x00, 0x01,
+		0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01, 0x00, byte(orientation), 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00,
+	}
+
+	segment := []byte{0xFF, 0xE1, 0x00, 0x00}
+	segment = append(segment, "Exif\x00\x00"...)
+	segment = append(segment, tiff...)
+	binary.BigEndian.PutUint16(segment[2:4], uint16(len(segment)-2))
+
+	return segment
+}
+
+var pngMetadataChunks = map[string]bool{
+	"eXIf": true,
+	"tEXt": true,
+	"zTXt": true,
+	"iTXt": true,
+	"tIME": true,
+}
+
+func stripPNG(content []byte) ([]byte, error) {
+	output := append([]byte{}, pngSignature...)
+	i := len(pngSignature)
+
+	for {
+		if i+8 > len(content) {
+			return nil, errors.New("PNG image is corrupted")
+		}
+
+		length := int(binary.BigEndian.Uint32(content[i : i+4]))
+		chunkType := string(content[i+4 : i+8])
+
+		//Length, type, data and CRC
+		end := i + 12 + length
+		if length < 0 || end > len(content) {
+			return nil, errors.New("PNG image is corrupted")
+		}
+
+		if !pngMetadataChunks[chunkType] {
+			output = append(output, content[i:end]...)
+		}
+
+		if chunkType == "IEND" {
+			return output, nil
+		}
+
+		i = end
+	}
+}
+
+const (
+	webpFlagXMP  = 0x04
+	webpFlagEXIF = 0x08
+)
+
+func stripWebP(content []byte) ([]byte, error) {
+	output := append([]byte{}, content[0:12]...)
+	i := 12
+
+	for i < len(content) {
+		if i+8 > len(content) {
+			return nil, errors.New("WebP image is corrupted")
+		}
+
+		chunkType := string(content[i : i+4])
+		length := int(binary.LittleEndian.Uint32(content[i+4 : i+8]))
+
+		//Chunks are padded to an even length
+		end := i + 8 + length + length%2
+		if length < 0 || end > len(content) {
+			return nil, errors.New("WebP image is corrupted")
+		}
+
+		switch chunkType {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			chunk := append([]byte{}, content[i:end]...)
+			if length > 0 {
+				chunk[8] = chunk[8] &^ (webpFlagEXIF | webpFlagXMP)
+			}
+			output = append(output, chunk...)
+		default:
+			output = append(output, content[i:end]...)
+		}
+
+		i = end
+	}
+
+	binary.LittleEndian.PutUint32(output[4:8], uint32(len(output)-8))
+
+	return output, nil
+}
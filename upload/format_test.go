@@ -0,0 +1,92 @@
+package upload
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"testing"
+)
+
+// exifSegment builds a little-endian APP1 segment with a camera make and an orientation
+func exifSegment(orientation int) []byte {
+	tiff := []byte{'I', 'I', 0x2A, 0x00, 0x08, 0x00, 0x00, 0x00}
+	tiff = append(tiff, 0x02, 0x00)
+
+	//Make, ASCII, stored after the IFD
+	entry := make([]byte, 12)
+	binary.LittleEndian.PutUint16(entry[0:2], 0x010F)
+	binary.LittleEndian.PutUint16(entry[2:4], 2)
+	binary.LittleEndian.PutUint32(entry[4:8], 6)
+	binary.LittleEndian.PutUint32(entry[8:12], 38)
+	tiff = append(tiff, entry...)
+
+	entry = make([]byte, 12)
+	binary.LittleEndian.PutUint16(entry[0:2], exifOrientationTag)
+	binary.LittleEndian.PutUint16(entry[2:4], 3)
+	binary.LittleEndian.PutUint32(entry[4:8], 1)
+	binary.LittleEndian.PutUint16(entry[8:10], uint16(orientation))
+	tiff = append(tiff, entry...)
+
+	tiff = append(tiff, 0x00, 0x00, 0x00, 0x00)
+	tiff = append(tiff, "Canon\x00"...)
+
+	segment := []byte{0xFF, 0xE1, 0x00, 0x00}
+	segment = append(segment, "Exif\x00\x00"...)
+	segment = append(segment, tiff...)
+	binary.BigEndian.PutUint16(segment[2:4], uint16(len(segment)-2))
+
+	return segment
+}
+
+func testJPEG(t *testing.T, orientation int) []byte {
+	var buffer bytes.Buffer
+
+	err := jpeg.Encode(&buffer, image.NewRGBA(image.Rect(0, 0, 4, 2)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded := buffer.Bytes()
+	content := append([]byte{}, encoded[0:2]...)
+	content = append(content, exifSegment(orientation)...)
+	content = append(content, 0xFF, 0xFE, 0x00, 0x06, 'n', 'o', 't', 'e')
+
+	return append(content, encoded[2:]...)
+}
+
+func TestStripJPEGKeepsOnlyOrientation(t *testing.T) {
+	content := testJPEG(t, 6)
+	if Orientation(content) != 6 {
+		t.Fatalf("Orientation() of the test image = %d, expected 6", Orientation(content))
+	}
+
+	stripped, err := stripJPEG(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Contains(stripped, []byte("Canon")) || bytes.Contains(stripped, []byte("note")) {
+		t.Error("stripJPEG() kept metadata other than the orientation")
+	}
+
+	if Orientation(stripped) != 6 {
+		t.Errorf("Orientation() after stripJPEG() = %d, expected 6", Orientation(stripped))
+	}
+
+	_, err = jpeg.Decode(bytes.NewReader(stripped))
+	if err != nil {
+		t.Errorf("stripped JPEG does not decode: %v", err)
+	}
+}
+
+func TestStripJPEGDropsUprightOrientation(t *testing.T) {
+	stripped, err := stripJPEG(testJPEG(t, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Contains(stripped, []byte("Exif")) {
+		t.Error("stripJPEG() kept an EXIF segment for an upright image")
+	}
+}
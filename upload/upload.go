@@ -0,0 +1,97 @@
+package upload
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime/multipart"
+)
+
+type File struct {
+	Content     []byte
+	ContentType string
+	Extension   string
+	Hash        string
+}
+
+// ValidationError describes why an upload was rejected, so handlers can tell
+// bad files apart from storage failures.
+type ValidationError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// Process reads an uploaded image, checks its type and size, strips metadata
+// and hashes the cleaned content for naming.
+func Process(header *multipart.FileHeader, maxSize int64) (File, error) {
+	if header.Size > maxSize {
+		return File{}, tooLarge(maxSize)
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return File{}, err
+	}
+	defer file.Close()
+
+	//The declared size comes from the client, so the read is bounded as well
+	content, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		return File{}, err
+	}
+
+	if int64(len(content)) > maxSize {
+		return File{}, tooLarge(maxSize)
+	}
+
+	if len(content) == 0 {
+		return File{}, &ValidationError{Code: "empty_file", Message: "File is empty"}
+	}
+
+	format, ok := detectFormat(content)
+	if !ok {
+		return File{}, &ValidationError{Code: "unsupported_type", Message: "Only JPEG, PNG and WebP images are allowed"}
+	}
+
+	stripped, err := format.strip(content)
+	if err != nil {
+		return File{}, &ValidationError{Code: "invalid_image", Message: err.Error()}
+	}
+
+	sum := sha256.Sum256(stripped)
+
+	processed := File{
+		Content:     stripped,
+		ContentType: format.contentType,
+		Extension:   format.extension,
+		Hash:        hex.EncodeToString(sum[:]),
+	}
+
+	return processed, nil
+}
+
+// Key names the file after its content, so client filenames never reach storage.
+func (file File) Key(prefix string) string {
+	return contentKey(prefix, file.Hash, file.Extension)
+}
+
+func (file File) Reader() io.Reader {
+	return bytes.NewReader(file.Content)
+}
+
+func contentKey(prefix string, hash string, extension string) string {
+	return fmt.Sprintf("%s/%s%s", prefix, hash, extension)
+}
+
+func tooLarge(maxSize int64) *ValidationError {
+	return &ValidationError{
+		Code:    "file_too_large",
+		Message: fmt.Sprintf("File must not be larger than %d KB", maxSize/1024),
+	}
+}
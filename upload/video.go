@@ -0,0 +1,96 @@
+package upload
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"mime/multipart"
+)
+
+type Video struct {
+	ContentType string
+	Extension   string
+	Hash        string
+	Size        int64
+}
+
+// Brands of MP4 and QuickTime files that browsers can play without transcoding
+var mp4Brands = map[string]bool{
+	"isom": true,
+	"iso2": true,
+	"iso5": true,
+	"iso6": true,
+	"mp41": true,
+	"mp42": true,
+	"avc1": true,
+	"dash": true,
+	"M4V ": true,
+}
+
+// ProcessVideo checks the container of an uploaded video and hashes it without keeping it in memory.
+func ProcessVideo(header *multipart.FileHeader, maxSize int64) (Video, error) {
+	if header.Size > maxSize {
+		return Video{}, tooLarge(maxSize)
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return Video{}, err
+	}
+	defer file.Close()
+
+	head := make([]byte, 64)
+	count, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return Video{}, err
+	}
+	head = head[:count]
+
+	video, ok := detectVideo(head)
+	if !ok {
+		return Video{}, &ValidationError{Code: "unsupported_type", Message: "Only MP4, QuickTime and WebM videos are allowed"}
+	}
+
+	hash := sha256.New()
+	hash.Write(head)
+
+	size, err := io.Copy(hash, io.LimitReader(file, maxSize+1-int64(count)))
+	if err != nil {
+		return Video{}, err
+	}
+
+	video.Size = size + int64(count)
+	if video.Size > maxSize {
+		return Video{}, tooLarge(maxSize)
+	}
+
+	video.Hash = hex.EncodeToString(hash.Sum(nil))
+
+	return video, nil
+}
+
+// Key names the video after its content, like images.
+func (video Video) Key(prefix string) string {
+	return contentKey(prefix, video.Hash, video.Extension)
+}
+
+func detectVideo(head []byte) (Video, bool) {
+	if len(head) >= 12 && string(head[4:8]) == "ftyp" {
+		brand := string(head[8:12])
+		if brand == "qt  " {
+			return Video{ContentType: "video/quicktime", Extension: ".mov"}, true
+		}
+
+		if mp4Brands[brand] {
+			return Video{ContentType: "video/mp4", Extension: ".mp4"}, true
+		}
+	}
+
+	//WebM is a Matroska file whose EBML header names the webm doc type
+	if bytes.HasPrefix(head, []byte{0x1A, 0x45, 0xDF, 0xA3}) && bytes.Contains(head, []byte("webm")) {
+		return Video{ContentType: "video/webm", Extension: ".webm"}, true
+	}
+
+	return Video{}, false
+}
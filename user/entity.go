@@ -9,6 +9,13 @@ type User struct {
 	Email          string
 	PasswordHash   string
 	AvatarFileName string
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
+	// AvatarVariantStatus tracks the resized copies of the current avatar, see imaging.StatusReady
+	AvatarVariantStatus string
+	Bio                 string `gorm:"size:1000;not null;default:''"`
+	Location            string `gorm:"size:100;not null;default:''"`
+	// Links holds one URL per line
+	Links               string `gorm:"size:1300;not null;default:''"`
+	ShowBackedCampaigns bool   `gorm:"not null;default:false"`
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
 }
@@ -1,21 +1,49 @@
 package user
 
+import (
+	"rocketship/imaging"
+	"rocketship/storage"
+	"strings"
+)
+
 type UserFormatter struct {
-	ID     int    `json:"id"`
-	Name   string `json:"name"`
-	Email  string `json:"email"`
-	Avatar string `json:"avatar"`
-	Token  string `json:"token"`
+	ID                  int               `json:"id"`
+	Name                string            `json:"name"`
+	Email               string            `json:"email"`
+	Avatar              string            `json:"avatar"`
+	AvatarVariants      map[string]string `json:"avatar_variants"`
+	Bio                 string            `json:"bio"`
+	Location            string            `json:"location"`
+	Links               []string          `json:"links"`
+	ShowBackedCampaigns bool              `json:"show_backed_campaigns"`
+	Token               string            `json:"token"`
 }
 
-func FormatUser(user User, token string) UserFormatter {
+func FormatUser(user User, token string, storageService storage.Service) UserFormatter {
 	formatter := UserFormatter{
-		ID:     user.ID,
-		Name:   user.Name,
-		Email:  user.Email,
-		Avatar: user.AvatarFileName,
-		Token:  token,
+		ID:                  user.ID,
+		Name:                user.Name,
+		Email:               user.Email,
+		Avatar:              storage.URL(storageService, user.AvatarFileName),
+		AvatarVariants:      imaging.VariantURLs(user.AvatarFileName, user.AvatarVariantStatus, storageService),
+		Bio:                 user.Bio,
+		Location:            user.Location,
+		Links:               SplitLinks(user.Links),
+		ShowBackedCampaigns: user.ShowBackedCampaigns,
+		Token:               token,
 	}
 
 	return formatter
 }
+
+func SplitLinks(links string) []string {
+	linkList := []string{}
+
+	for _, link := range strings.Split(links, "\n") {
+		if strings.TrimSpace(link) != "" {
+			linkList = append(linkList, strings.TrimSpace(link))
+		}
+	}
+
+	return linkList
+}
@@ -11,6 +11,13 @@ type LoginInput struct {
 	Password string `json:"password" binding:"required"`
 }
 
+type UpdateProfileInput struct {
+	Bio                 string   `json:"bio" binding:"max=1000"`
+	Location            string   `json:"location" binding:"max=100"`
+	Links               []string `json:"links" binding:"max=5,dive,url,max=255"`
+	ShowBackedCampaigns bool     `json:"show_backed_campaigns"`
+}
+
 type EmailValidatorInput struct {
 	Email string `json:"email" binding:"required,email"`
 }
@@ -6,7 +6,9 @@ type Repository interface {
 	CreateUser(user User) (User, error)
 	FindUserByEmail(email string) (User, error)
 	FindUserByID(id int) (User, error)
+	FindUserByIDs(ids []int) ([]User, error)
 	UpdateUser(user User) (User, error)
+	FindUserByAvatarVariantStatus(status string) ([]User, error)
 }
 
 type repository struct {
@@ -48,6 +50,28 @@ func (repo *repository) FindUserByID(id int) (User, error) {
 	return user, nil
 }
 
+func (repo *repository) FindUserByIDs(ids []int) ([]User, error) {
+	var userList []User
+	err := repo.db.Where("id IN ?", ids).Find(&userList).Error
+
+	if err != nil {
+		return userList, err
+	}
+
+	return userList, nil
+}
+
+func (repo *repository) FindUserByAvatarVariantStatus(status string) ([]User, error) {
+	var userList []User
+	err := repo.db.Where("avatar_file_name <> '' AND avatar_variant_status = ?", status).Find(&userList).Error
+
+	if err != nil {
+		return userList, err
+	}
+
+	return userList, nil
+}
+
 func (repo *repository) UpdateUser(user User) (User, error) {
 	err := repo.db.Save(&user).Error
 	if err != nil {
@@ -2,6 +2,9 @@ package user
 
 import (
 	"errors"
+	"net/url"
+	"rocketship/imaging"
+	"strings"
 
 	"golang.org/x/crypto/bcrypt"
 )
@@ -12,6 +15,9 @@ type Service interface {
 	ValidateEmail(email EmailValidatorInput) (bool, error)
 	UploadAvatar(id int, filePath string) (User, error)
 	FindUserByID(id int) (User, error)
+	UpdateProfile(id int, input UpdateProfileInput) (User, error)
+	FindPendingVariants() ([]imaging.Job, error)
+	SaveVariantStatus(job imaging.Job, status string) error
 }
 
 type service struct {
@@ -84,6 +90,7 @@ func (s *service) UploadAvatar(id int, filePath string) (User, error) {
 	}
 
 	user.AvatarFileName = filePath
+	user.AvatarVariantStatus = imaging.StatusPending
 
 	updatedUser, err := s.repository.UpdateUser(user)
 	if err != nil {
@@ -93,6 +100,68 @@ func (s *service) UploadAvatar(id int, filePath string) (User, error) {
 	return updatedUser, nil
 }
 
+func (s *service) UpdateProfile(id int, input UpdateProfileInput) (User, error) {
+	user, err := s.repository.FindUserByID(id)
+	if err != nil {
+		return user, err
+	}
+
+	//Links end up as anchors on the public profile
+	for _, link := range input.Links {
+		linkURL, err := url.Parse(link)
+		if err != nil || (linkURL.Scheme != "http" && linkURL.Scheme != "https") {
+			return user, errors.New("Profile links must be http or https URLs")
+		}
+	}
+
+	user.Bio = strings.TrimSpace(input.Bio)
+	user.Location = strings.TrimSpace(input.Location)
+	user.Links = strings.Join(input.Links, "\n")
+	user.ShowBackedCampaigns = input.ShowBackedCampaigns
+
+	updatedUser, err := s.repository.UpdateUser(user)
+	if err != nil {
+		return user, err
+	}
+
+	return updatedUser, nil
+}
+
+func (s *service) FindPendingVariants() ([]imaging.Job, error) {
+	userList, err := s.repository.FindUserByAvatarVariantStatus(imaging.StatusPending)
+	if err != nil {
+		return []imaging.Job{}, err
+	}
+
+	jobList := []imaging.Job{}
+	for _, user := range userList {
+		jobList = append(jobList, imaging.Job{ID: user.ID, Key: user.AvatarFileName})
+	}
+
+	return jobList, nil
+}
+
+func (s *service) SaveVariantStatus(job imaging.Job, status string) error {
+	user, err := s.repository.FindUserByID(job.ID)
+	if err != nil {
+		return err
+	}
+
+	//The avatar may have been replaced while its variants were generated
+	if user.AvatarFileName != job.Key {
+		return nil
+	}
+
+	user.AvatarVariantStatus = status
+
+	_, err = s.repository.UpdateUser(user)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (s *service) FindUserByID(id int) (User, error) {
 	user, err := s.repository.FindUserByID(id)
 
@@ -0,0 +1,34 @@
+package voucher
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+)
+
+// Same alphabet as transaction codes, without characters that are easily confused
+const codeAlphabet = "23456789ABCDEFGHJKLMNPQRSTUVWXYZ"
+const codeLength = 12
+
+func generateCode() (string, error) {
+	var code strings.Builder
+
+	for i := 0; i < codeLength; i++ {
+		if i > 0 && i%4 == 0 {
+			code.WriteByte('-')
+		}
+
+		index, err := rand.Int(rand.Reader, big.NewInt(int64(len(codeAlphabet))))
+		if err != nil {
+			return "", err
+		}
+
+		code.WriteByte(codeAlphabet[index.Int64()])
+	}
+
+	return code.String(), nil
+}
+
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
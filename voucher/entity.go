@@ -0,0 +1,31 @@
+package voucher
+
+import "time"
+
+// A voucher with MaxRedemptions of 1 is single-use, 0 means it can be redeemed until the balance runs out.
+// Balance is in the voucher currency, and every change to it is written to BalanceEntry.
+type Voucher struct {
+	ID              int
+	Code            string `gorm:"uniqueIndex;size:50"`
+	CampaignID      int
+	Currency        string
+	Balance         int
+	MaxRedemptions  int
+	RedemptionCount int
+	ExpiresAt       time.Time
+	CreatedBy       int
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	BalanceEntries  []BalanceEntry
+}
+
+// Amount is positive when money is added to the voucher and negative when it is redeemed
+type BalanceEntry struct {
+	ID            int
+	VoucherID     int
+	TransactionID int
+	Kind          string
+	Amount        int
+	BalanceAfter  int
+	CreatedAt     time.Time
+}
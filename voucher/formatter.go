@@ -0,0 +1,89 @@
+package voucher
+
+import "time"
+
+type VoucherFormatter struct {
+	ID              int       `json:"id"`
+	Code            string    `json:"code"`
+	CampaignID      int       `json:"campaign_id"`
+	Currency        string    `json:"currency"`
+	Balance         int       `json:"balance"`
+	MaxRedemptions  int       `json:"max_redemptions"`
+	RedemptionCount int       `json:"redemption_count"`
+	ExpiresAt       time.Time `json:"expires_at"`
+}
+
+type VoucherDetailFormatter struct {
+	ID              int                     `json:"id"`
+	Code            string                  `json:"code"`
+	CampaignID      int                     `json:"campaign_id"`
+	Currency        string                  `json:"currency"`
+	Balance         int                     `json:"balance"`
+	MaxRedemptions  int                     `json:"max_redemptions"`
+	RedemptionCount int                     `json:"redemption_count"`
+	ExpiresAt       time.Time               `json:"expires_at"`
+	BalanceEntries  []BalanceEntryFormatter `json:"balance_entries"`
+}
+
+type BalanceEntryFormatter struct {
+	TransactionID int       `json:"transaction_id"`
+	Kind          string    `json:"kind"`
+	Amount        int       `json:"amount"`
+	BalanceAfter  int       `json:"balance_after"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func FormatVoucher(voucher Voucher) VoucherFormatter {
+	formatter := VoucherFormatter{
+		ID:              voucher.ID,
+		Code:            voucher.Code,
+		CampaignID:      voucher.CampaignID,
+		Currency:        voucher.Currency,
+		Balance:         voucher.Balance,
+		MaxRedemptions:  voucher.MaxRedemptions,
+		RedemptionCount: voucher.RedemptionCount,
+		ExpiresAt:       voucher.ExpiresAt,
+	}
+
+	return formatter
+}
+
+func FormatVoucherList(voucherList []Voucher) []VoucherFormatter {
+	formatterList := []VoucherFormatter{}
+
+	for _, voucher := range voucherList {
+		formatter := FormatVoucher(voucher)
+		formatterList = append(formatterList, formatter)
+	}
+
+	return formatterList
+}
+
+func FormatVoucherDetail(voucher Voucher) VoucherDetailFormatter {
+	formatter := VoucherDetailFormatter{
+		ID:              voucher.ID,
+		Code:            voucher.Code,
+		CampaignID:      voucher.CampaignID,
+		Currency:        voucher.Currency,
+		Balance:         voucher.Balance,
+		MaxRedemptions:  voucher.MaxRedemptions,
+		RedemptionCount: voucher.RedemptionCount,
+		ExpiresAt:       voucher.ExpiresAt,
+	}
+
+	balanceEntries := []BalanceEntryFormatter{}
+	for _, entry := range voucher.BalanceEntries {
+		entryFormatter := BalanceEntryFormatter{
+			TransactionID: entry.TransactionID,
+			Kind:          entry.Kind,
+			Amount:        entry.Amount,
+			BalanceAfter:  entry.BalanceAfter,
+			CreatedAt:     entry.CreatedAt,
+		}
+
+		balanceEntries = append(balanceEntries, entryFormatter)
+	}
+	formatter.BalanceEntries = balanceEntries
+
+	return formatter
+}
@@ -0,0 +1,21 @@
+package voucher
+
+import (
+	"rocketship/user"
+	"time"
+)
+
+type VoucherDetailInput struct {
+	ID   int `uri:"id" binding:"required"`
+	User user.User
+}
+
+type CreateVoucherInput struct {
+	Code           string    `json:"code" binding:"max=50"`
+	CampaignID     int       `json:"campaign_id" binding:"gte=0"`
+	Currency       string    `json:"currency"`
+	Balance        int       `json:"balance" binding:"required,gt=0"`
+	MaxRedemptions int       `json:"max_redemptions" binding:"gte=0"`
+	ExpiresAt      time.Time `json:"expires_at" binding:"required"`
+	User           user.User
+}
@@ -0,0 +1,132 @@
+package voucher
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository interface {
+	FindAllVoucher() ([]Voucher, error)
+	FindVoucherByID(ID int) (Voucher, error)
+	FindVoucherByCode(code string) (Voucher, error)
+	CreateVoucher(voucher Voucher) (Voucher, error)
+	FindEntryByTransactionID(transactionID int) ([]BalanceEntry, error)
+	UpdateBalance(voucherID int, change func(voucher Voucher) (Voucher, BalanceEntry, error)) (BalanceEntry, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{db}
+}
+
+func (repo *repository) FindAllVoucher() ([]Voucher, error) {
+	var voucherList []Voucher
+
+	err := repo.db.Order("created_at desc").Find(&voucherList).Error
+	if err != nil {
+		return voucherList, err
+	}
+
+	return voucherList, nil
+}
+
+func (repo *repository) FindVoucherByID(ID int) (Voucher, error) {
+	var voucher Voucher
+
+	err := repo.db.Preload("BalanceEntries", func(db *gorm.DB) *gorm.DB {
+		return db.Order("balance_entries.id asc")
+	}).Where("id = ?", ID).Find(&voucher).Error
+	if err != nil {
+		return voucher, err
+	}
+
+	return voucher, nil
+}
+
+func (repo *repository) FindVoucherByCode(code string) (Voucher, error) {
+	var voucher Voucher
+
+	err := repo.db.Where("code = ?", code).Find(&voucher).Error
+	if err != nil {
+		return voucher, err
+	}
+
+	return voucher, nil
+}
+
+// CreateVoucher writes the opening balance as the first entry of the voucher
+func (repo *repository) CreateVoucher(voucher Voucher) (Voucher, error) {
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&voucher).Error
+		if err != nil {
+			return err
+		}
+
+		entry := BalanceEntry{
+			VoucherID:    voucher.ID,
+			Kind:         "issue",
+			Amount:       voucher.Balance,
+			BalanceAfter: voucher.Balance,
+		}
+
+		return tx.Create(&entry).Error
+	})
+
+	if err != nil {
+		return voucher, err
+	}
+
+	return voucher, nil
+}
+
+func (repo *repository) FindEntryByTransactionID(transactionID int) ([]BalanceEntry, error) {
+	var entryList []BalanceEntry
+
+	err := repo.db.Where("transaction_id = ?", transactionID).Order("id asc").Find(&entryList).Error
+	if err != nil {
+		return entryList, err
+	}
+
+	return entryList, nil
+}
+
+// UpdateBalance locks the voucher while change decides the new balance, so two pledges can never spend the same money
+func (repo *repository) UpdateBalance(voucherID int, change func(voucher Voucher) (Voucher, BalanceEntry, error)) (BalanceEntry, error) {
+	var entry BalanceEntry
+
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		var voucher Voucher
+
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&voucher, voucherID).Error
+		if err != nil {
+			return err
+		}
+
+		voucher, entry, err = change(voucher)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&voucher).Updates(map[string]interface{}{
+			"balance":          voucher.Balance,
+			"redemption_count": voucher.RedemptionCount,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		entry.VoucherID = voucher.ID
+		entry.BalanceAfter = voucher.Balance
+
+		return tx.Create(&entry).Error
+	})
+
+	if err != nil {
+		return entry, err
+	}
+
+	return entry, nil
+}
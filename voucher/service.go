@@ -0,0 +1,239 @@
+package voucher
+
+import (
+	"errors"
+	"fmt"
+	"rocketship/campaign"
+	"rocketship/helper"
+	"rocketship/money"
+	"rocketship/user"
+	"time"
+)
+
+type Service interface {
+	FindVouchers(currentUser user.User) ([]Voucher, error)
+	FindVoucher(input VoucherDetailInput) (Voucher, error)
+	CreateVoucher(input CreateVoucherInput) (Voucher, error)
+	QuoteVoucher(code string, campaignID int, currency string, amount int) (Voucher, int, error)
+	RedeemVoucher(voucherID int, transactionID int, campaignID int, currency string, amount int) error
+	ReleaseVoucher(transactionID int) error
+}
+
+type service struct {
+	repository Repository
+	campaign   campaign.Repository
+}
+
+func NewService(repository Repository, campaignRepository campaign.Repository) *service {
+	return &service{repository, campaignRepository}
+}
+
+func (service *service) FindVouchers(currentUser user.User) ([]Voucher, error) {
+	if currentUser.Role != "admin" {
+		return []Voucher{}, errors.New("could not find vouchers due to lack of credentials")
+	}
+
+	voucherList, err := service.repository.FindAllVoucher()
+	if err != nil {
+		return voucherList, err
+	}
+
+	return voucherList, nil
+}
+
+func (service *service) FindVoucher(input VoucherDetailInput) (Voucher, error) {
+	if input.User.Role != "admin" {
+		return Voucher{}, errors.New("could not find voucher due to lack of credentials")
+	}
+
+	voucher, err := service.repository.FindVoucherByID(input.ID)
+	if err != nil {
+		return voucher, err
+	}
+
+	if voucher.ID == 0 {
+		return voucher, errors.New("no voucher found with this ID")
+	}
+
+	return voucher, nil
+}
+
+func (service *service) CreateVoucher(input CreateVoucherInput) (Voucher, error) {
+	if input.User.Role != "admin" {
+		return Voucher{}, errors.New("could not create voucher due to lack of credentials")
+	}
+
+	if !input.ExpiresAt.After(time.Now()) {
+		return Voucher{}, errors.New("voucher must expire in the future")
+	}
+
+	if !money.IsSupported(input.Currency) {
+		return Voucher{}, errors.New("currency is not supported")
+	}
+
+	if input.CampaignID != 0 {
+		campaign, err := service.campaign.FindCampaignByID(input.CampaignID)
+		if err != nil {
+			return Voucher{}, err
+		}
+
+		if campaign.ID == 0 {
+			return Voucher{}, errors.New("no campaign found with this ID")
+		}
+	}
+
+	code := normalizeCode(input.Code)
+	if code == "" {
+		generatedCode, err := generateCode()
+		if err != nil {
+			return Voucher{}, err
+		}
+
+		code = generatedCode
+	}
+
+	existingVoucher, err := service.repository.FindVoucherByCode(code)
+	if err != nil {
+		return Voucher{}, err
+	}
+
+	if existingVoucher.ID != 0 {
+		return Voucher{}, errors.New("voucher code is already in use")
+	}
+
+	voucher := Voucher{
+		Code:           code,
+		CampaignID:     input.CampaignID,
+		Currency:       money.Normalize(input.Currency),
+		Balance:        input.Balance,
+		MaxRedemptions: input.MaxRedemptions,
+		ExpiresAt:      input.ExpiresAt,
+		CreatedBy:      input.User.ID,
+	}
+
+	//The unique index catches a voucher created with the same code since the lookup above
+	newVoucher, err := service.repository.CreateVoucher(voucher)
+	if helper.IsDuplicateKey(err) {
+		return newVoucher, errors.New("voucher code is already in use")
+	}
+
+	if err != nil {
+		return newVoucher, err
+	}
+
+	return newVoucher, nil
+}
+
+// QuoteVoucher tells how much of a pledge the voucher covers without spending anything yet
+func (service *service) QuoteVoucher(code string, campaignID int, currency string, amount int) (Voucher, int, error) {
+	voucher, err := service.repository.FindVoucherByCode(normalizeCode(code))
+	if err != nil {
+		return voucher, 0, err
+	}
+
+	err = checkVoucher(voucher, campaignID, currency)
+	if err != nil {
+		return voucher, 0, err
+	}
+
+	covered := amount
+	if voucher.Balance < covered {
+		covered = voucher.Balance
+	}
+
+	return voucher, covered, nil
+}
+
+func (service *service) RedeemVoucher(voucherID int, transactionID int, campaignID int, currency string, amount int) error {
+	_, err := service.repository.UpdateBalance(voucherID, func(voucher Voucher) (Voucher, BalanceEntry, error) {
+		err := checkVoucher(voucher, campaignID, currency)
+		if err != nil {
+			return voucher, BalanceEntry{}, err
+		}
+
+		if voucher.Balance < amount {
+			return voucher, BalanceEntry{}, errors.New("voucher balance has changed, please try again")
+		}
+
+		voucher.Balance = voucher.Balance - amount
+		voucher.RedemptionCount = voucher.RedemptionCount + 1
+
+		entry := BalanceEntry{
+			TransactionID: transactionID,
+			Kind:          "redeem",
+			Amount:        -amount,
+		}
+
+		return voucher, entry, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ReleaseVoucher gives back whatever an unpaid pledge still holds, calling it twice releases nothing more
+func (service *service) ReleaseVoucher(transactionID int) error {
+	entryList, err := service.repository.FindEntryByTransactionID(transactionID)
+	if err != nil {
+		return err
+	}
+
+	heldByVoucher := map[int]int{}
+	for _, entry := range entryList {
+		heldByVoucher[entry.VoucherID] = heldByVoucher[entry.VoucherID] - entry.Amount
+	}
+
+	for voucherID, held := range heldByVoucher {
+		if held <= 0 {
+			continue
+		}
+
+		_, err := service.repository.UpdateBalance(voucherID, func(voucher Voucher) (Voucher, BalanceEntry, error) {
+			voucher.Balance = voucher.Balance + held
+			voucher.RedemptionCount = voucher.RedemptionCount - 1
+
+			entry := BalanceEntry{
+				TransactionID: transactionID,
+				Kind:          "release",
+				Amount:        held,
+			}
+
+			return voucher, entry, nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func checkVoucher(voucher Voucher, campaignID int, currency string) error {
+	if voucher.ID == 0 {
+		return errors.New("voucher code is not valid")
+	}
+
+	if !time.Now().Before(voucher.ExpiresAt) {
+		return errors.New("voucher has expired")
+	}
+
+	if voucher.CampaignID != 0 && voucher.CampaignID != campaignID {
+		return errors.New("voucher could not be used for this campaign")
+	}
+
+	if money.Normalize(voucher.Currency) != money.Normalize(currency) {
+		return fmt.Errorf("voucher could only be used for donations in %s", money.Normalize(voucher.Currency))
+	}
+
+	if voucher.MaxRedemptions != 0 && voucher.RedemptionCount >= voucher.MaxRedemptions {
+		return errors.New("voucher has already been used")
+	}
+
+	if voucher.Balance <= 0 {
+		return errors.New("voucher has no balance left")
+	}
+
+	return nil
+}
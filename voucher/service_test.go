@@ -0,0 +1,101 @@
+package voucher
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeRepository holds a single voucher, changes are applied one at a time as under the row lock
+type fakeRepository struct {
+	Repository
+	voucher Voucher
+	entries []BalanceEntry
+}
+
+func (repo *fakeRepository) FindVoucherByCode(code string) (Voucher, error) {
+	if code != repo.voucher.Code {
+		return Voucher{}, nil
+	}
+
+	return repo.voucher, nil
+}
+
+func (repo *fakeRepository) FindEntryByTransactionID(transactionID int) ([]BalanceEntry, error) {
+	entryList := []BalanceEntry{}
+	for _, entry := range repo.entries {
+		if entry.TransactionID == transactionID {
+			entryList = append(entryList, entry)
+		}
+	}
+
+	return entryList, nil
+}
+
+func (repo *fakeRepository) UpdateBalance(voucherID int, change func(voucher Voucher) (Voucher, BalanceEntry, error)) (BalanceEntry, error) {
+	voucher, entry, err := change(repo.voucher)
+	if err != nil {
+		return BalanceEntry{}, err
+	}
+
+	entry.VoucherID = voucher.ID
+	entry.BalanceAfter = voucher.Balance
+
+	repo.voucher = voucher
+	repo.entries = append(repo.entries, entry)
+
+	return entry, nil
+}
+
+func TestRedeemVoucherNeverSpendsMoreThanTheBalance(t *testing.T) {
+	repository := &fakeRepository{voucher: Voucher{ID: 1, Code: "GIFT-2024", Currency: "IDR", Balance: 100000, ExpiresAt: time.Now().Add(time.Hour)}}
+	service := NewService(repository, nil)
+
+	//Both pledges were quoted the full balance before either of them was saved
+	for transactionID := 1; transactionID <= 2; transactionID++ {
+		_, covered, err := service.QuoteVoucher("gift-2024", 7, "IDR", 150000)
+		if err != nil || covered != 100000 {
+			t.Fatalf("QuoteVoucher() = %d, %v, expected the full balance", covered, err)
+		}
+	}
+
+	err := service.RedeemVoucher(1, 1, 7, "IDR", 100000)
+	if err != nil {
+		t.Fatalf("RedeemVoucher() returned %v", err)
+	}
+
+	err = service.RedeemVoucher(1, 2, 7, "IDR", 100000)
+	if err == nil {
+		t.Errorf("RedeemVoucher() spent the balance twice")
+	}
+
+	if repository.voucher.Balance != 0 || repository.voucher.RedemptionCount != 1 {
+		t.Errorf("voucher = %+v, expected it spent once", repository.voucher)
+	}
+
+	//An unpaid pledge gives the balance back once, however often it is released
+	for i := 0; i < 2; i++ {
+		err = service.ReleaseVoucher(1)
+		if err != nil {
+			t.Fatalf("ReleaseVoucher() returned %v", err)
+		}
+	}
+
+	if repository.voucher.Balance != 100000 || repository.voucher.RedemptionCount != 0 {
+		t.Errorf("voucher = %+v, expected the balance back once", repository.voucher)
+	}
+}
+
+func TestRedeemVoucherChecksTheRedemptionLimit(t *testing.T) {
+	repository := &fakeRepository{voucher: Voucher{ID: 1, Code: "ONCE", Currency: "IDR", Balance: 100000, MaxRedemptions: 1, ExpiresAt: time.Now().Add(time.Hour)}}
+	service := NewService(repository, nil)
+
+	err := service.RedeemVoucher(1, 1, 7, "IDR", 20000)
+	if err != nil {
+		t.Fatalf("RedeemVoucher() returned %v", err)
+	}
+
+	err = service.RedeemVoucher(1, 2, 7, "IDR", 20000)
+	if err == nil || err.Error() != "voucher has already been used" {
+		t.Errorf("RedeemVoucher() returned %v, expected the single-use voucher to be rejected", err)
+	}
+}